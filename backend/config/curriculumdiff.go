@@ -0,0 +1,139 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Nichakorn25/CPE-Teaching-Schedule/entity"
+)
+
+const (
+	MatchByMapping = "mapping"
+	MatchByCode    = "code"
+)
+
+type (
+	CourseRef struct {
+		ID          uint
+		Code        string
+		ThaiName    string
+		EnglishName string
+	}
+
+	CourseFieldChange struct {
+		Field string
+		Old   string
+		New   string
+	}
+
+	CourseChange struct {
+		Old       CourseRef
+		New       CourseRef
+		MatchedBy string
+		Changes   []CourseFieldChange
+	}
+
+	CurriculumDiff struct {
+		Added     []CourseRef
+		Removed   []CourseRef
+		Changed   []CourseChange
+		Unchanged []CourseChange
+	}
+)
+
+// NormalizeCourseCode รหัสวิชาสำหรับจับคู่ข้ามหลักสูตร: ตัวพิมพ์ใหญ่ ไม่มีช่องว่าง ขีด หรือ "*"
+func NormalizeCourseCode(code string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '\t', '-', '*':
+			return -1
+		}
+		return r
+	}, strings.ToUpper(code))
+}
+
+func courseRef(ac entity.AllCourses) CourseRef {
+	return CourseRef{ID: ac.ID, Code: ac.Code, ThaiName: ac.ThaiName, EnglishName: ac.EnglishName}
+}
+
+func creditLabel(c entity.Credit) string {
+	return fmt.Sprintf("%d (%d-%d-%d)", c.Unit, c.Lecture, c.Lab, c.Self)
+}
+
+// courseChanges ความต่างของรายวิชาที่จับคู่กันได้ ต้อง preload Credit, TypeOfCourses และ AcademicYear
+func courseChanges(o, n entity.AllCourses) []CourseFieldChange {
+	changes := []CourseFieldChange{}
+	add := func(field, ov, nv string) {
+		if ov != nv {
+			changes = append(changes, CourseFieldChange{Field: field, Old: ov, New: nv})
+		}
+	}
+	add("Code", o.Code, n.Code)
+	add("ThaiName", o.ThaiName, n.ThaiName)
+	add("EnglishName", o.EnglishName, n.EnglishName)
+	add("Credit", creditLabel(o.Credit), creditLabel(n.Credit))
+	add("TypeOfCourses", o.TypeOfCourses.TypeName, n.TypeOfCourses.TypeName)
+
+	year := func(ac entity.AllCourses) string {
+		if ac.AcademicYearID == nil {
+			return ""
+		}
+		return ac.AcademicYear.Level
+	}
+	add("AcademicYear", year(o), year(n))
+	return changes
+}
+
+// CompareCurricula เทียบรายวิชาของหลักสูตรเก่ากับหลักสูตรใหม่
+// จับคู่จากตาราง mapping (วิชาเก่า -> วิชาใหม่) ก่อน แล้วจึงจับคู่วิชาที่เหลือด้วยรหัสวิชาหลัง normalize
+func CompareCurricula(oldCourses, newCourses []entity.AllCourses, mapping map[uint]uint) CurriculumDiff {
+	diff := CurriculumDiff{Added: []CourseRef{}, Removed: []CourseRef{}, Changed: []CourseChange{}, Unchanged: []CourseChange{}}
+
+	newByID := make(map[uint]entity.AllCourses, len(newCourses))
+	newByCode := make(map[string]entity.AllCourses, len(newCourses))
+	for _, ac := range newCourses {
+		newByID[ac.ID] = ac
+		newByCode[NormalizeCourseCode(ac.Code)] = ac
+	}
+
+	matched := make(map[uint]bool, len(newCourses))
+	record := func(o, n entity.AllCourses, by string) {
+		matched[n.ID] = true
+		ch := CourseChange{Old: courseRef(o), New: courseRef(n), MatchedBy: by, Changes: courseChanges(o, n)}
+		if len(ch.Changes) == 0 {
+			diff.Unchanged = append(diff.Unchanged, ch)
+		} else {
+			diff.Changed = append(diff.Changed, ch)
+		}
+	}
+
+	var pending []entity.AllCourses
+	for _, o := range oldCourses {
+		if id, ok := mapping[o.ID]; ok {
+			if n, ok := newByID[id]; ok && !matched[id] {
+				record(o, n, MatchByMapping)
+				continue
+			}
+		}
+		pending = append(pending, o)
+	}
+	for _, o := range pending {
+		if n, ok := newByCode[NormalizeCourseCode(o.Code)]; ok && !matched[n.ID] {
+			record(o, n, MatchByCode)
+			continue
+		}
+		diff.Removed = append(diff.Removed, courseRef(o))
+	}
+	for _, n := range newCourses {
+		if !matched[n.ID] {
+			diff.Added = append(diff.Added, courseRef(n))
+		}
+	}
+
+	sort.Slice(diff.Added, func(i, j int) bool { return diff.Added[i].Code < diff.Added[j].Code })
+	sort.Slice(diff.Removed, func(i, j int) bool { return diff.Removed[i].Code < diff.Removed[j].Code })
+	sort.Slice(diff.Changed, func(i, j int) bool { return diff.Changed[i].Old.Code < diff.Changed[j].Old.Code })
+	sort.Slice(diff.Unchanged, func(i, j int) bool { return diff.Unchanged[i].Old.Code < diff.Unchanged[j].Old.Code })
+	return diff
+}
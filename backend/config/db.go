@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/Nichakorn25/CPE-Teaching-Schedule/entity"
@@ -110,23 +111,46 @@ func ConnectionDB() {
 }
 
 func SetupDatabase() {
+	migrateLaboratoryCapacity()
+	migrateCourseCodeUnique()
+	migrateSectionTAUnique()
+	dropFullUniqueIndex("rooms", "idx_room_name_building")
+	dropFullUniqueIndex("all_courses", "idx_course_code_curriculum")
+
 	err := db.AutoMigrate(
 		&entity.Title{},
 		&entity.Position{},
 		&entity.Role{},
 		&entity.Department{},
+		&entity.TimeGrid{},
+		&entity.TimeGridBlock{},
 		&entity.Major{},
 		&entity.Laboratory{},
+		&entity.Room{},
 		&entity.Credit{},
 		&entity.TypeOfCourses{},
 		&entity.AcademicYear{},
 		&entity.Curriculum{},
 		&entity.TeachingAssistant{},
+		&entity.TAAvailability{},
 		&entity.User{},
 		&entity.Condition{},
+		&entity.ConditionPreference{},
+		&entity.ConditionLimit{},
 		&entity.AllCourses{},
+		&entity.CreditHourRule{},
+		&entity.CoursePrerequisite{},
+		&entity.CourseMapping{},
+		&entity.CourseEquivalence{},
+		&entity.StudyPlanEntry{},
 		&entity.UserAllCourses{},
 		&entity.OfferedCourses{},
+		&entity.OfferedCourseAlias{},
+		&entity.TermCalendar{},
+		&entity.Section{},
+		&entity.StudentGroup{},
+		&entity.SectionStudentGroup{},
+		&entity.SectionInstructor{},
 		&entity.TimeFixedCourses{},
 		&entity.Schedule{},
 		&entity.ScheduleTeachingAssistant{},
@@ -134,6 +158,7 @@ func SetupDatabase() {
 	if err != nil {
 		log.Fatalf("AutoMigrate failed: %v", err)
 	}
+	migrateSearchIndexes()
 	SeedTitles()
 	SeedPositions()
 	SeedRoles()
@@ -154,6 +179,89 @@ func SetupDatabase() {
 	SeedSchedules()
 	SeedTimeFixedCourses()
 	// SeedScheduleTeachingAssistants()
+	SeedRooms()
+	SyncRooms()
+	CoalesceSchedules()
+	SyncSections()
+}
+
+// migrateLaboratoryCapacity แปลงคอลัมน์ laboratories.capacity ที่เคยเก็บเป็นข้อความให้เป็นตัวเลข
+// (AutoMigrate เปลี่ยนชนิดคอลัมน์เองไม่ได้เพราะ PostgreSQL ต้องการ USING)
+func migrateLaboratoryCapacity() {
+	if !db.Migrator().HasTable(&entity.Laboratory{}) {
+		return
+	}
+
+	var dataType string
+	db.Raw(`SELECT data_type FROM information_schema.columns
+		WHERE table_name = 'laboratories' AND column_name = 'capacity'`).Scan(&dataType)
+	if dataType != "text" && dataType != "character varying" {
+		return
+	}
+
+	if err := db.Exec(`ALTER TABLE laboratories ALTER COLUMN capacity TYPE bigint
+		USING COALESCE(NULLIF(regexp_replace(capacity, '[^0-9]', '', 'g'), ''), '0')::bigint`).Error; err != nil {
+		log.Fatalf("migrate laboratories.capacity failed: %v", err)
+	}
+}
+
+// migrateCourseCodeUnique เปลี่ยนรหัสวิชาจาก unique ทั้งตารางเป็น unique ต่อหลักสูตร (idx_course_code_curriculum)
+// และคืนรหัสที่เคยต่อท้ายด้วย "*" จากการคัดลอกหลักสูตรแบบเดิมให้เป็นรหัสจริง
+func migrateCourseCodeUnique() {
+	if !db.Migrator().HasTable(&entity.AllCourses{}) {
+		return
+	}
+
+	for _, name := range []string{"uni_all_courses_code", "all_courses_code_key"} {
+		if err := db.Exec(fmt.Sprintf(`ALTER TABLE all_courses DROP CONSTRAINT IF EXISTS %s`, name)).Error; err != nil {
+			log.Fatalf("drop %s failed: %v", name, err)
+		}
+	}
+
+	// คืนรหัสเฉพาะเมื่อยังมีรหัสที่ต่อท้ายด้วย "*" และไม่ชนกับรายวิชาอื่นในหลักสูตรเดียวกัน
+	const starred = `FROM all_courses a WHERE a.code LIKE '%*%' AND a.deleted_at IS NULL AND NOT EXISTS (
+			SELECT 1 FROM all_courses b
+			WHERE b.curriculum_id = a.curriculum_id AND b.id <> a.id AND b.deleted_at IS NULL
+				AND replace(b.code, '*', '') = replace(a.code, '*', ''))`
+	var pending int64
+	if err := db.Raw(`SELECT COUNT(*) ` + starred).Scan(&pending).Error; err != nil {
+		log.Fatalf("check all_courses.code failed: %v", err)
+	}
+	if pending == 0 {
+		return
+	}
+	if err := db.Exec(`UPDATE all_courses SET code = replace(code, '*', '')
+		WHERE id IN (SELECT a.id ` + starred + `)`).Error; err != nil {
+		log.Fatalf("normalize all_courses.code failed: %v", err)
+	}
+}
+
+// migrateSectionTAUnique ลบผู้ช่วยสอนที่ผูกกับกลุ่มเรียนเดียวกันซ้ำ (เหลือแถวแรก) ก่อนสร้าง idx_section_ta
+func migrateSectionTAUnique() {
+	if !db.Migrator().HasColumn(&entity.ScheduleTeachingAssistant{}, "section_id") {
+		return
+	}
+	if err := db.Exec(`DELETE FROM schedule_teaching_assistants a
+		USING schedule_teaching_assistants b
+		WHERE a.section_id = b.section_id
+		AND a.teaching_assistant_id = b.teaching_assistant_id
+		AND a.deleted_at IS NULL AND b.deleted_at IS NULL
+		AND a.id > b.id`).Error; err != nil {
+		log.Fatalf("dedupe schedule_teaching_assistants failed: %v", err)
+	}
+}
+
+// dropFullUniqueIndex ลบดัชนี unique เดิมที่ยังนับแถวที่ถูก soft delete ด้วย
+// แล้วให้ AutoMigrate สร้างใหม่ตามแท็กเป็นดัชนีบางส่วน (WHERE deleted_at IS NULL)
+func dropFullUniqueIndex(table, index string) {
+	var def string
+	db.Raw(`SELECT indexdef FROM pg_indexes WHERE tablename = ? AND indexname = ?`, table, index).Scan(&def)
+	if def == "" || strings.Contains(def, " WHERE ") {
+		return
+	}
+	if err := db.Exec(fmt.Sprintf(`DROP INDEX IF EXISTS %s`, index)).Error; err != nil {
+		log.Fatalf("drop index %s failed: %v", index, err)
+	}
 }
 
 // //////////////////////////////////////////////////// ผู้ใช้งาน ///////////////////////////////////////////////
@@ -550,9 +658,9 @@ func SeedConditions() {
 // ครบ
 func SeedLaboratory() {
 	laboratories := []entity.Laboratory{
-		{Room: "F11419", Building: "อาคารเครื่องมือ 11 (F11)", Capacity: "40"},
-		{Room: "F11421", Building: "อาคารเครื่องมือ 11 (F11)", Capacity: "30"},
-		{Room: "F11422", Building: "อาคารเครื่องมือ 11 (F11)", Capacity: "50"},
+		{Room: "F11419", Building: "อาคารเครื่องมือ 11 (F11)", Capacity: 40},
+		{Room: "F11421", Building: "อาคารเครื่องมือ 11 (F11)", Capacity: 30},
+		{Room: "F11422", Building: "อาคารเครื่องมือ 11 (F11)", Capacity: 50},
 	}
 
 	for _, lab := range laboratories {
@@ -564,6 +672,27 @@ func SeedLaboratory() {
 	}
 }
 
+// ห้องเรียนบรรยาย/ห้องคอมพิวเตอร์ (ห้องปฏิบัติการสร้างจาก Laboratory ใน SyncRooms)
+func SeedRooms() {
+	rooms := []entity.Room{
+		{Name: "B4101", Building: "อาคารเรียนรวม 4", RoomType: entity.RoomTypeLectureHall, Capacity: 1500, Equipment: "projector,microphone"},
+		{Name: "B3104", Building: "อาคารเรียนรวม 3", RoomType: entity.RoomTypeLectureHall, Capacity: 300, Equipment: "projector,microphone"},
+		{Name: "B5207", Building: "อาคารเรียนรวม 5", RoomType: entity.RoomTypeLectureHall, Capacity: 45, Equipment: "projector"},
+		{Name: "B5204", Building: "อาคารเรียนรวม 5", RoomType: entity.RoomTypeLectureHall, Capacity: 80, Equipment: "projector"},
+		{Name: "DIGITAL TECH LAB 09", Building: "อาคารสิรินธรวิศวพัฒน์", RoomType: entity.RoomTypeComputerRoom, Capacity: 40, Equipment: "computer,projector"},
+	}
+
+	for _, room := range rooms {
+		db.FirstOrCreate(&entity.Room{}, &entity.Room{
+			Name:      room.Name,
+			Building:  room.Building,
+			RoomType:  room.RoomType,
+			Capacity:  room.Capacity,
+			Equipment: room.Equipment,
+		})
+	}
+}
+
 // ยังใส่ไม่ครบ
 func SeedCredits() {
 	credits := []entity.Credit{
@@ -1901,7 +2030,7 @@ func SeedAllCourses() {
 	for _, c := range courses {
 		var course entity.AllCourses
 		db.
-			Where("code = ?", c.Code).
+			Where("code = ? AND curriculum_id = ?", c.Code, c.CurriculumID).
 			FirstOrCreate(&course, entity.AllCourses{
 				Code:            c.Code,
 				EnglishName:     c.EnglishName,
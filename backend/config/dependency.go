@@ -0,0 +1,403 @@
+package config
+
+import (
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/Nichakorn25/CPE-Teaching-Schedule/entity"
+)
+
+// ชนิดของข้อมูลในกราฟความสัมพันธ์สำหรับการลบ/กู้คืน
+const (
+	KindAllCourses          = "all_courses"
+	KindUser                = "user"
+	KindTeachingAssistant   = "teaching_assistant"
+	KindLaboratory          = "laboratory"
+	KindRoom                = "room"
+	KindOfferedCourse       = "offered_course"
+	KindSection             = "section"
+	KindSectionInstructor   = "section_instructor"
+	KindSectionStudentGroup = "section_student_group"
+	KindOfferedCourseAlias  = "offered_course_alias"
+	KindSchedule            = "schedule"
+	KindTimeFixedCourse     = "time_fixed_course"
+	KindScheduleTA          = "schedule_teaching_assistant"
+	KindTAAvailability      = "ta_availability"
+	KindUserAllCourses      = "user_all_courses"
+	KindCondition           = "condition"
+	KindConditionPreference = "condition_preference"
+	KindConditionLimit      = "condition_limit"
+	KindCoursePrerequisite  = "course_prerequisite"
+	KindCourseEquivalence   = "course_equivalence"
+	KindCourseMapping       = "course_mapping"
+	KindCreditHourRule      = "credit_hour_rule"
+	KindStudyPlanEntry      = "study_plan_entry"
+)
+
+// Dependency แถวชนิด Kind อ้างถึงแถวแม่ผ่านคอลัมน์ Column
+type Dependency struct {
+	Kind   string
+	Column string
+	// Owned แถวลูกเป็นส่วนหนึ่งของแถวแม่ ลบตามไปเสมอโดยไม่ถือว่าขัดข้อง
+	Owned bool
+	// Detach คอลัมน์ที่เป็น NULL ได้ เมื่อลบแบบ cascade จะตั้งเป็น NULL แทนการลบแถวลูก
+	Detach bool
+}
+
+var dependencyModels = map[string]func() interface{}{
+	KindAllCourses:          func() interface{} { return &entity.AllCourses{} },
+	KindUser:                func() interface{} { return &entity.User{} },
+	KindTeachingAssistant:   func() interface{} { return &entity.TeachingAssistant{} },
+	KindLaboratory:          func() interface{} { return &entity.Laboratory{} },
+	KindRoom:                func() interface{} { return &entity.Room{} },
+	KindOfferedCourse:       func() interface{} { return &entity.OfferedCourses{} },
+	KindSection:             func() interface{} { return &entity.Section{} },
+	KindSectionInstructor:   func() interface{} { return &entity.SectionInstructor{} },
+	KindSectionStudentGroup: func() interface{} { return &entity.SectionStudentGroup{} },
+	KindOfferedCourseAlias:  func() interface{} { return &entity.OfferedCourseAlias{} },
+	KindSchedule:            func() interface{} { return &entity.Schedule{} },
+	KindTimeFixedCourse:     func() interface{} { return &entity.TimeFixedCourses{} },
+	KindScheduleTA:          func() interface{} { return &entity.ScheduleTeachingAssistant{} },
+	KindTAAvailability:      func() interface{} { return &entity.TAAvailability{} },
+	KindUserAllCourses:      func() interface{} { return &entity.UserAllCourses{} },
+	KindCondition:           func() interface{} { return &entity.Condition{} },
+	KindConditionPreference: func() interface{} { return &entity.ConditionPreference{} },
+	KindConditionLimit:      func() interface{} { return &entity.ConditionLimit{} },
+	KindCoursePrerequisite:  func() interface{} { return &entity.CoursePrerequisite{} },
+	KindCourseEquivalence:   func() interface{} { return &entity.CourseEquivalence{} },
+	KindCourseMapping:       func() interface{} { return &entity.CourseMapping{} },
+	KindCreditHourRule:      func() interface{} { return &entity.CreditHourRule{} },
+	KindStudyPlanEntry:      func() interface{} { return &entity.StudyPlanEntry{} },
+}
+
+var dependencyLabels = map[string]string{
+	KindAllCourses:          "รายวิชา",
+	KindUser:                "ผู้ใช้",
+	KindTeachingAssistant:   "ผู้ช่วยสอน",
+	KindLaboratory:          "ห้องปฏิบัติการ",
+	KindRoom:                "ห้องเรียน",
+	KindOfferedCourse:       "รายวิชาที่เปิดสอน",
+	KindSection:             "กลุ่มเรียน",
+	KindSectionInstructor:   "ผู้สอนประจำกลุ่มเรียน",
+	KindSectionStudentGroup: "กลุ่มนักศึกษาของกลุ่มเรียน",
+	KindOfferedCourseAlias:  "รายวิชาเทียบเท่าที่เปิดสอนร่วม",
+	KindSchedule:            "ตารางสอน",
+	KindTimeFixedCourse:     "วิชาจากศูนย์บริการ",
+	KindScheduleTA:          "การมอบหมายผู้ช่วยสอน",
+	KindTAAvailability:      "เวลาว่างของผู้ช่วยสอน",
+	KindUserAllCourses:      "ผู้สอนของรายวิชา",
+	KindCondition:           "เงื่อนไขเวลาไม่ว่าง",
+	KindConditionPreference: "ความต้องการด้านเวลา",
+	KindConditionLimit:      "ข้อจำกัดภาระสอน",
+	KindCoursePrerequisite:  "วิชาบังคับก่อน/เรียนร่วม",
+	KindCourseEquivalence:   "รายวิชาเทียบเท่า",
+	KindCourseMapping:       "การเทียบรายวิชาระหว่างหลักสูตร",
+	KindCreditHourRule:      "กติกาชั่วโมงสอน",
+	KindStudyPlanEntry:      "แผนการเรียน",
+}
+
+// dependencyGraph แถวลูกของแต่ละชนิด ใช้ทั้งตรวจก่อนลบ ลบแบบ cascade และกู้คืน
+var dependencyGraph = map[string][]Dependency{
+	KindAllCourses: {
+		{Kind: KindUserAllCourses, Column: "all_courses_id", Owned: true},
+		{Kind: KindCoursePrerequisite, Column: "all_courses_id", Owned: true},
+		{Kind: KindCoursePrerequisite, Column: "requires_id", Owned: true},
+		{Kind: KindCourseEquivalence, Column: "all_courses_id", Owned: true},
+		{Kind: KindCourseEquivalence, Column: "equivalent_id", Owned: true},
+		{Kind: KindCourseMapping, Column: "old_course_id", Owned: true},
+		{Kind: KindCourseMapping, Column: "new_course_id", Owned: true},
+		{Kind: KindCreditHourRule, Column: "all_courses_id", Owned: true},
+		{Kind: KindOfferedCourse, Column: "all_courses_id"},
+		{Kind: KindOfferedCourseAlias, Column: "all_courses_id"},
+		{Kind: KindTimeFixedCourse, Column: "all_courses_id"},
+		{Kind: KindStudyPlanEntry, Column: "all_courses_id", Detach: true},
+	},
+	KindUser: {
+		{Kind: KindUserAllCourses, Column: "user_id", Owned: true},
+		{Kind: KindCondition, Column: "user_id", Owned: true},
+		{Kind: KindConditionPreference, Column: "user_id", Owned: true},
+		{Kind: KindConditionLimit, Column: "user_id", Owned: true},
+		{Kind: KindOfferedCourse, Column: "user_id"},
+		{Kind: KindSectionInstructor, Column: "user_id"},
+	},
+	KindTeachingAssistant: {
+		{Kind: KindTAAvailability, Column: "teaching_assistant_id", Owned: true},
+		{Kind: KindScheduleTA, Column: "teaching_assistant_id"},
+	},
+	KindLaboratory: {
+		{Kind: KindRoom, Column: "laboratory_id", Owned: true},
+		{Kind: KindOfferedCourse, Column: "laboratory_id", Detach: true},
+	},
+	KindRoom: {
+		{Kind: KindSchedule, Column: "room_id", Detach: true},
+		{Kind: KindSection, Column: "room_id", Detach: true},
+		{Kind: KindTimeFixedCourse, Column: "room_id", Detach: true},
+	},
+	KindOfferedCourse: {
+		{Kind: KindSection, Column: "offered_courses_id", Owned: true},
+		{Kind: KindSectionInstructor, Column: "offered_courses_id", Owned: true},
+		{Kind: KindOfferedCourseAlias, Column: "offered_courses_id", Owned: true},
+		{Kind: KindSchedule, Column: "offered_courses_id"},
+	},
+	KindSection: {
+		{Kind: KindSectionInstructor, Column: "section_id", Owned: true},
+		{Kind: KindSectionStudentGroup, Column: "section_id", Owned: true},
+		{Kind: KindScheduleTA, Column: "section_id"},
+	},
+	KindSchedule: {
+		{Kind: KindTimeFixedCourse, Column: "schedule_id", Owned: true},
+		{Kind: KindScheduleTA, Column: "schedule_id"},
+	},
+}
+
+// Dependencies แถวลูกที่อ้างถึงข้อมูลชนิด kind
+func Dependencies(kind string) []Dependency {
+	return dependencyGraph[kind]
+}
+
+// DependencyKinds ชนิดทั้งหมดที่รู้จัก
+func DependencyKinds() []string {
+	kinds := make([]string, 0, len(dependencyModels))
+	for kind := range dependencyModels {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
+// DependencyModel model ว่างของข้อมูลชนิด kind (nil ถ้าไม่รู้จัก)
+func DependencyModel(kind string) interface{} {
+	if f, ok := dependencyModels[kind]; ok {
+		return f()
+	}
+	return nil
+}
+
+// DependencyLabel ชื่อภาษาไทยของข้อมูลชนิด kind
+func DependencyLabel(kind string) string {
+	if label, ok := dependencyLabels[kind]; ok {
+		return label
+	}
+	return kind
+}
+
+// Dependent แถวที่อ้างถึงข้อมูลที่จะลบ รวมตามชนิด
+type Dependent struct {
+	Kind  string `json:"kind"`
+	Label string `json:"label"`
+	Count int    `json:"count"`
+	IDs   []uint `json:"ids"`
+}
+
+type detachment struct {
+	Kind   string
+	Column string
+	IDs    []uint
+}
+
+// DeletePlan ผลการไล่ความสัมพันธ์ของข้อมูลที่จะลบ
+type DeletePlan struct {
+	Kind     string
+	ID       uint
+	Blockers []Dependent // แถวที่ทำให้ลบไม่ได้ถ้าไม่ได้ขอ cascade
+	Cascaded []Dependent // แถวที่จะถูกลบตาม (รวมแถวที่เป็นส่วนหนึ่งของข้อมูล)
+	Detached []Dependent // แถวที่จะถูกตัดการอ้างอิง (ตั้งคอลัมน์เป็น NULL)
+
+	remove   map[string][]uint
+	detaches []detachment
+}
+
+// Blocked มีแถวอื่นอ้างถึงข้อมูลนี้และต้องขอ cascade ก่อนลบ
+func (p *DeletePlan) Blocked() bool {
+	return len(p.Blockers) > 0
+}
+
+type dependentSet map[string]map[uint]bool
+
+func (s dependentSet) add(kind string, ids []uint) {
+	if s[kind] == nil {
+		s[kind] = map[uint]bool{}
+	}
+	for _, id := range ids {
+		s[kind][id] = true
+	}
+}
+
+func (s dependentSet) list() []Dependent {
+	out := make([]Dependent, 0, len(s))
+	for kind, set := range s {
+		ids := make([]uint, 0, len(set))
+		for id := range set {
+			ids = append(ids, id)
+		}
+		sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+		out = append(out, Dependent{Kind: kind, Label: DependencyLabel(kind), Count: len(ids), IDs: ids})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Kind < out[j].Kind })
+	return out
+}
+
+// PlanDelete ไล่หาแถวลูกทั้งหมด (ที่ยังไม่ถูกลบ) ของข้อมูลชนิด kind รหัส id
+func PlanDelete(tx *gorm.DB, kind string, id uint) (*DeletePlan, error) {
+	blockers, cascaded, detached := dependentSet{}, dependentSet{}, dependentSet{}
+	seen := dependentSet{kind: {id: true}}
+	plan := &DeletePlan{Kind: kind, ID: id, remove: map[string][]uint{}}
+
+	type frontier struct {
+		kind string
+		ids  []uint
+	}
+	queue := []frontier{{kind, []uint{id}}}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		for _, dep := range dependencyGraph[cur.kind] {
+			var ids []uint
+			if err := tx.Model(DependencyModel(dep.Kind)).Where(dep.Column+" IN ?", cur.ids).Pluck("id", &ids).Error; err != nil {
+				return nil, err
+			}
+			if len(ids) == 0 {
+				continue
+			}
+			if !dep.Owned {
+				blockers.add(dep.Kind, ids)
+			}
+			if dep.Detach {
+				detached.add(dep.Kind, ids)
+				plan.detaches = append(plan.detaches, detachment{Kind: dep.Kind, Column: dep.Column, IDs: ids})
+				continue
+			}
+
+			fresh := []uint{}
+			for _, childID := range ids {
+				if !seen[dep.Kind][childID] {
+					fresh = append(fresh, childID)
+				}
+			}
+			seen.add(dep.Kind, fresh)
+			if len(fresh) == 0 {
+				continue
+			}
+			cascaded.add(dep.Kind, fresh)
+			plan.remove[dep.Kind] = append(plan.remove[dep.Kind], fresh...)
+			queue = append(queue, frontier{dep.Kind, fresh})
+		}
+	}
+
+	plan.Blockers = blockers.list()
+	plan.Cascaded = cascaded.list()
+	plan.Detached = detached.list()
+	return plan, nil
+}
+
+// Execute ลบข้อมูลและแถวลูกแบบ soft delete ด้วยเวลาเดียวกัน เพื่อให้ RestoreDeleted กู้คืนกลับได้ครบชุด
+// แถวที่ถูกตัดการอ้างอิง (Detach) จะไม่ถูกเชื่อมกลับเมื่อกู้คืน
+func (p *DeletePlan) Execute(tx *gorm.DB) error {
+	now := time.Now()
+	for _, d := range p.detaches {
+		if err := tx.Model(DependencyModel(d.Kind)).Where("id IN ?", d.IDs).UpdateColumn(d.Column, nil).Error; err != nil {
+			return err
+		}
+	}
+	for kind, ids := range p.remove {
+		if err := tx.Model(DependencyModel(kind)).Where("id IN ?", ids).UpdateColumn("deleted_at", now).Error; err != nil {
+			return err
+		}
+	}
+	return tx.Model(DependencyModel(p.Kind)).Where("id = ?", p.ID).UpdateColumn("deleted_at", now).Error
+}
+
+// DeletedParent ข้อมูลแม่ที่ยังถูกลบอยู่ ทำให้กู้คืนแถวลูกไม่ได้
+type DeletedParent struct {
+	Kind  string `json:"kind"`
+	Label string `json:"label"`
+	ID    uint   `json:"id"`
+}
+
+// DeletedParents ข้อมูลแม่ (ตามกราฟความสัมพันธ์) ของแถว kind/id ที่ยังถูกลบอยู่
+// ไม่นับคอลัมน์ที่ตัดการอ้างอิงได้ (Detach)
+func DeletedParents(tx *gorm.DB, kind string, id uint) ([]DeletedParent, error) {
+	out := []DeletedParent{}
+	parents := make([]string, 0, len(dependencyGraph))
+	for parent := range dependencyGraph {
+		parents = append(parents, parent)
+	}
+	sort.Strings(parents)
+	for _, parent := range parents {
+		for _, dep := range dependencyGraph[parent] {
+			if dep.Kind != kind || dep.Detach {
+				continue
+			}
+			var refs []*uint
+			if err := tx.Unscoped().Model(DependencyModel(kind)).Where("id = ?", id).Pluck(dep.Column, &refs).Error; err != nil {
+				return nil, err
+			}
+			if len(refs) == 0 || refs[0] == nil {
+				continue
+			}
+			var deleted int64
+			if err := tx.Unscoped().Model(DependencyModel(parent)).Where("id = ? AND deleted_at IS NOT NULL", *refs[0]).Count(&deleted).Error; err != nil {
+				return nil, err
+			}
+			if deleted > 0 {
+				out = append(out, DeletedParent{Kind: parent, Label: DependencyLabel(parent), ID: *refs[0]})
+			}
+		}
+	}
+	return out, nil
+}
+
+// RestoreDeleted กู้คืนข้อมูลที่ถูก soft delete พร้อมแถวลูกที่ถูกลบในคราวเดียวกัน (deleted_at ตรงกัน)
+func RestoreDeleted(tx *gorm.DB, kind string, id uint, deletedAt time.Time) ([]Dependent, error) {
+	restored := dependentSet{}
+	seen := dependentSet{kind: {id: true}}
+
+	type frontier struct {
+		kind string
+		ids  []uint
+	}
+	queue := []frontier{{kind, []uint{id}}}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		for _, dep := range dependencyGraph[cur.kind] {
+			if dep.Detach {
+				continue
+			}
+			var ids []uint
+			if err := tx.Unscoped().Model(DependencyModel(dep.Kind)).
+				Where(dep.Column+" IN ? AND deleted_at = ?", cur.ids, deletedAt).
+				Pluck("id", &ids).Error; err != nil {
+				return nil, err
+			}
+			fresh := []uint{}
+			for _, childID := range ids {
+				if !seen[dep.Kind][childID] {
+					fresh = append(fresh, childID)
+				}
+			}
+			if len(fresh) == 0 {
+				continue
+			}
+			seen.add(dep.Kind, fresh)
+			restored.add(dep.Kind, fresh)
+			queue = append(queue, frontier{dep.Kind, fresh})
+		}
+	}
+
+	for childKind, set := range restored {
+		ids := make([]uint, 0, len(set))
+		for childID := range set {
+			ids = append(ids, childID)
+		}
+		if err := tx.Unscoped().Model(DependencyModel(childKind)).Where("id IN ?", ids).UpdateColumn("deleted_at", nil).Error; err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Unscoped().Model(DependencyModel(kind)).Where("id = ?", id).UpdateColumn("deleted_at", nil).Error; err != nil {
+		return nil, err
+	}
+	return restored.list(), nil
+}
@@ -0,0 +1,68 @@
+package config
+
+import (
+	"sort"
+
+	"github.com/Nichakorn25/CPE-Teaching-Schedule/entity"
+)
+
+// Equivalence กลุ่มรายวิชาเทียบเท่า (เทียบเท่าต่อกันเป็นทอด: A=B และ B=C ถือว่า A=C)
+type Equivalence struct {
+	parent map[uint]uint
+}
+
+func NewEquivalence(rows []entity.CourseEquivalence) *Equivalence {
+	e := &Equivalence{parent: make(map[uint]uint)}
+	for _, r := range rows {
+		e.Add(r.AllCoursesID, r.EquivalentID)
+	}
+	return e
+}
+
+// LoadEquivalence โหลดรายวิชาเทียบเท่าทั้งหมดจากฐานข้อมูล
+func LoadEquivalence() (*Equivalence, error) {
+	var rows []entity.CourseEquivalence
+	if err := db.Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	return NewEquivalence(rows), nil
+}
+
+func (e *Equivalence) root(id uint) uint {
+	for {
+		p, ok := e.parent[id]
+		if !ok || p == id {
+			return id
+		}
+		e.parent[id] = e.parent[p]
+		id = p
+	}
+}
+
+func (e *Equivalence) Add(a, b uint) {
+	for _, id := range []uint{a, b} {
+		if _, ok := e.parent[id]; !ok {
+			e.parent[id] = id
+		}
+	}
+	if ra, rb := e.root(a), e.root(b); ra != rb {
+		e.parent[ra] = rb
+	}
+}
+
+func (e *Equivalence) Equivalent(a, b uint) bool {
+	return a == b || e.root(a) == e.root(b)
+}
+
+// Class รายวิชาที่เทียบเท่ากับวิชานี้ทั้งหมด ไม่รวมตัวเอง
+func (e *Equivalence) Class(id uint) []uint {
+	out := []uint{}
+	r := e.root(id)
+	for other := range e.parent {
+		if other != id && e.root(other) == r {
+			out = append(out, other)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+	return out
+}
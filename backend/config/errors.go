@@ -0,0 +1,30 @@
+package config
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// IsDuplicateKey ข้อผิดพลาดเกิดจากข้อมูลซ้ำกับ unique index (เช่น รหัสวิชาหรือชื่อผู้ใช้ที่ถูกใช้ไปแล้ว)
+func IsDuplicateKey(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return true
+	}
+	if db == nil {
+		return false
+	}
+	translator, ok := db.Dialector.(gorm.ErrorTranslator)
+	if !ok {
+		return false
+	}
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		if errors.Is(translator.Translate(e), gorm.ErrDuplicatedKey) {
+			return true
+		}
+	}
+	return false
+}
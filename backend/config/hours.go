@@ -0,0 +1,117 @@
+package config
+
+import (
+	"github.com/Nichakorn25/CPE-Teaching-Schedule/entity"
+)
+
+// ค่าเริ่มต้นเดิม: บรรยาย 1 ชม./หน่วยกิต ปฏิบัติ 3 ชม./หน่วยกิต บรรยายต่อเนื่องไม่เกิน 3 ชม.
+const (
+	defaultLecturePerCredit = 1
+	defaultLabPerCredit     = 3
+	defaultMaxLectureBlock  = 3
+)
+
+// CourseHours ชั่วโมงสอนต่อสัปดาห์ต่อกลุ่มของรายวิชา ตามกติกาที่ใช้ได้
+type CourseHours struct {
+	Lecture         int
+	Lab             int
+	MaxLectureBlock int // 0 = ไม่จำกัด
+	MaxLabBlock     int // 0 = ปฏิบัติครั้งเดียวจบ
+}
+
+// HoursRules กติกาแปลงหน่วยกิตเป็นชั่วโมงที่โหลดไว้ครั้งเดียว ใช้ร่วมกันทั้งตัวจัดตาราง รายงานภาระงาน และการส่งออก
+type HoursRules struct {
+	byType   map[uint]entity.CreditHourRule
+	byCourse map[uint]entity.CreditHourRule
+}
+
+// LoadHoursRules โหลดกติกาทั้งหมดจากฐานข้อมูล
+func LoadHoursRules() (*HoursRules, error) {
+	var rules []entity.CreditHourRule
+	if err := db.Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	return NewHoursRules(rules), nil
+}
+
+// NewHoursRules จัดกติกาเป็นดัชนีตามรายวิชาและประเภทรายวิชา
+func NewHoursRules(rules []entity.CreditHourRule) *HoursRules {
+	r := &HoursRules{
+		byType:   make(map[uint]entity.CreditHourRule),
+		byCourse: make(map[uint]entity.CreditHourRule),
+	}
+	for _, rule := range rules {
+		if rule.AllCoursesID != nil {
+			r.byCourse[*rule.AllCoursesID] = rule
+		} else if rule.TypeOfCoursesID != nil {
+			r.byType[*rule.TypeOfCoursesID] = rule
+		}
+	}
+	return r
+}
+
+func pick(values ...*uint) (uint, bool) {
+	for _, v := range values {
+		if v != nil {
+			return *v, true
+		}
+	}
+	return 0, false
+}
+
+// For ชั่วโมงของรายวิชา (ต้องมี Credit ของรายวิชา) กติการายวิชามาก่อนกติกาประเภทรายวิชา
+// r เป็น nil ได้ (ใช้ค่าเริ่มต้นอย่างเดียว)
+func (r *HoursRules) For(ac entity.AllCourses) CourseHours {
+	var course, typ entity.CreditHourRule
+	if r != nil {
+		course = r.byCourse[ac.ID]
+		typ = r.byType[ac.TypeOfCoursesID]
+	}
+
+	lecPerCredit, ok := pick(course.LecturePerCredit, typ.LecturePerCredit)
+	if !ok {
+		lecPerCredit = defaultLecturePerCredit
+	}
+	labPerCredit, ok := pick(course.LabPerCredit, typ.LabPerCredit)
+	if !ok {
+		labPerCredit = defaultLabPerCredit
+	}
+
+	h := CourseHours{
+		Lecture:         int(ac.Credit.Lecture * lecPerCredit),
+		Lab:             int(ac.Credit.Lab * labPerCredit),
+		MaxLectureBlock: defaultMaxLectureBlock,
+	}
+	if v, ok := pick(course.LectureHours, typ.LectureHours); ok {
+		h.Lecture = int(v)
+	}
+	if v, ok := pick(course.LabHours, typ.LabHours); ok {
+		h.Lab = int(v)
+	}
+	if v, ok := pick(course.MaxLectureBlock, typ.MaxLectureBlock); ok {
+		h.MaxLectureBlock = int(v)
+	}
+	if v, ok := pick(course.MaxLabBlock, typ.MaxLabBlock); ok {
+		h.MaxLabBlock = int(v)
+	}
+	return h
+}
+
+// LabBlocks แบ่งชั่วโมงปฏิบัติเป็นช่วงต่อเนื่องตาม MaxLabBlock เช่น 6 ชม. สูงสุด 2 ชม. -> [2 2 2]
+func (h CourseHours) LabBlocks() []int {
+	if h.Lab <= 0 {
+		return nil
+	}
+	if h.MaxLabBlock <= 0 || h.MaxLabBlock >= h.Lab {
+		return []int{h.Lab}
+	}
+	blocks := []int{}
+	for rest := h.Lab; rest > 0; rest -= h.MaxLabBlock {
+		if rest < h.MaxLabBlock {
+			blocks = append(blocks, rest)
+		} else {
+			blocks = append(blocks, h.MaxLabBlock)
+		}
+	}
+	return blocks
+}
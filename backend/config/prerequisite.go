@@ -0,0 +1,116 @@
+package config
+
+import (
+	"github.com/Nichakorn25/CPE-Teaching-Schedule/entity"
+)
+
+type requisiteEdge struct {
+	to  uint
+	pre bool
+}
+
+// RequisiteGraph กราฟวิชาบังคับก่อน/เรียนร่วม (ขอบชี้จากวิชาไปยังวิชาที่ต้องเรียนก่อน)
+type RequisiteGraph struct {
+	edges map[uint][]requisiteEdge
+}
+
+func NewRequisiteGraph(rows []entity.CoursePrerequisite) *RequisiteGraph {
+	g := &RequisiteGraph{edges: make(map[uint][]requisiteEdge)}
+	for _, r := range rows {
+		g.Add(r.AllCoursesID, r.RequiresID, r.Kind)
+	}
+	return g
+}
+
+// LoadRequisiteGraph โหลดความสัมพันธ์ทั้งหมดจากฐานข้อมูล
+func LoadRequisiteGraph() (*RequisiteGraph, error) {
+	var rows []entity.CoursePrerequisite
+	if err := db.Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	return NewRequisiteGraph(rows), nil
+}
+
+func (g *RequisiteGraph) Add(courseID, requiresID uint, kind string) {
+	g.edges[courseID] = append(g.edges[courseID], requisiteEdge{to: requiresID, pre: kind == entity.RequisitePre})
+}
+
+// CyclePath ตรวจว่าการเพิ่ม courseID -> requiresID ทำให้เกิดวงวนหรือไม่ คืนเส้นทางวิชาที่วน (nil = ไม่วน)
+// วิชาเรียนร่วมที่อ้างถึงกันเอง (ไม่มีวิชาบังคับก่อนในวง) ไม่นับเป็นวงวน
+func (g *RequisiteGraph) CyclePath(courseID, requiresID uint, kind string) []uint {
+	if courseID == requiresID {
+		return []uint{courseID, courseID}
+	}
+
+	type state struct {
+		node uint
+		pre  bool
+	}
+	seen := make(map[state]bool)
+	var path []uint
+
+	var visit func(node uint, pre bool) bool
+	visit = func(node uint, pre bool) bool {
+		path = append(path, node)
+		if node == courseID && pre {
+			return true
+		}
+		s := state{node, pre}
+		if !seen[s] {
+			seen[s] = true
+			for _, e := range g.edges[node] {
+				if visit(e.to, pre || e.pre) {
+					return true
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		return false
+	}
+
+	path = []uint{courseID}
+	if visit(requiresID, kind == entity.RequisitePre) {
+		return path
+	}
+	return nil
+}
+
+// Prerequisites วิชาบังคับก่อนทั้งหมดของวิชา (รวมทางอ้อม ไม่รวมวิชาเรียนร่วม)
+func (g *RequisiteGraph) Prerequisites(courseID uint) []uint {
+	seen := make(map[uint]bool)
+	out := []uint{}
+	var walk func(uint)
+	walk = func(id uint) {
+		for _, e := range g.edges[id] {
+			if e.pre && !seen[e.to] {
+				seen[e.to] = true
+				out = append(out, e.to)
+				walk(e.to)
+			}
+		}
+	}
+	walk(courseID)
+	return out
+}
+
+// RemapRequisites แปลงความสัมพันธ์ของวิชาต้นทางไปยังวิชาที่คัดลอกแล้ว (idMap: วิชาต้นทาง -> วิชาปลายทาง)
+// วิชาที่ต้องเรียนก่อนซึ่งอยู่นอกหลักสูตรต้นทาง (เช่น วิชาศึกษาทั่วไป) คงไว้ตามเดิม
+// ส่วนวิชาในหลักสูตรต้นทางที่ไม่มีคู่ในปลายทางจะถูกตัดออก (skipped)
+func RemapRequisites(rows []entity.CoursePrerequisite, idMap map[uint]uint, inSource map[uint]bool) (mapped, skipped []entity.CoursePrerequisite) {
+	mapped, skipped = []entity.CoursePrerequisite{}, []entity.CoursePrerequisite{}
+	for _, r := range rows {
+		course, ok := idMap[r.AllCoursesID]
+		if !ok {
+			continue
+		}
+		requires := r.RequiresID
+		if inSource[requires] {
+			if requires, ok = idMap[requires]; !ok {
+				skipped = append(skipped, r)
+				continue
+			}
+		}
+		mapped = append(mapped, entity.CoursePrerequisite{Kind: r.Kind, AllCoursesID: course, RequiresID: requires})
+	}
+	return mapped, skipped
+}
@@ -0,0 +1,113 @@
+package config
+
+import (
+	"errors"
+	"log"
+	"regexp"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"github.com/Nichakorn25/CPE-Teaching-Schedule/entity"
+)
+
+var roomNameNoise = regexp.MustCompile(`[\s\-_.]+`)
+
+// NormalizeRoomName ทำชื่อห้องให้อยู่ในรูปเดียวกันก่อนเทียบ เช่น "b 4101" กับ "B4101"
+func NormalizeRoomName(name string) string {
+	return strings.ToUpper(roomNameNoise.ReplaceAllString(strings.TrimSpace(name), ""))
+}
+
+// FindRoomByName หาห้องในแคตตาล็อกจากชื่อห้องแบบข้อความอิสระ (เช่น RoomFix) คืน nil ถ้าไม่พบ
+func FindRoomByName(name string) *entity.Room {
+	norm := NormalizeRoomName(name)
+	if norm == "" {
+		return nil
+	}
+
+	var room entity.Room
+	if err := db.
+		Where(`UPPER(REGEXP_REPLACE(name, '[\s\-_.]+', '', 'g')) = ?`, norm).
+		Order("id").
+		First(&room).Error; err != nil {
+		return nil
+	}
+	return &room
+}
+
+// SyncLaboratoryRoom สร้าง/อัปเดตห้องประเภทปฏิบัติการที่คู่กับ Laboratory (ใช้ tx เดียวกับที่บันทึก Laboratory)
+func SyncLaboratoryRoom(tx *gorm.DB, lab entity.Laboratory) error {
+	var room entity.Room
+	err := tx.Where("laboratory_id = ?", lab.ID).First(&room).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		room = entity.Room{
+			Name:         lab.Room,
+			Building:     lab.Building,
+			RoomType:     entity.RoomTypeLab,
+			Capacity:     lab.Capacity,
+			LaboratoryID: &lab.ID,
+		}
+		return tx.Create(&room).Error
+	}
+	if err != nil {
+		return err
+	}
+
+	room.Name = lab.Room
+	room.Building = lab.Building
+	room.Capacity = lab.Capacity
+	return tx.Save(&room).Error
+}
+
+// ResolveFixedCourseRooms จับคู่ RoomFix ของวิชาจากศูนย์บริการกับแคตตาล็อกห้อง แล้วคืนรายการที่ยังจับคู่ไม่ได้
+func ResolveFixedCourseRooms() ([]entity.TimeFixedCourses, error) {
+	var fixed []entity.TimeFixedCourses
+	if err := db.Where("room_id IS NULL").Find(&fixed).Error; err != nil {
+		return nil, err
+	}
+
+	unresolved := make([]entity.TimeFixedCourses, 0)
+	for _, tf := range fixed {
+		room := FindRoomByName(tf.RoomFix)
+		if room == nil {
+			unresolved = append(unresolved, tf)
+			continue
+		}
+		if err := db.Model(&tf).Update("room_id", room.ID).Error; err != nil {
+			return nil, err
+		}
+		if tf.ScheduleID != 0 {
+			if err := db.Model(&entity.Schedule{}).
+				Where("id = ? AND room_id IS NULL", tf.ScheduleID).
+				Update("room_id", room.ID).Error; err != nil {
+				return nil, err
+			}
+		}
+	}
+	return unresolved, nil
+}
+
+// SyncRooms เตรียมแคตตาล็อกห้องจากข้อมูลเดิม: ห้องปฏิบัติการ, RoomFix และห้องของคาบที่ผูกกับห้องปฏิบัติการ
+func SyncRooms() {
+	var labs []entity.Laboratory
+	db.Find(&labs)
+	for _, lab := range labs {
+		if err := SyncLaboratoryRoom(db, lab); err != nil {
+			log.Printf("sync room for laboratory %d failed: %v", lab.ID, err)
+		}
+	}
+
+	if _, err := ResolveFixedCourseRooms(); err != nil {
+		log.Printf("resolve fixed course rooms failed: %v", err)
+	}
+
+	if err := db.Exec(`
+		UPDATE schedules SET room_id = rooms.id
+		FROM offered_courses, rooms
+		WHERE schedules.offered_courses_id = offered_courses.id
+			AND rooms.laboratory_id = offered_courses.laboratory_id
+			AND rooms.deleted_at IS NULL
+			AND schedules.room_id IS NULL`).Error; err != nil {
+		log.Printf("backfill schedule rooms failed: %v", err)
+	}
+}
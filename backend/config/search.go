@@ -0,0 +1,35 @@
+package config
+
+import "log"
+
+var trigramEnabled bool
+
+// TrigramEnabled ฐานข้อมูลมีส่วนขยาย pg_trgm (ใช้จัดอันดับผลค้นหาแบบคล้ายคลึงได้)
+func TrigramEnabled() bool {
+	return trigramEnabled
+}
+
+// migrateSearchIndexes เปิด pg_trgm และสร้างดัชนี trigram สำหรับ /search
+// ข้อความภาษาไทยไม่มีช่องว่างระหว่างคำ full-text search ของ PostgreSQL จึงตัดคำไทยไม่ได้
+// ใช้ ILIKE (เร่งด้วยดัชนี trigram) แทน ถ้าติดตั้งส่วนขยายไม่ได้จะค้นด้วย ILIKE อย่างเดียว
+func migrateSearchIndexes() {
+	if err := db.Exec(`CREATE EXTENSION IF NOT EXISTS pg_trgm`).Error; err != nil {
+		log.Printf("pg_trgm unavailable, search falls back to ILIKE: %v", err)
+		return
+	}
+	trigramEnabled = true
+
+	indexes := []string{
+		`CREATE INDEX IF NOT EXISTS idx_all_courses_code_trgm ON all_courses USING gin (replace(code, ' ', '') gin_trgm_ops)`,
+		`CREATE INDEX IF NOT EXISTS idx_all_courses_thai_name_trgm ON all_courses USING gin (thai_name gin_trgm_ops)`,
+		`CREATE INDEX IF NOT EXISTS idx_all_courses_english_name_trgm ON all_courses USING gin (english_name gin_trgm_ops)`,
+		`CREATE INDEX IF NOT EXISTS idx_users_fullname_trgm ON users USING gin ((firstname || ' ' || lastname) gin_trgm_ops)`,
+		`CREATE INDEX IF NOT EXISTS idx_teaching_assistants_fullname_trgm ON teaching_assistants USING gin ((firstname || ' ' || lastname) gin_trgm_ops)`,
+		`CREATE INDEX IF NOT EXISTS idx_rooms_name_trgm ON rooms USING gin (name gin_trgm_ops)`,
+	}
+	for _, stmt := range indexes {
+		if err := db.Exec(stmt).Error; err != nil {
+			log.Printf("create search index failed: %v", err)
+		}
+	}
+}
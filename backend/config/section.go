@@ -0,0 +1,153 @@
+package config
+
+import (
+	"fmt"
+	"log"
+
+	"gorm.io/gorm"
+
+	"github.com/Nichakorn25/CPE-Teaching-Schedule/entity"
+)
+
+// ScheduledSectionsError ลดจำนวนกลุ่มเรียนไม่ได้เพราะกลุ่มที่จะถูกลบยังมีตารางสอนอยู่
+type ScheduledSectionsError struct {
+	Sections []uint // เลขกลุ่มที่ยังมีตารางสอน
+}
+
+func (e *ScheduledSectionsError) Error() string {
+	return fmt.Sprintf("sections %v still have schedules", e.Sections)
+}
+
+// EnsureSections ทำให้รายวิชาที่เปิดสอนมี Section ครบ 1..oc.Section และลบกลุ่มที่เกินออก
+// ถ้ากลุ่มที่เกินยังมีตารางสอนจะคืน *ScheduledSectionsError โดยไม่แก้ไขอะไร (ควรเรียกใน transaction เดียวกับที่บันทึก oc)
+// คืน map เลขกลุ่ม -> Section.ID
+func EnsureSections(tx *gorm.DB, oc entity.OfferedCourses) (map[uint]uint, error) {
+	var existing []entity.Section
+	if err := tx.Unscoped().Where("offered_courses_id = ?", oc.ID).Find(&existing).Error; err != nil {
+		return nil, err
+	}
+
+	ids := make(map[uint]uint)
+	extra := []uint{}
+	for _, s := range existing {
+		switch {
+		case s.SectionNumber > oc.Section:
+			extra = append(extra, s.ID)
+		case s.DeletedAt.Valid:
+			if err := tx.Unscoped().Model(&s).Update("deleted_at", nil).Error; err != nil {
+				return nil, err
+			}
+			ids[s.SectionNumber] = s.ID
+		default:
+			ids[s.SectionNumber] = s.ID
+		}
+	}
+
+	// กลุ่มที่จะถูกลบต้องไม่มีตารางสอนเหลืออยู่ (ทั้งที่ผูกด้วย section_id และเลขกลุ่มแบบเดิม)
+	var scheduled []uint
+	q := tx.Model(&entity.Schedule{}).Where("offered_courses_id = ?", oc.ID)
+	if len(extra) > 0 {
+		q = q.Where("(section_number > ? OR section_id IN ?)", oc.Section, extra)
+	} else {
+		q = q.Where("section_number > ?", oc.Section)
+	}
+	if err := q.Distinct().Order("section_number").Pluck("section_number", &scheduled).Error; err != nil {
+		return nil, err
+	}
+	if len(scheduled) > 0 {
+		return nil, &ScheduledSectionsError{Sections: scheduled}
+	}
+
+	for n := uint(1); n <= oc.Section; n++ {
+		if _, ok := ids[n]; ok {
+			continue
+		}
+		sec := entity.Section{
+			SectionNumber:    n,
+			Capacity:         oc.Capacity,
+			OfferedCoursesID: oc.ID,
+		}
+		if err := tx.Create(&sec).Error; err != nil {
+			return nil, err
+		}
+		ids[n] = sec.ID
+	}
+
+	if len(extra) > 0 {
+		if err := tx.Unscoped().Where("section_id IN ?", extra).Delete(&entity.SectionInstructor{}).Error; err != nil {
+			return nil, err
+		}
+		if err := tx.Unscoped().Where("section_id IN ?", extra).Delete(&entity.ScheduleTeachingAssistant{}).Error; err != nil {
+			return nil, err
+		}
+		if err := tx.Unscoped().Where("id IN ?", extra).Delete(&entity.Section{}).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	return ids, nil
+}
+
+// SectionIDOf หา Section.ID จากรายวิชาที่เปิดสอนและเลขกลุ่ม คืน nil ถ้าไม่พบ
+func SectionIDOf(offeredCoursesID, sectionNumber uint) *uint {
+	var sec entity.Section
+	if err := db.
+		Where("offered_courses_id = ? AND section_number = ?", offeredCoursesID, sectionNumber).
+		First(&sec).Error; err != nil {
+		return nil
+	}
+	return &sec.ID
+}
+
+// SyncSections ย้ายข้อมูลเดิมที่รู้จักกลุ่มเรียนแค่จากเลขกลุ่มมาผูกกับ Section
+//   - สร้าง Section ให้ทุกรายวิชาที่เปิดสอนตามจำนวนกลุ่ม
+//   - เติม section_id ให้ตารางสอนและผู้สอนรายกลุ่ม
+//   - ย้ายผู้ช่วยสอนที่ผูกรายคาบ (schedule_id) มาผูกกับกลุ่มเรียน โดยตัดรายการซ้ำในกลุ่มเดียวกัน
+func SyncSections() {
+	var offered []entity.OfferedCourses
+	if err := db.Find(&offered).Error; err != nil {
+		log.Printf("SyncSections: load offered courses failed: %v", err)
+		return
+	}
+	for _, oc := range offered {
+		if _, err := EnsureSections(db, oc); err != nil {
+			log.Printf("SyncSections: offered course %d: %v", oc.ID, err)
+		}
+	}
+
+	steps := []string{
+		`UPDATE schedules SET section_id = sections.id
+			FROM sections
+			WHERE schedules.section_id IS NULL
+			AND sections.deleted_at IS NULL
+			AND sections.offered_courses_id = schedules.offered_courses_id
+			AND sections.section_number = schedules.section_number`,
+		`UPDATE section_instructors SET section_id = sections.id
+			FROM sections
+			WHERE section_instructors.section_id IS NULL
+			AND sections.deleted_at IS NULL
+			AND sections.offered_courses_id = section_instructors.offered_courses_id
+			AND sections.section_number = section_instructors.section_number`,
+		// ตัดแถวรายคาบที่จะซ้ำกับแถวอื่นของกลุ่มเดียวกันก่อนเติม section_id (idx_section_ta ไม่ยอมให้ซ้ำ)
+		`DELETE FROM schedule_teaching_assistants a
+			USING schedules sa, schedule_teaching_assistants b
+			LEFT JOIN schedules sb ON sb.id = b.schedule_id
+			WHERE a.section_id IS NULL AND a.deleted_at IS NULL
+			AND sa.id = a.schedule_id AND sa.section_id IS NOT NULL
+			AND b.deleted_at IS NULL AND b.id <> a.id
+			AND b.teaching_assistant_id = a.teaching_assistant_id
+			AND (b.section_id = sa.section_id
+				OR (b.section_id IS NULL AND sb.section_id = sa.section_id AND b.id < a.id))`,
+		`UPDATE schedule_teaching_assistants SET section_id = schedules.section_id
+			FROM schedules
+			WHERE schedule_teaching_assistants.section_id IS NULL
+			AND schedule_teaching_assistants.schedule_id = schedules.id`,
+		`UPDATE schedule_teaching_assistants SET schedule_id = NULL
+			WHERE section_id IS NOT NULL AND schedule_id IS NOT NULL`,
+	}
+	for _, sql := range steps {
+		if err := db.Exec(sql).Error; err != nil {
+			log.Printf("SyncSections: %v", err)
+		}
+	}
+}
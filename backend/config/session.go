@@ -0,0 +1,198 @@
+package config
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/Nichakorn25/CPE-Teaching-Schedule/entity"
+)
+
+// sessionDayOrder ลำดับวันในสัปดาห์ ใช้หาวันปฏิบัติของข้อมูลเก่า
+var sessionDayOrder = map[string]int{
+	"จันทร์": 0, "อังคาร": 1, "พุธ": 2, "พฤหัสบดี": 3, "ศุกร์": 4, "เสาร์": 5, "อาทิตย์": 6,
+}
+
+func sameRoom(a, b *uint) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+func sameSection(a, b entity.Schedule) bool {
+	return a.NameTable == b.NameTable &&
+		a.OfferedCoursesID == b.OfferedCoursesID &&
+		a.SectionNumber == b.SectionNumber
+}
+
+// InferSessionKinds ชนิดคาบของแต่ละแถว (ลำดับเดียวกับ rows) แถวที่มี Kind อยู่แล้วคงค่าเดิม
+// rows ต้องเรียงตาม name_table, offered_courses_id, section_number, day_of_week, start_time
+//   - วิชาจากศูนย์บริการ หรือวิชาที่ไม่มีห้องปฏิบัติการ/ชั่วโมงปฏิบัติ เป็น lecture
+//   - แถวที่ยาวอย่างน้อยเท่าชั่วโมงปฏิบัติเป็น lab
+//   - แถวรายชั่วโมงแบบเดิม: ช่วงต่อเนื่องในห้องเดียวกันที่ยาวเท่าชั่วโมงปฏิบัติพอดีเป็น lab
+//     (ตัวจัดตารางเดิมวางคาบบรรยายก่อนวันปฏิบัติ จึงเลือกช่วงของวันท้ายสุดเพียงช่วงเดียวต่อกลุ่ม)
+func InferSessionKinds(rows []entity.Schedule, labHours func(entity.OfferedCourses) float64) []string {
+	kinds := make([]string, len(rows))
+	for i, s := range rows {
+		kinds[i] = s.Kind
+	}
+
+	legacy := func(i int) bool { return rows[i].Kind == "" && len(rows[i].TimeFixedCourses) == 0 }
+
+	for from := 0; from < len(rows); {
+		to := from
+		for to < len(rows) && sameSection(rows[from], rows[to]) {
+			to++
+		}
+
+		oc := rows[from].OfferedCourses
+		lab := labHours(oc)
+		hasLab := oc.LaboratoryID != nil && lab > 0
+
+		labFound := false
+		for i := from; i < to; i++ {
+			if legacy(i) && hasLab && rows[i].EndTime.Sub(rows[i].StartTime).Hours() >= lab {
+				kinds[i] = entity.SessionKindLab
+				labFound = true
+			}
+		}
+
+		// ช่วงต่อเนื่องของแถวเก่าที่ยาวเท่าชั่วโมงปฏิบัติพอดี เลือกวันท้ายสุด
+		runStart, runEnd := -1, -1
+		if hasLab && !labFound {
+			for i := from; i < to; {
+				if !legacy(i) {
+					i++
+					continue
+				}
+				j := i + 1
+				for j < to && legacy(j) &&
+					rows[j].DayOfWeek == rows[i].DayOfWeek &&
+					sameRoom(rows[j].RoomID, rows[i].RoomID) &&
+					rows[j-1].EndTime.Equal(rows[j].StartTime) {
+					j++
+				}
+				if rows[j-1].EndTime.Sub(rows[i].StartTime).Hours() == lab &&
+					(runStart == -1 || sessionDayOrder[rows[i].DayOfWeek] >= sessionDayOrder[rows[runStart].DayOfWeek]) {
+					runStart, runEnd = i, j
+				}
+				i = j
+			}
+		}
+
+		for i := from; i < to; i++ {
+			if kinds[i] != "" {
+				continue
+			}
+			if i >= runStart && i < runEnd {
+				kinds[i] = entity.SessionKindLab
+			} else {
+				kinds[i] = entity.SessionKindLecture
+			}
+		}
+		from = to
+	}
+	return kinds
+}
+
+// SessionMerge แถว From ต่อท้ายแถว Into ได้ (Into จะจบที่ EndTime)
+type SessionMerge struct {
+	Into    uint
+	From    uint
+	EndTime time.Time
+}
+
+// PlanSessionMerges หาแถวที่ต่อเนื่องกันของกลุ่มเดียวกัน (วันเดียวกัน ห้องเดียวกัน ชนิดคาบเดียวกัน) ที่รวมเป็นคาบเดียวได้
+// rows ต้องเรียงตาม name_table, offered_courses_id, section_number, day_of_week, start_time
+// แถวที่ยังไม่มี Kind และคาบของวิชาจากศูนย์บริการจะไม่ถูกรวม
+func PlanSessionMerges(rows []entity.Schedule) []SessionMerge {
+	merges := []SessionMerge{}
+	var prev *entity.Schedule
+	var end time.Time
+	for i := range rows {
+		cur := &rows[i]
+		if prev != nil &&
+			prev.Kind != "" &&
+			len(prev.TimeFixedCourses) == 0 && len(cur.TimeFixedCourses) == 0 &&
+			sameSection(*prev, *cur) &&
+			prev.DayOfWeek == cur.DayOfWeek &&
+			prev.Kind == cur.Kind &&
+			sameRoom(prev.RoomID, cur.RoomID) &&
+			end.Equal(cur.StartTime) {
+			end = cur.EndTime
+			merges = append(merges, SessionMerge{Into: prev.ID, From: cur.ID, EndTime: end})
+			continue
+		}
+		prev, end = cur, cur.EndTime
+	}
+	return merges
+}
+
+// CoalesceSchedules กำหนดชนิดคาบ (lecture/lab) ให้แถวเก่าที่ยังไม่มี แล้วรวมแถวแบบรายชั่วโมงที่ต่อเนื่องกัน
+// ของกลุ่มเดียวกัน (วันเดียวกัน ห้องเดียวกัน ชนิดคาบเดียวกัน) ให้เป็นคาบช่วงเดียว
+// คาบของวิชาจากศูนย์บริการ (ผูกกับ TimeFixedCourses) จะไม่ถูกรวม
+func CoalesceSchedules() {
+	var schedules []entity.Schedule
+	if err := db.
+		Preload("TimeFixedCourses").
+		Preload("OfferedCourses.AllCourses.Credit").
+		Order("name_table, offered_courses_id, section_number, day_of_week, start_time").
+		Find(&schedules).Error; err != nil {
+		log.Printf("CoalesceSchedules: load schedules failed: %v", err)
+		return
+	}
+
+	rules, err := LoadHoursRules()
+	if err != nil {
+		log.Printf("CoalesceSchedules: load hours rules failed: %v", err)
+	}
+	labHours := func(oc entity.OfferedCourses) float64 { return float64(rules.For(oc.AllCourses).Lab) }
+
+	// กำหนดชนิดคาบก่อนรวม แถวที่บันทึกชนิดไม่สำเร็จคงเป็นค่าว่างและจะไม่ถูกรวม
+	for i, kind := range InferSessionKinds(schedules, labHours) {
+		s := &schedules[i]
+		if s.Kind != "" {
+			continue
+		}
+		if err := db.Model(&entity.Schedule{}).Where("id = ? AND (kind IS NULL OR kind = '')", s.ID).Update("kind", kind).Error; err != nil {
+			log.Printf("CoalesceSchedules: set kind of schedule %d: %v", s.ID, err)
+			continue
+		}
+		s.Kind = kind
+	}
+
+	// ถ้ารวมแถวใดไม่สำเร็จ จะไม่ต่อแถวถัดไปเข้ากับคาบเดิมอีก เพื่อไม่ให้คาบยาวทับแถวที่ยังอยู่
+	merged := 0
+	failed := map[uint]bool{}
+	for _, m := range PlanSessionMerges(schedules) {
+		if failed[m.Into] {
+			continue
+		}
+		// ต่อคาบ ย้ายผู้ช่วยสอน และลบแถวที่ถูกรวมต้องสำเร็จพร้อมกัน แถวที่ถูกรวมลบแบบ soft delete เพื่อย้อนกลับได้
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Model(&entity.Schedule{}).Where("id = ?", m.Into).Update("end_time", m.EndTime).Error; err != nil {
+				return fmt.Errorf("extend schedule %d: %w", m.Into, err)
+			}
+			if err := tx.Model(&entity.ScheduleTeachingAssistant{}).Where("schedule_id = ?", m.From).Update("schedule_id", m.Into).Error; err != nil {
+				return fmt.Errorf("move teaching assistants of schedule %d: %w", m.From, err)
+			}
+			if err := tx.Delete(&entity.Schedule{}, m.From).Error; err != nil {
+				return fmt.Errorf("remove schedule %d: %w", m.From, err)
+			}
+			return nil
+		})
+		if err != nil {
+			log.Printf("CoalesceSchedules: %v", err)
+			failed[m.Into] = true
+			continue
+		}
+		merged++
+	}
+
+	if merged > 0 {
+		log.Printf("CoalesceSchedules: merged %d hourly rows into session blocks", merged)
+	}
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/Nichakorn25/CPE-Teaching-Schedule/config"
 	"github.com/Nichakorn25/CPE-Teaching-Schedule/entity"
+	"github.com/Nichakorn25/CPE-Teaching-Schedule/services"
 )
 
 func GetAllCourseByID(c *gin.Context) {
@@ -30,7 +31,7 @@ func GetAllCourseByID(c *gin.Context) {
 		Preload("OfferedCourses"). //
 		Preload("TimeFixedCourses"). //
 		First(&course, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "ไม่พบข้อมูลวิชา"})
+		respondError(c, http.StatusNotFound, "ไม่พบข้อมูลวิชา")
 		return
 	}
 
@@ -38,10 +39,51 @@ func GetAllCourseByID(c *gin.Context) {
 }
 
 
+var allCoursesListSpec = services.ListSpec{
+	Sorts: map[string]string{
+		"id":           "all_courses.id",
+		"code":         "all_courses.code",
+		"thai_name":    "all_courses.thai_name",
+		"english_name": "all_courses.english_name",
+		"credit":       "credits.unit",
+		"type":         "all_courses.type_of_courses_id",
+		"curriculum":   "all_courses.curriculum_id",
+	},
+	Filters: map[string]services.ListFilter{
+		"major_id":         {Kind: services.FilterUint, Where: "curriculums.major_id = ?"},
+		"department_id":    {Kind: services.FilterUint, Where: "majors.department_id = ?"},
+		"curriculum_id":    {Kind: services.FilterUint, Where: "all_courses.curriculum_id = ?"},
+		"type_id":          {Kind: services.FilterUint, Where: "all_courses.type_of_courses_id = ?"},
+		"academic_year_id": {Kind: services.FilterUint, Where: "all_courses.academic_year_id = ?"},
+		"instructor_id": {Kind: services.FilterUint, Where: `EXISTS (SELECT 1 FROM user_all_courses uac
+			WHERE uac.all_courses_id = all_courses.id AND uac.user_id = ? AND uac.deleted_at IS NULL)`},
+		"search": {Kind: services.FilterSearch, Where: "(all_courses.code ILIKE ? OR all_courses.thai_name ILIKE ? OR all_courses.english_name ILIKE ?)"},
+	},
+	DefaultSort: "id",
+}
+
+// GET /all-courses?page=&size=&sort=code&major_id=&department_id=&type_id=&instructor_id=&search=
 func GetAllCourses(c *gin.Context) {
-	var courses []entity.AllCourses
+	q, ok := listQuery(c, allCoursesListSpec)
+	if !ok {
+		return
+	}
+	base := func() *gorm.DB {
+		return q.Filter(config.DB().Model(&entity.AllCourses{}).
+			Joins("JOIN curriculums ON curriculums.id = all_courses.curriculum_id").
+			Joins("JOIN majors ON majors.id = curriculums.major_id").
+			Joins("LEFT JOIN credits ON credits.id = all_courses.credit_id"))
+	}
+
+	var total int64
+	if err := base().Count(&total).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, "ไม่สามารถดึงข้อมูลได้")
+		return
+	}
 
-	err := config.DB().Preload("Curriculum.Major").
+	var courses []entity.AllCourses
+	err := q.Paginate(base()).
+		Preload("Curriculum.Major").
 		Preload("AcademicYear").
 		Preload("TypeOfCourses").
 		Preload("Credit").
@@ -50,11 +92,11 @@ func GetAllCourses(c *gin.Context) {
 		Find(&courses).Error
 
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "ไม่สามารถดึงข้อมูลได้"})
+		respondError(c, http.StatusInternalServerError, "ไม่สามารถดึงข้อมูลได้")
 		return
 	}
 
-	var result []gin.H
+	result := []gin.H{}
 
 	for i, course := range courses {
 		var teachers []string
@@ -65,7 +107,7 @@ func GetAllCourses(c *gin.Context) {
 
 		result = append(result, gin.H{
 			"ID":         course.ID,
-			"No":         i + 1,
+			"No":         q.Offset() + i + 1,
 			"CourseCode": course.Code,
 			"ThaiCourseName": course.ThaiName,
 			"EnglishCourseName": course.EnglishName,
@@ -78,7 +120,7 @@ func GetAllCourses(c *gin.Context) {
 		})
 	}
 
-	c.JSON(http.StatusOK, result)
+	respondList(c, q, total, result, result)
 }
 
 // /////////////////////////////////////
@@ -96,23 +138,37 @@ type AllCoursesInput struct {
 	UserIDs         []uint
 }
 
+// course ค่าจาก input ทับลงบนรายวิชา (ใช้ตรวจแท็ก valid ก่อนบันทึก)
+func (in AllCoursesInput) course(ac entity.AllCourses) entity.AllCourses {
+	ac.Code = in.Code
+	ac.EnglishName = in.EnglishName
+	ac.ThaiName = in.ThaiName
+	ac.CurriculumID = in.CurriculumID
+	ac.AcademicYearID = in.AcademicYearID
+	ac.TypeOfCoursesID = in.TypeOfCoursesID
+	return ac
+}
+
 func CreateCourses(c *gin.Context) {
 	var input AllCoursesInput
 
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "ข้อมูลไม่ถูกต้อง"})
+		respondError(c, http.StatusBadRequest, "ข้อมูลไม่ถูกต้อง")
+		return
+	}
+	if !validateEntity(c, input.course(entity.AllCourses{})) {
 		return
 	}
 
 	// ตรวจสอบรหัสวิชาซ้ำ
 	var existingCourse entity.AllCourses
-	if err := config.DB().Where("code = ?", input.Code).First(&existingCourse).Error; err == nil {
-		// เจอแล้ว → ส่ง 409
-		c.JSON(http.StatusConflict, gin.H{"error": "รหัสวิชาซ้ำ"})
+	if err := config.DB().Where("code = ? AND curriculum_id = ?", input.Code, input.CurriculumID).First(&existingCourse).Error; err == nil {
+		// เจอแล้ว → ส่ง 409 (รหัสวิชาซ้ำได้ถ้าอยู่คนละหลักสูตร)
+		respondError(c, http.StatusConflict, "รหัสวิชาซ้ำในหลักสูตรนี้")
 		return
 	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
 		// ถ้า error อื่น
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "ไม่สามารถตรวจสอบรหัสวิชาได้"})
+		respondError(c, http.StatusInternalServerError, "ไม่สามารถตรวจสอบรหัสวิชาได้")
 		return
 	}
 
@@ -129,27 +185,20 @@ func CreateCourses(c *gin.Context) {
 			Self:    input.Self,
 		}
 		if err := config.DB().Create(&credit).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "ไม่สามารถสร้างข้อมูลหน่วยกิตได้"})
+			respondError(c, http.StatusInternalServerError, "ไม่สามารถสร้างข้อมูลหน่วยกิตได้")
 			return
 		}
 	} else if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "ไม่สามารถดึงข้อมูลหน่วยกิตได้"})
+		respondError(c, http.StatusInternalServerError, "ไม่สามารถดึงข้อมูลหน่วยกิตได้")
 		return
 	}
 
 	// สร้าง Course
-	course := entity.AllCourses{
-		Code:            input.Code,
-		EnglishName:     input.EnglishName,
-		ThaiName:        input.ThaiName,
-		CurriculumID:    input.CurriculumID,
-		AcademicYearID:  input.AcademicYearID,
-		TypeOfCoursesID: input.TypeOfCoursesID,
-		CreditID:        credit.ID,
-	}
+	course := input.course(entity.AllCourses{})
+	course.CreditID = credit.ID
 
 	if err := config.DB().Create(&course).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "เพิ่มข้อมูลรายวิชาล้มเหลว"})
+		respondError(c, http.StatusInternalServerError, "เพิ่มข้อมูลรายวิชาล้มเหลว")
 		return
 	}
 
@@ -164,7 +213,7 @@ func CreateCourses(c *gin.Context) {
 
 	if len(userAllCourses) > 0 {
 		if err := config.DB().Create(&userAllCourses).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "เพิ่มผู้สอนล้มเหลว"})
+			respondError(c, http.StatusInternalServerError, "เพิ่มผู้สอนล้มเหลว")
 			return
 		}
 	}
@@ -181,13 +230,28 @@ func UpdateAllCourses(c *gin.Context) {
 
 	var course entity.AllCourses
 	if err := config.DB().Preload("UserAllCourses").First(&course, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "ไม่พบรายวิชา"})
+		respondError(c, http.StatusNotFound, "ไม่พบรายวิชา")
 		return
 	}
 
 	var in AllCoursesInput
 	if err := c.ShouldBindJSON(&in); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "รูปแบบข้อมูลไม่ถูกต้อง"})
+		respondError(c, http.StatusBadRequest, "รูปแบบข้อมูลไม่ถูกต้อง")
+		return
+	}
+	if !validateEntity(c, in.course(course)) {
+		return
+	}
+
+	var dupCount int64
+	if err := config.DB().Model(&entity.AllCourses{}).
+		Where("code = ? AND curriculum_id = ? AND id <> ?", in.Code, in.CurriculumID, course.ID).
+		Count(&dupCount).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, "ไม่สามารถตรวจสอบรหัสวิชาได้")
+		return
+	}
+	if dupCount > 0 {
+		respondError(c, http.StatusConflict, "รหัสวิชาซ้ำในหลักสูตรนี้")
 		return
 	}
 
@@ -203,20 +267,15 @@ func UpdateAllCourses(c *gin.Context) {
 			Self:    in.Self,
 		}
 		if err := config.DB().Create(&credit).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "ไม่สามารถสร้างข้อมูลหน่วยกิตใหม่ได้"})
+			respondError(c, http.StatusInternalServerError, "ไม่สามารถสร้างข้อมูลหน่วยกิตใหม่ได้")
 			return
 		}
 	} else if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "ไม่สามารถตรวจสอบข้อมูลหน่วยกิตได้"})
+		respondError(c, http.StatusInternalServerError, "ไม่สามารถตรวจสอบข้อมูลหน่วยกิตได้")
 		return
 	}
 
-	course.Code = in.Code
-	course.EnglishName = in.EnglishName
-	course.ThaiName = in.ThaiName
-	course.CurriculumID = in.CurriculumID
-	course.AcademicYearID = in.AcademicYearID
-	course.TypeOfCoursesID = in.TypeOfCoursesID
+	course = in.course(course)
 	course.CreditID = credit.ID
 
 	// ใช้ Transaction เพื่อจัดการทั้งการอัปเดตวิชาและผู้สอน
@@ -249,7 +308,7 @@ func UpdateAllCourses(c *gin.Context) {
 	})
 
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "อัปเดตรายวิชาไม่สำเร็จ"})
+		respondError(c, http.StatusInternalServerError, "อัปเดตรายวิชาไม่สำเร็จ")
 		return
 	}
 
@@ -259,21 +318,23 @@ func UpdateAllCourses(c *gin.Context) {
 func DeleteAllCourses(c *gin.Context) {
 	id := c.Param("id")
 
-	err := config.DB().Transaction(func(tx *gorm.DB) error {
-		if err := tx.Where("all_courses_id = ?", id).Delete(&entity.UserAllCourses{}).Error; err != nil {
-			return err
-		}
-
-		if err := tx.Delete(&entity.AllCourses{}, id).Error; err != nil {
-			return err
-		}
-		return nil
-	})
+	var course entity.AllCourses
+	if err := config.DB().First(&course, id).Error; err != nil {
+		respondError(c, http.StatusNotFound, "ไม่พบรายวิชาที่ต้องการลบ")
+		return
+	}
 
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "ลบรายวิชาไม่สำเร็จ"})
+	// ผู้สอนของรายวิชา วิชาบังคับก่อน และรายวิชาเทียบเท่าถูกลบตามไปเสมอ
+	// ส่วนรายวิชาที่เปิดสอน วิชาจากศูนย์บริการ และแผนการเรียนต้องขอ cascade
+	plan, ok := deleteWithDependents(c, config.KindAllCourses, course.ID, "ลบรายวิชาไม่สำเร็จ")
+	if !ok {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "ลบรายวิชาสำเร็จ"})
+	c.JSON(http.StatusOK, gin.H{"message": "ลบรายวิชาสำเร็จ", "cascaded": plan.Cascaded, "detached": plan.Detached})
+}
+
+// RestoreAllCourses กู้คืนรายวิชาที่ถูกลบ
+func RestoreAllCourses(c *gin.Context) {
+	restoreDeleted(c, config.KindAllCourses)
 }
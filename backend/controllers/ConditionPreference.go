@@ -0,0 +1,221 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/Nichakorn25/CPE-Teaching-Schedule/config"
+	"github.com/Nichakorn25/CPE-Teaching-Schedule/entity"
+	"github.com/Nichakorn25/CPE-Teaching-Schedule/services"
+)
+
+type (
+	PreferenceInput struct {
+		DayOfWeek string
+		StartTime string
+		EndTime   string
+		Weight    int
+	}
+
+	PreferencesRequest struct {
+		MaxTeachingDays uint
+		MaxHoursPerDay  uint
+		Preferences     []PreferenceInput
+	}
+
+	PreferenceItem struct {
+		ID        uint
+		DayOfWeek string
+		Start     string
+		End       string
+		Weight    int
+	}
+)
+
+const maxPreferenceWeight = 5
+
+// loadTeacherPrefs ความต้องการด้านเวลาและจำนวนวัน/ชั่วโมงสอนสูงสุดของผู้สอนทุกคน
+func loadTeacherPrefs() (*services.TeacherPrefs, error) {
+	var prefs []entity.ConditionPreference
+	if err := config.DB().Find(&prefs).Error; err != nil {
+		return nil, err
+	}
+	var limits []entity.ConditionLimit
+	if err := config.DB().Find(&limits).Error; err != nil {
+		return nil, err
+	}
+	return services.NewTeacherPrefs(prefs, limits), nil
+}
+
+func overlapMinutes(aStart, aEnd, bStart, bEnd int) int {
+	return services.OverlapMinutes(aStart, aEnd, bStart, bEnd)
+}
+
+// preferenceReport รายงานความพอใจของผู้สอนพร้อมชื่อผู้สอน
+func preferenceReport(prefs *services.TeacherPrefs, schedules []entity.Schedule, ix *instructorIndex) []services.PreferenceSatisfaction {
+	report := prefs.SatisfactionReport(schedules, ix.teachesSchedule)
+	if len(report) == 0 {
+		return report
+	}
+
+	ids := make([]uint, 0, len(report))
+	for _, r := range report {
+		ids = append(ids, r.UserID)
+	}
+	var users []entity.User
+	config.DB().Preload("Title").Where("id IN ?", ids).Find(&users)
+	names := make(map[uint]string, len(users))
+	for _, u := range users {
+		names[u.ID] = fmt.Sprintf("%s%s %s", u.Title.Title, u.Firstname, u.Lastname)
+	}
+	for i := range report {
+		report[i].Fullname = names[report[i].UserID]
+	}
+	return report
+}
+
+// GET /conditions/user/:userID/preferences
+func GetConditionPreferences(c *gin.Context) {
+	userID := c.Param("userID")
+
+	var prefs []entity.ConditionPreference
+	if err := config.DB().Where("user_id = ?", userID).Order("id").Find(&prefs).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, "ไม่สามารถดึงความต้องการด้านเวลาได้")
+		return
+	}
+
+	// ไม่มีแถว = ผู้สอนยังไม่ได้กำหนดจำนวนวัน/ชั่วโมงสอนสูงสุด
+	var limit entity.ConditionLimit
+	if err := config.DB().Where("user_id = ?", userID).First(&limit).Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		respondServerError(c, http.StatusInternalServerError, "ไม่สามารถดึงจำนวนวันและชั่วโมงสอนสูงสุดได้", err)
+		return
+	}
+
+	items := make([]PreferenceItem, 0, len(prefs))
+	for _, p := range prefs {
+		items = append(items, PreferenceItem{
+			ID:        p.ID,
+			DayOfWeek: p.DayOfWeek,
+			Start:     p.StartTime.Format("15:04"),
+			End:       p.EndTime.Format("15:04"),
+			Weight:    p.Weight,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"MaxTeachingDays": limit.MaxTeachingDays,
+		"MaxHoursPerDay":  limit.MaxHoursPerDay,
+		"Preferences":     items,
+	})
+}
+
+// PUT /conditions/user/:userID/preferences แทนที่ความต้องการด้านเวลาทั้งหมดของผู้สอน
+func UpdateConditionPreferences(c *gin.Context) {
+	var user entity.User
+	if err := config.DB().First(&user, c.Param("userID")).Error; err != nil {
+		respondError(c, http.StatusNotFound, "ไม่พบผู้ใช้")
+		return
+	}
+
+	var req PreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "ข้อมูลไม่ถูกต้อง")
+		return
+	}
+	if req.MaxTeachingDays > 7 || req.MaxHoursPerDay > 24 {
+		respondError(c, http.StatusBadRequest, "จำนวนวันสอนหรือชั่วโมงต่อวันไม่ถูกต้อง")
+		return
+	}
+
+	loc, _ := time.LoadLocation("Asia/Bangkok")
+	const fixedDate = "2000-01-01"
+	layout := "2006-01-02 15:04"
+
+	prefs := make([]entity.ConditionPreference, 0, len(req.Preferences))
+	for _, input := range req.Preferences {
+		start, err1 := time.ParseInLocation(layout, fixedDate+" "+input.StartTime, loc)
+		end, err2 := time.ParseInLocation(layout, fixedDate+" "+input.EndTime, loc)
+		if err1 != nil || err2 != nil || !end.After(start) {
+			respondError(c, http.StatusBadRequest, "รูปแบบเวลาไม่ถูกต้อง")
+			return
+		}
+		if input.DayOfWeek != "" && dayIndex(input.DayOfWeek) < 0 {
+			respondError(c, http.StatusBadRequest, fmt.Sprintf("ไม่รู้จักวัน %s", input.DayOfWeek))
+			return
+		}
+		if input.Weight == 0 || input.Weight > maxPreferenceWeight || input.Weight < -maxPreferenceWeight {
+			respondError(c, http.StatusBadRequest, fmt.Sprintf("น้ำหนักต้องอยู่ระหว่าง -%d ถึง %d และไม่เป็น 0", maxPreferenceWeight, maxPreferenceWeight))
+			return
+		}
+		prefs = append(prefs, entity.ConditionPreference{
+			DayOfWeek: input.DayOfWeek,
+			StartTime: start,
+			EndTime:   end,
+			Weight:    input.Weight,
+			UserID:    user.ID,
+		})
+	}
+
+	if !validateEntity(c, prefs) {
+		return
+	}
+	if err := config.DB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", user.ID).Delete(&entity.ConditionPreference{}).Error; err != nil {
+			return err
+		}
+		if len(prefs) > 0 {
+			if err := tx.Create(&prefs).Error; err != nil {
+				return err
+			}
+		}
+
+		var limit entity.ConditionLimit
+		if err := tx.Where("user_id = ?", user.ID).First(&limit).Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		limit.UserID = user.ID
+		limit.MaxTeachingDays = req.MaxTeachingDays
+		limit.MaxHoursPerDay = req.MaxHoursPerDay
+		return tx.Save(&limit).Error
+	}); err != nil {
+		respondError(c, http.StatusInternalServerError, "ไม่สามารถบันทึกความต้องการด้านเวลาได้")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "บันทึกความต้องการด้านเวลาสำเร็จ"})
+}
+
+// GET /preference-report?year=2568&term=1 ความพอใจของผู้สอนต่อตารางที่จัดแล้ว
+func GetPreferenceReport(c *gin.Context) {
+	year := c.Query("year")
+	term := c.Query("term")
+	if year == "" || term == "" {
+		respondError(c, http.StatusBadRequest, "ต้องระบุ year และ term")
+		return
+	}
+	nameTable := fmt.Sprintf("ปีการศึกษา %s เทอม %s", year, term)
+
+	var schedules []entity.Schedule
+	if err := config.DB().Where("name_table = ?", nameTable).Find(&schedules).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, "ไม่สามารถดึงตารางสอนได้")
+		return
+	}
+
+	ix, err := loadInstructorIndex(year, term)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "ไม่สามารถดึงข้อมูลผู้สอนได้")
+		return
+	}
+	prefs, err := loadTeacherPrefs()
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "ไม่สามารถดึงความต้องการด้านเวลาได้")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"name_table": nameTable, "data": preferenceReport(prefs, schedules, ix)})
+}
@@ -0,0 +1,300 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Nichakorn25/CPE-Teaching-Schedule/config"
+	"github.com/Nichakorn25/CPE-Teaching-Schedule/entity"
+	"github.com/Nichakorn25/CPE-Teaching-Schedule/services"
+)
+
+const (
+	conditionKindWeekly = "weekly"
+	conditionKindRanged = "ranged"
+	conditionKindOnce   = "once"
+)
+
+// ConditionConflict คาบในตารางที่ทับเวลาที่ผู้สอนแจ้งว่าไม่ว่าง
+type ConditionConflict struct {
+	ScheduleID       uint
+	OfferedCoursesID uint
+	Code             string
+	SectionNumber    uint
+	DayOfWeek        string
+	Start            string
+	End              string
+	UserID           uint
+	Fullname         string
+	ConditionID      uint
+	Kind             string   // weekly | ranged | once
+	Dates            []string // วันที่ที่ไม่ว่างจริง (เฉพาะ ranged/once)
+	Note             string
+}
+
+func conditionKind(cond entity.Condition) string {
+	switch {
+	case cond.Date != nil:
+		return conditionKindOnce
+	case cond.IsWeekly():
+		return conditionKindWeekly
+	default:
+		return conditionKindRanged
+	}
+}
+
+// conditionPeriod ข้อความช่วงวันที่ของเงื่อนไข ใช้ในข้อความเตือน
+func conditionPeriod(cond entity.Condition) string {
+	switch {
+	case cond.Date != nil:
+		return formatConditionDate(cond.Date)
+	case cond.EffectiveFrom != nil && cond.EffectiveTo != nil:
+		return formatConditionDate(cond.EffectiveFrom) + " ถึง " + formatConditionDate(cond.EffectiveTo)
+	case cond.EffectiveFrom != nil:
+		return "ตั้งแต่ " + formatConditionDate(cond.EffectiveFrom)
+	case cond.EffectiveTo != nil:
+		return "จนถึง " + formatConditionDate(cond.EffectiveTo)
+	}
+	return "ทุกสัปดาห์"
+}
+
+// findConditionConflicts หาคาบที่ผู้สอนไม่ว่าง (ถ้ามี cal จะนับเฉพาะวันที่อยู่ในภาคเรียน)
+func findConditionConflicts(schedules []entity.Schedule, conds []entity.Condition, ix *instructorIndex, cal *entity.TermCalendar) []ConditionConflict {
+	byUser := make(map[uint][]entity.Condition)
+	for _, cond := range conds {
+		byUser[cond.UserID] = append(byUser[cond.UserID], cond)
+	}
+
+	conflicts := []ConditionConflict{}
+	for _, s := range schedules {
+		for _, uid := range ix.teachers(s.OfferedCoursesID, s.SectionNumber) {
+			for _, cond := range byUser[uid] {
+				if cond.DayOfWeek != s.DayOfWeek ||
+					overlapMinutes(clockMinutes(s.StartTime), clockMinutes(s.EndTime), clockMinutes(cond.StartTime), clockMinutes(cond.EndTime)) == 0 {
+					continue
+				}
+
+				kind := conditionKind(cond)
+				dates := []string{}
+				if kind != conditionKindWeekly {
+					found := conditionDates(cond, cal)
+					_, _, bounded := conditionWindow(cond, cal)
+					if bounded && len(found) == 0 {
+						continue
+					}
+					for _, d := range found {
+						dates = append(dates, d.Format(conditionDateLayout))
+					}
+				}
+
+				conflicts = append(conflicts, ConditionConflict{
+					ScheduleID:       s.ID,
+					OfferedCoursesID: s.OfferedCoursesID,
+					Code:             offeredCodeLabel(s.OfferedCourses),
+					SectionNumber:    s.SectionNumber,
+					DayOfWeek:        s.DayOfWeek,
+					Start:            s.StartTime.Format("15:04"),
+					End:              s.EndTime.Format("15:04"),
+					UserID:           uid,
+					Fullname:         instructorFullname(cond.User),
+					ConditionID:      cond.ID,
+					Kind:             kind,
+					Dates:            dates,
+					Note:             cond.Note,
+				})
+			}
+		}
+	}
+	return conflicts
+}
+
+// GET /condition-conflicts?year=2568&term=1 คาบที่ทับเวลาไม่ว่างของผู้สอน (ทั้งรายสัปดาห์และตามวันที่)
+func GetConditionConflicts(c *gin.Context) {
+	year := c.Query("year")
+	term := c.Query("term")
+	if year == "" || term == "" {
+		respondError(c, http.StatusBadRequest, "ต้องระบุ year และ term")
+		return
+	}
+	nameTable := fmt.Sprintf("ปีการศึกษา %s เทอม %s", year, term)
+
+	var schedules []entity.Schedule
+	if err := config.DB().
+		Preload("OfferedCourses.AllCourses").
+		Preload("OfferedCourses.Aliases.AllCourses").
+		Where("name_table = ?", nameTable).
+		Find(&schedules).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, "ไม่สามารถดึงตารางสอนได้")
+		return
+	}
+
+	var conds []entity.Condition
+	if err := config.DB().Preload("User.Title").Find(&conds).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, "ไม่สามารถดึงเงื่อนไขเวลาที่ไม่ว่างได้")
+		return
+	}
+
+	ix, err := loadInstructorIndex(year, term)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "ไม่สามารถดึงข้อมูลผู้สอนได้")
+		return
+	}
+	cal, err := loadTermCalendar(year, term)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "ไม่สามารถดึงวันเปิด-ปิดภาคเรียนได้")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"name_table": nameTable, "data": findConditionConflicts(schedules, conds, ix, cal)})
+}
+
+// ====================== ICS ======================
+
+var icsEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`)
+
+type icsWriter struct {
+	b strings.Builder
+}
+
+// line เขียนหนึ่งบรรทัดของ iCalendar พับบรรทัดที่ยาวเกิน 75 ไบต์ (ชื่อวิชาภาษาไทยยาวเกินได้ง่าย)
+func (w *icsWriter) line(format string, args ...interface{}) {
+	w.b.WriteString(services.FoldICSLine(fmt.Sprintf(format, args...)))
+	w.b.WriteString("\r\n")
+}
+
+// timezone เขียน VTIMEZONE ของเวลาไทย (UTC+7 ไม่มีเวลาออมแสง) ที่ DTSTART/DTEND อ้างถึง
+func (w *icsWriter) timezone() {
+	w.line("BEGIN:VTIMEZONE")
+	w.line("TZID:Asia/Bangkok")
+	w.line("BEGIN:STANDARD")
+	w.line("DTSTART:19700101T000000")
+	w.line("TZOFFSETFROM:+0700")
+	w.line("TZOFFSETTO:+0700")
+	w.line("TZNAME:ICT")
+	w.line("END:STANDARD")
+	w.line("END:VTIMEZONE")
+}
+
+// event เขียน VEVENT หนึ่งรายการ untilDate ไม่เป็นศูนย์ = ซ้ำทุกสัปดาห์จนถึงวันนั้น
+// เวลาเริ่ม/จบใช้นาฬิกาเวลาไทยของ start/end ไม่ว่าจะบันทึกไว้ใน time zone ใด
+func (w *icsWriter) event(uid string, first time.Time, start, end time.Time, untilDate time.Time, summary, location string) {
+	at := func(clock time.Time) string {
+		m := clockMinutes(clock)
+		return fmt.Sprintf("%sT%02d%02d00", first.Format("20060102"), m/60, m%60)
+	}
+	w.line("BEGIN:VEVENT")
+	w.line("UID:%s@cpe-teaching-schedule", uid)
+	w.line("DTSTAMP:%s", time.Now().UTC().Format("20060102T150405Z"))
+	w.line("DTSTART;TZID=Asia/Bangkok:%s", at(start))
+	w.line("DTEND;TZID=Asia/Bangkok:%s", at(end))
+	if !untilDate.IsZero() && untilDate.After(first) {
+		// UNTIL ต้องเป็นเวลา UTC เมื่อ DTSTART ระบุ TZID (RFC 5545)
+		until := time.Date(untilDate.Year(), untilDate.Month(), untilDate.Day(), 23, 59, 59, 0, time.FixedZone("Asia/Bangkok", 7*60*60))
+		w.line("RRULE:FREQ=WEEKLY;UNTIL=%s", until.UTC().Format("20060102T150405Z"))
+	}
+	w.line("SUMMARY:%s", icsEscaper.Replace(summary))
+	if location != "" {
+		w.line("LOCATION:%s", icsEscaper.Replace(location))
+	}
+	w.line("END:VEVENT")
+}
+
+// firstDateOn วันแรกตั้งแต่ from ที่ตรงกับวัน day (จันทร์ = 0)
+func firstDateOn(from time.Time, day int) time.Time {
+	return from.AddDate(0, 0, (day-weekdayIndex(from)+7)%7)
+}
+
+// GET /conditions/user/:userID/ics?year=2568&term=1 ปฏิทินสอนและวันที่ไม่ว่างของผู้สอน
+func GetInstructorICS(c *gin.Context) {
+	year := c.Query("year")
+	term := c.Query("term")
+
+	var user entity.User
+	if err := config.DB().Preload("Title").First(&user, c.Param("userID")).Error; err != nil {
+		respondError(c, http.StatusNotFound, "ไม่พบผู้ใช้")
+		return
+	}
+
+	cal, err := loadTermCalendar(year, term)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "ไม่สามารถดึงวันเปิด-ปิดภาคเรียนได้")
+		return
+	}
+	if cal == nil {
+		respondError(c, http.StatusBadRequest, "ยังไม่ได้กำหนดวันเปิด-ปิดภาคเรียนของเทอมนี้")
+		return
+	}
+	termStart, termEnd := dateOnly(cal.StartDate), dateOnly(cal.EndDate)
+
+	var schedules []entity.Schedule
+	if err := config.DB().
+		Preload("OfferedCourses.AllCourses").
+		Preload("OfferedCourses.Aliases.AllCourses").
+		Preload("Room").
+		Where("name_table = ?", fmt.Sprintf("ปีการศึกษา %s เทอม %s", year, term)).
+		Find(&schedules).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, "ไม่สามารถดึงตารางสอนได้")
+		return
+	}
+	ix, err := loadInstructorIndex(year, term)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "ไม่สามารถดึงข้อมูลผู้สอนได้")
+		return
+	}
+
+	var conds []entity.Condition
+	if err := config.DB().Where("user_id = ?", user.ID).Find(&conds).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, "ไม่สามารถดึงเงื่อนไขเวลาที่ไม่ว่างได้")
+		return
+	}
+
+	w := &icsWriter{}
+	w.line("BEGIN:VCALENDAR")
+	w.line("VERSION:2.0")
+	w.line("PRODID:-//CPE Teaching Schedule//TH")
+	w.line("CALSCALE:GREGORIAN")
+	w.line("X-WR-CALNAME:%s", icsEscaper.Replace(instructorFullname(user)))
+	w.timezone()
+
+	for _, s := range schedules {
+		if !ix.teaches(user.ID, s.OfferedCoursesID, s.SectionNumber) {
+			continue
+		}
+		day := dayIndex(s.DayOfWeek)
+		if day < 0 {
+			continue
+		}
+		ac := s.OfferedCourses.AllCourses
+		summary := fmt.Sprintf("%s %s กลุ่ม %d", offeredCodeLabel(s.OfferedCourses), ac.ThaiName, s.SectionNumber)
+		if s.Kind == entity.SessionKindLab {
+			summary += " (ปฏิบัติ)"
+		}
+		w.event(fmt.Sprintf("schedule-%d", s.ID), firstDateOn(termStart, day), s.StartTime, s.EndTime, termEnd, summary, s.Room.Name)
+	}
+
+	for _, cond := range conds {
+		from, to, ok := conditionWindow(cond, cal)
+		day := dayIndex(cond.DayOfWeek)
+		if !ok || day < 0 {
+			continue
+		}
+		first := firstDateOn(from, day)
+		if first.After(to) {
+			continue
+		}
+		summary := "ไม่ว่าง"
+		if cond.Note != "" {
+			summary += ": " + cond.Note
+		}
+		w.event(fmt.Sprintf("condition-%d", cond.ID), first, cond.StartTime, cond.EndTime, to, summary, "")
+	}
+
+	w.line("END:VCALENDAR")
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="schedule-%d-%s-%s.ics"`, user.ID, year, term))
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", []byte(w.b.String()))
+}
@@ -3,21 +3,29 @@ package controllers
 import (
 	"fmt"
 	"net/http"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"github.com/Nichakorn25/CPE-Teaching-Schedule/config"
 	"github.com/Nichakorn25/CPE-Teaching-Schedule/entity"
+	"github.com/Nichakorn25/CPE-Teaching-Schedule/services"
 )
 
 type (
 	ConditionInput struct {
-		DayOfWeek string
-		StartTime string
-		EndTime   string
+		DayOfWeek     string
+		StartTime     string
+		EndTime       string
+		EffectiveFrom string // "2006-01-02" ไม่บังคับ
+		EffectiveTo   string
+		Date          string // วันที่ไม่ว่างครั้งเดียว
+		Note          string
 	}
 
 	ConditionsRequest struct {
@@ -26,10 +34,14 @@ type (
 	}
 
 	ConditionItem struct {
-		ID        uint
-		DayOfWeek string
-		Start     string
-		End       string
+		ID            uint
+		DayOfWeek     string
+		Start         string
+		End           string
+		EffectiveFrom string `json:",omitempty"`
+		EffectiveTo   string `json:",omitempty"`
+		Date          string `json:",omitempty"`
+		Note          string `json:",omitempty"`
 	}
 
 	ConditionResponse struct {
@@ -46,98 +58,380 @@ type (
 	}
 )
 
-func CreateConditions(c *gin.Context) {
-	var req ConditionsRequest
+const conditionDateLayout = "2006-01-02"
 
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "ข้อมูลที่รับมาไม่ถูกต้อง"})
-		return
+func parseConditionDate(value string, loc *time.Location) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	d, err := time.ParseInLocation(conditionDateLayout, value, loc)
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+func formatConditionDate(d *time.Time) string {
+	if d == nil {
+		return ""
 	}
+	return d.Format(conditionDateLayout)
+}
 
+// buildConditions แปลงข้อมูลที่รับมาเป็น Condition
+// เงื่อนไขครั้งเดียวใช้วันของ Date เป็น DayOfWeek, เงื่อนไขช่วงวันที่ที่ไม่ระบุวันจะกระจายเป็นทุกวันในช่วงนั้น
+func buildConditions(userID uint, inputs []ConditionInput) ([]entity.Condition, string) {
 	loc, _ := time.LoadLocation("Asia/Bangkok")
 	const fixedDate = "2000-01-01"
 	layout := "2006-01-02 15:04"
 
-	for _, input := range req.Conditions {
+	conds := []entity.Condition{}
+	for _, input := range inputs {
 		startTime, err1 := time.ParseInLocation(layout, fixedDate+" "+input.StartTime, loc)
 		endTime, err2 := time.ParseInLocation(layout, fixedDate+" "+input.EndTime, loc)
-
 		if err1 != nil || err2 != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "รูปแบบเวลาไม่ถูกต้อง"})
-			return
+			return nil, "รูปแบบเวลาไม่ถูกต้อง"
+		}
+		if !endTime.After(startTime) {
+			return nil, fmt.Sprintf("เวลาสิ้นสุดต้องหลังเวลาเริ่ม (%s-%s)", input.StartTime, input.EndTime)
 		}
 
-		condition := entity.Condition{
-			DayOfWeek: input.DayOfWeek,
-			StartTime: startTime,
-			EndTime:   endTime,
-			UserID:    req.UserID,
+		from, err1 := parseConditionDate(input.EffectiveFrom, loc)
+		to, err2 := parseConditionDate(input.EffectiveTo, loc)
+		date, err3 := parseConditionDate(input.Date, loc)
+		if err1 != nil || err2 != nil || err3 != nil {
+			return nil, "รูปแบบวันที่ไม่ถูกต้อง (YYYY-MM-DD)"
+		}
+		if date != nil && (from != nil || to != nil) {
+			return nil, "ระบุได้อย่างใดอย่างหนึ่งระหว่างวันที่ครั้งเดียวหรือช่วงวันที่"
+		}
+		if from != nil && to != nil && to.Before(*from) {
+			return nil, "วันสิ้นสุดต้องไม่ก่อนวันเริ่มต้น"
 		}
 
-		if err := config.DB().Create(&condition).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "ไม่สามารถบันทึกเงื่อนไขเวลาที่ไม่ว่างได้"})
-			return
+		base := entity.Condition{
+			StartTime:     startTime,
+			EndTime:       endTime,
+			EffectiveFrom: from,
+			EffectiveTo:   to,
+			Date:          date,
+			Note:          input.Note,
+			UserID:        userID,
+		}
+
+		switch {
+		case date != nil:
+			base.DayOfWeek = getDayName(weekdayIndex(*date))
+			conds = append(conds, base)
+		case input.DayOfWeek == "" && from != nil && to != nil:
+			for d, n := *from, 0; !d.After(*to) && n < 7; d, n = d.AddDate(0, 0, 1), n+1 {
+				cond := base
+				cond.DayOfWeek = getDayName(weekdayIndex(d))
+				conds = append(conds, cond)
+			}
+		default:
+			if dayIndex(input.DayOfWeek) < 0 {
+				return nil, fmt.Sprintf("ไม่รู้จักวัน %s", input.DayOfWeek)
+			}
+			base.DayOfWeek = input.DayOfWeek
+			conds = append(conds, base)
 		}
 	}
+	return conds, ""
+}
 
-	c.JSON(http.StatusOK, gin.H{"message": "บันทึกเงื่อนไขเวลาที่ไม่ว่างสำเร็จ"})
+// conditionScope เงื่อนไขที่รวมกันได้ต้องเป็นวันเดียวกันและมีช่วงวันที่เดียวกัน
+func conditionScope(c entity.Condition) string {
+	return c.DayOfWeek + "|" + formatConditionDate(c.Date) + "|" + formatConditionDate(c.EffectiveFrom) + "|" + formatConditionDate(c.EffectiveTo)
 }
 
-func UpdateConditions(c *gin.Context) { 
-	var req ConditionsRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "ข้อมูลไม่ถูกต้อง"})
-		return
+// mergeConditions รวมช่วงเวลาที่ทับหรือต่อกันในขอบเขตเดียวกันเป็นช่วงเดียว คืนจำนวนช่วงที่ถูกรวม
+// ช่วงที่รวมแล้วใช้ ID/CreatedAt ของแถวที่บันทึกไว้แล้วแถวแรกในกลุ่ม (ถ้ามี) แถวที่บันทึกไว้อื่นในกลุ่มถือว่าถูกรวมไป
+func mergeConditions(conds []entity.Condition) ([]entity.Condition, int) {
+	sorted := append([]entity.Condition(nil), conds...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		si, sj := conditionScope(sorted[i]), conditionScope(sorted[j])
+		if si != sj {
+			return si < sj
+		}
+		return clockMinutes(sorted[i].StartTime) < clockMinutes(sorted[j].StartTime)
+	})
+
+	merged := []entity.Condition{}
+	for _, cond := range sorted {
+		if n := len(merged); n > 0 {
+			last := &merged[n-1]
+			if conditionScope(*last) == conditionScope(cond) &&
+				clockMinutes(cond.StartTime) <= clockMinutes(last.EndTime) {
+				if last.ID == 0 && cond.ID != 0 {
+					last.ID, last.CreatedAt, last.UpdatedAt = cond.ID, cond.CreatedAt, cond.UpdatedAt
+				}
+				if clockMinutes(cond.EndTime) > clockMinutes(last.EndTime) {
+					last.EndTime = cond.EndTime
+				}
+				if cond.Note != "" && !strings.Contains(last.Note, cond.Note) {
+					if last.Note != "" {
+						last.Note += ", "
+					}
+					last.Note += cond.Note
+				}
+				continue
+			}
+		}
+		merged = append(merged, cond)
 	}
+	return merged, len(conds) - len(merged)
+}
 
-	loc, _ := time.LoadLocation("Asia/Bangkok")
-	const fixedDate = "2000-01-01"
-	layout := "2006-01-02 15:04"
+// sameCondition เงื่อนไขสองแถวเป็นช่วงเวลาเดียวกันทุกอย่าง (ไม่สนใจ ID)
+func sameCondition(a, b entity.Condition) bool {
+	return conditionScope(a) == conditionScope(b) &&
+		clockMinutes(a.StartTime) == clockMinutes(b.StartTime) &&
+		clockMinutes(a.EndTime) == clockMinutes(b.EndTime) &&
+		a.Note == b.Note
+}
 
-	if err := config.DB().Transaction(func(tx *gorm.DB) error {
-		// 1) ลบ Condition เดิมทั้งหมดของ User
-		if err := tx.Where("user_id = ?", req.UserID).
-			Delete(&entity.Condition{}).Error; err != nil {
+// replaceConditions แทนที่เงื่อนไขของผู้ใช้ด้วยผลของ merge(เงื่อนไขเดิม) ภายใน transaction เดียว
+// ล็อกแถวผู้ใช้ไว้ระหว่างอ่าน-รวม-บันทึก เพื่อไม่ให้การบันทึกพร้อมกันทับกันจนช่วงเวลาหาย
+// แถวเดิมที่ไม่เปลี่ยนหรือรับช่วงอื่นมารวมยังคง ID/CreatedAt เดิม ลบเฉพาะแถวที่ถูกรวมหรือไม่อยู่ในชุดใหม่ และเพิ่มเฉพาะแถวใหม่
+func replaceConditions(userID uint, merge func(existing []entity.Condition) []entity.Condition) error {
+	return config.DB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&entity.User{}, userID).Error; err != nil {
 			return err
 		}
+		var existing []entity.Condition
+		if err := tx.Where("user_id = ?", userID).Find(&existing).Error; err != nil {
+			return err
+		}
+		next := merge(existing)
 
-		// 2) เพิ่ม Condition ใหม่ตามที่รับมา
-		for _, input := range req.Conditions {
-			start, err1 := time.ParseInLocation(layout, fixedDate+" "+input.StartTime, loc)
-			end, err2 := time.ParseInLocation(layout, fixedDate+" "+input.EndTime, loc)
-			if err1 != nil || err2 != nil {
-				return fmt.Errorf("รูปแบบเวลาไม่ถูกต้อง")
+		keep := map[uint]bool{}
+		for _, cond := range next {
+			if cond.ID != 0 {
+				keep[cond.ID] = true
+			}
+		}
+		// แถวใหม่ที่ตรงกับแถวเดิมทุกอย่างใช้แถวเดิมแทน (เช่น ส่งชุดเดิมกลับมาตอนแก้ไข)
+		for i := range next {
+			if next[i].ID != 0 {
+				continue
+			}
+			for _, old := range existing {
+				if !keep[old.ID] && sameCondition(old, next[i]) {
+					next[i].ID, next[i].CreatedAt, next[i].UpdatedAt = old.ID, old.CreatedAt, old.UpdatedAt
+					keep[old.ID] = true
+					break
+				}
+			}
+		}
+
+		stale := []uint{}
+		byID := map[uint]entity.Condition{}
+		for _, old := range existing {
+			byID[old.ID] = old
+			if !keep[old.ID] {
+				stale = append(stale, old.ID)
+			}
+		}
+		if len(stale) > 0 {
+			if err := tx.Where("id IN ?", stale).Delete(&entity.Condition{}).Error; err != nil {
+				return err
 			}
+		}
 
-			cond := entity.Condition{
-				DayOfWeek: input.DayOfWeek,
-				StartTime: start,
-				EndTime:   end,
-				UserID:    req.UserID,
+		fresh := []entity.Condition{}
+		for _, cond := range next {
+			if cond.ID == 0 {
+				fresh = append(fresh, cond)
+				continue
+			}
+			if sameCondition(byID[cond.ID], cond) {
+				continue
 			}
-			if err := tx.Create(&cond).Error; err != nil {
+			if err := tx.Model(&entity.Condition{}).Where("id = ?", cond.ID).Updates(map[string]interface{}{
+				"start_time": cond.StartTime,
+				"end_time":   cond.EndTime,
+				"note":       cond.Note,
+			}).Error; err != nil {
 				return err
 			}
 		}
-		return nil
+		if len(fresh) == 0 {
+			return nil
+		}
+		return tx.Create(&fresh).Error
+	})
+}
+
+// scheduledConflictsOf คาบในตารางที่จัดไว้แล้ว (ทุกเทอม) ที่ทับเงื่อนไขใหม่ของผู้ใช้
+func scheduledConflictsOf(userID uint) []ConditionConflict {
+	var conds []entity.Condition
+	if err := config.DB().Preload("User.Title").Where("user_id = ?", userID).Find(&conds).Error; err != nil || len(conds) == 0 {
+		return []ConditionConflict{}
+	}
+
+	var terms []struct {
+		Year uint
+		Term uint
+	}
+	config.DB().Model(&entity.OfferedCourses{}).Distinct("year", "term").Order("year, term").Scan(&terms)
+
+	conflicts := []ConditionConflict{}
+	for _, t := range terms {
+		year, term := strconv.Itoa(int(t.Year)), strconv.Itoa(int(t.Term))
+		var schedules []entity.Schedule
+		if err := config.DB().
+			Preload("OfferedCourses.AllCourses").
+			Preload("OfferedCourses.Aliases.AllCourses").
+			Where("name_table = ?", fmt.Sprintf("ปีการศึกษา %s เทอม %s", year, term)).
+			Find(&schedules).Error; err != nil || len(schedules) == 0 {
+			continue
+		}
+		ix, err := loadInstructorIndex(year, term)
+		if err != nil {
+			continue
+		}
+		cal, _ := loadTermCalendar(year, term)
+		conflicts = append(conflicts, findConditionConflicts(schedules, conds, ix, cal)...)
+	}
+	return conflicts
+}
+
+func conditionItem(cnd entity.Condition) ConditionItem {
+	return ConditionItem{
+		ID:            cnd.ID,
+		DayOfWeek:     cnd.DayOfWeek,
+		Start:         cnd.StartTime.Format("15:04"),
+		End:           cnd.EndTime.Format("15:04"),
+		EffectiveFrom: formatConditionDate(cnd.EffectiveFrom),
+		EffectiveTo:   formatConditionDate(cnd.EffectiveTo),
+		Date:          formatConditionDate(cnd.Date),
+		Note:          cnd.Note,
+	}
+}
+
+func CreateConditions(c *gin.Context) {
+	var req ConditionsRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "ข้อมูลที่รับมาไม่ถูกต้อง")
+		return
+	}
+
+	conds, msg := buildConditions(req.UserID, req.Conditions)
+	if msg != "" {
+		respondError(c, http.StatusBadRequest, msg)
+		return
+	}
+
+	if err := config.DB().First(&entity.User{}, req.UserID).Error; err != nil {
+		respondError(c, http.StatusNotFound, "ไม่พบผู้ใช้")
+		return
+	}
+
+	// เพิ่มต่อจากเงื่อนไขเดิม แล้วรวมช่วงที่ทับกัน (อ่าน-รวม-บันทึกใน transaction เดียว)
+	if !validateEntity(c, conds) {
+		return
+	}
+	mergedCount := 0
+	if err := replaceConditions(req.UserID, func(existing []entity.Condition) []entity.Condition {
+		var merged []entity.Condition
+		merged, mergedCount = mergeConditions(append(existing, conds...))
+		return merged
+	}); err != nil {
+		respondError(c, http.StatusInternalServerError, "ไม่สามารถบันทึกเงื่อนไขเวลาที่ไม่ว่างได้")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "บันทึกเงื่อนไขเวลาที่ไม่ว่างสำเร็จ",
+		"merged":    mergedCount,
+		"conflicts": scheduledConflictsOf(req.UserID),
+	})
+}
+
+func UpdateConditions(c *gin.Context) { 
+	var req ConditionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "ข้อมูลไม่ถูกต้อง")
+		return
+	}
+
+	conds, msg := buildConditions(req.UserID, req.Conditions)
+	if msg != "" {
+		respondError(c, http.StatusBadRequest, msg)
+		return
+	}
+
+	if err := config.DB().First(&entity.User{}, req.UserID).Error; err != nil {
+		respondError(c, http.StatusNotFound, "ไม่พบผู้ใช้")
+		return
+	}
+
+	// แทนที่เงื่อนไขเดิมด้วยชุดใหม่ (รวมช่วงที่ทับกันแล้ว) ใน transaction เดียว
+	merged, mergedCount := mergeConditions(conds)
+	if !validateEntity(c, merged) {
+		return
+	}
+	if err := replaceConditions(req.UserID, func([]entity.Condition) []entity.Condition {
+		return merged
 	}); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, http.StatusInternalServerError, "ไม่สามารถอัปเดตเงื่อนไขเวลาที่ไม่ว่างได้")
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "อัปเดตเงื่อนไขเวลาที่ไม่ว่างสำเร็จ"})
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "อัปเดตเงื่อนไขเวลาที่ไม่ว่างสำเร็จ",
+		"merged":    mergedCount,
+		"conflicts": scheduledConflictsOf(req.UserID),
+	})
 }
 
+var conditionListSpec = services.ListSpec{
+	Sorts: map[string]string{
+		"id":        "users.id",
+		"username":  "users.username",
+		"firstname": "users.firstname",
+		"lastname":  "users.lastname",
+		"major":     "majors.major_name",
+		"updated":   "(SELECT MAX(cd.updated_at) FROM conditions cd WHERE cd.user_id = users.id AND cd.deleted_at IS NULL)",
+	},
+	Filters: map[string]services.ListFilter{
+		"major_id":      {Kind: services.FilterUint, Where: "users.major_id = ?"},
+		"department_id": {Kind: services.FilterUint, Where: "majors.department_id = ?"},
+		"instructor_id": {Kind: services.FilterUint, Where: "users.id = ?"},
+		"day":           {Kind: services.FilterString, Where: "EXISTS (SELECT 1 FROM conditions cd WHERE cd.user_id = users.id AND cd.deleted_at IS NULL AND cd.day_of_week = ?)"},
+		"search":        {Kind: services.FilterSearch, Where: "(users.firstname ILIKE ? OR users.lastname ILIKE ? OR users.username ILIKE ?)"},
+	},
+	DefaultSort: "id",
+}
+
+// GET /conditions?page=&size=&sort=&major_id=&department_id=&day=&search= (เฉพาะผู้สอนที่มีเวลาไม่ว่าง)
 func GetAllCondition(c *gin.Context) {
-	var users []entity.User
+	q, ok := listQuery(c, conditionListSpec)
+	if !ok {
+		return
+	}
+	base := func() *gorm.DB {
+		return q.Filter(config.DB().Model(&entity.User{}).
+			Joins("LEFT JOIN majors ON majors.id = users.major_id").
+			Where("EXISTS (SELECT 1 FROM conditions cd WHERE cd.user_id = users.id AND cd.deleted_at IS NULL)"))
+	}
+
+	var total int64
+	if err := base().Count(&total).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, "เกิดข้อผิดพลาดในการดึงข้อมูล")
+		return
+	}
 
-	err := config.DB().Preload("Title").Preload("Major").Preload("Conditions").Find(&users).Error
+	var users []entity.User
+	err := q.Paginate(base()).Preload("Title").Preload("Major").Preload("Conditions").Find(&users).Error
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "เกิดข้อผิดพลาดในการดึงข้อมูล"})
+		respondError(c, http.StatusInternalServerError, "เกิดข้อผิดพลาดในการดึงข้อมูล")
 		return
 	}
 
-	var responses []ConditionResponse
+	responses := []ConditionResponse{}
 
 	for _, user := range users {
 		if len(user.Conditions) == 0 {
@@ -155,12 +449,7 @@ func GetAllCondition(c *gin.Context) {
 				updated = cnd.UpdatedAt
 			}
 
-			items = append(items, ConditionItem{
-				ID:        cnd.ID,
-				DayOfWeek: cnd.DayOfWeek,
-				Start:     cnd.StartTime.Format("15:04"),
-				End:       cnd.EndTime.Format("15:04"),
-			})
+			items = append(items, conditionItem(cnd))
 		}
 
 		resp := ConditionResponse{
@@ -179,13 +468,13 @@ func GetAllCondition(c *gin.Context) {
 		responses = append(responses, resp)
 	}
 
-	c.JSON(http.StatusOK, responses)
+	respondList(c, q, total, responses, responses)
 }
 
 func GetConditionByuserID(c *gin.Context) {
 	uid, err := strconv.Atoi(c.Param("userID"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "userID ไม่ถูกต้อง"})
+		respondError(c, http.StatusBadRequest, "userID ไม่ถูกต้อง")
 		return
 	}
 
@@ -197,7 +486,7 @@ func GetConditionByuserID(c *gin.Context) {
 		First(&user, uid).Error
 
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "ไม่พบผู้ใช้"})
+		respondError(c, http.StatusNotFound, "ไม่พบผู้ใช้")
 		return
 	}
 
@@ -213,11 +502,7 @@ func GetConditionByuserID(c *gin.Context) {
 			updated = cnd.UpdatedAt
 		}
 
-		items = append(items, ConditionItem{
-			DayOfWeek: cnd.DayOfWeek,
-			Start:     cnd.StartTime.Format("15:04"),
-			End:       cnd.EndTime.Format("15:04"),
-		})
+		items = append(items, conditionItem(cnd))
 	}
 
 	resp := ConditionResponse{
@@ -239,14 +524,14 @@ func GetConditionByuserID(c *gin.Context) {
 func DeleteConditionsByUser(c *gin.Context) {
 	uid, err := strconv.Atoi(c.Param("userID"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "userID ไม่ถูกต้อง"})
+		respondError(c, http.StatusBadRequest, "userID ไม่ถูกต้อง")
 		return
 	}
 
 	if err := config.DB().
 		Where("user_id = ?", uid).
 		Delete(&entity.Condition{}).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "ลบข้อมูลไม่สำเร็จ"})
+		respondError(c, http.StatusInternalServerError, "ลบข้อมูลไม่สำเร็จ")
 		return
 	}
 
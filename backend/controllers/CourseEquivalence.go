@@ -0,0 +1,263 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/Nichakorn25/CPE-Teaching-Schedule/config"
+	"github.com/Nichakorn25/CPE-Teaching-Schedule/entity"
+)
+
+type (
+	EquivalenceInput struct {
+		EquivalentID uint `json:"equivalent_id" binding:"required"`
+	}
+
+	OfferedCourseAliasesInput struct {
+		AllCoursesIDs []uint `json:"all_courses_ids"`
+	}
+
+	EquivalentCourse struct {
+		ID             uint
+		Code           string
+		ThaiName       string
+		EnglishName    string
+		CurriculumID   uint
+		CurriculumName string
+		Direct         bool // บันทึกไว้ตรง ๆ (ลบได้) หรือเทียบเท่าต่อกันเป็นทอด
+	}
+)
+
+// servedCourses รายวิชาทั้งหมดที่เรียนในวิชาที่เปิดสอน (วิชาหลักและวิชาเทียบเท่า) ต้อง preload Aliases.AllCourses
+func servedCourses(oc entity.OfferedCourses) []entity.AllCourses {
+	out := []entity.AllCourses{oc.AllCourses}
+	for _, a := range oc.Aliases {
+		out = append(out, a.AllCourses)
+	}
+	return out
+}
+
+// offeredCodes รหัสวิชาทั้งหมดของวิชาที่เปิดสอน เริ่มจากวิชาหลัก
+func offeredCodes(oc entity.OfferedCourses) []string {
+	codes := make([]string, 0, len(oc.Aliases)+1)
+	for _, ac := range servedCourses(oc) {
+		codes = append(codes, ac.Code)
+	}
+	return codes
+}
+
+// offeredCodeLabel รหัสวิชาสำหรับแสดงผลและส่งออก เช่น "ENG23 1001/ENG23 1101"
+func offeredCodeLabel(oc entity.OfferedCourses) string {
+	return strings.Join(offeredCodes(oc), "/")
+}
+
+// servingOffering วิชาที่เปิดสอนในปี/เทอมเดียวกันซึ่งมีรายวิชานี้อยู่แล้ว (เป็นวิชาหลักหรือวิชาเทียบเท่า)
+func servingOffering(allCoursesID, year, term, exceptID uint) (*entity.OfferedCourses, error) {
+	var oc entity.OfferedCourses
+	err := config.DB().Preload("AllCourses").
+		Where("year = ? AND term = ? AND id <> ?", year, term, exceptID).
+		Where("all_courses_id = ? OR id IN (?)", allCoursesID,
+			config.DB().Model(&entity.OfferedCourseAlias{}).Select("offered_courses_id").Where("all_courses_id = ?", allCoursesID)).
+		First(&oc).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &oc, nil
+}
+
+// GET /all-courses/:id/equivalents
+func GetCourseEquivalents(c *gin.Context) {
+	var course entity.AllCourses
+	if err := config.DB().First(&course, c.Param("id")).Error; err != nil {
+		respondError(c, http.StatusNotFound, "ไม่พบรายวิชา")
+		return
+	}
+
+	var rows []entity.CourseEquivalence
+	if err := config.DB().Find(&rows).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, "ไม่สามารถดึงรายวิชาเทียบเท่าได้")
+		return
+	}
+	direct := map[uint]bool{}
+	for _, r := range rows {
+		if r.AllCoursesID == course.ID {
+			direct[r.EquivalentID] = true
+		} else if r.EquivalentID == course.ID {
+			direct[r.AllCoursesID] = true
+		}
+	}
+
+	ids := config.NewEquivalence(rows).Class(course.ID)
+	var courses []entity.AllCourses
+	if err := config.DB().Preload("Curriculum").Where("id IN ?", append([]uint{0}, ids...)).Order("code").Find(&courses).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, "ไม่สามารถดึงรายวิชาเทียบเท่าได้")
+		return
+	}
+
+	out := make([]EquivalentCourse, 0, len(courses))
+	for _, ac := range courses {
+		out = append(out, EquivalentCourse{
+			ID:             ac.ID,
+			Code:           ac.Code,
+			ThaiName:       ac.ThaiName,
+			EnglishName:    ac.EnglishName,
+			CurriculumID:   ac.CurriculumID,
+			CurriculumName: ac.Curriculum.CurriculumName,
+			Direct:         direct[ac.ID],
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{"courseId": course.ID, "equivalents": out})
+}
+
+// POST /all-courses/:id/equivalents
+func CreateCourseEquivalence(c *gin.Context) {
+	var course entity.AllCourses
+	if err := config.DB().First(&course, c.Param("id")).Error; err != nil {
+		respondError(c, http.StatusNotFound, "ไม่พบรายวิชา")
+		return
+	}
+
+	var input EquivalenceInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		respondBindError(c, err)
+		return
+	}
+	var other entity.AllCourses
+	if err := config.DB().First(&other, input.EquivalentID).Error; err != nil {
+		respondError(c, http.StatusBadRequest, "ไม่พบรายวิชาเทียบเท่า")
+		return
+	}
+	if other.CurriculumID == course.CurriculumID {
+		respondError(c, http.StatusBadRequest, "รายวิชาเทียบเท่าต้องอยู่คนละหลักสูตร")
+		return
+	}
+
+	eq, err := config.LoadEquivalence()
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "ไม่สามารถดึงรายวิชาเทียบเท่าได้")
+		return
+	}
+	if eq.Equivalent(course.ID, other.ID) {
+		respondError(c, http.StatusConflict, fmt.Sprintf("%s เทียบเท่ากับ %s อยู่แล้ว", course.Code, other.Code))
+		return
+	}
+
+	row := entity.CourseEquivalence{AllCoursesID: course.ID, EquivalentID: other.ID}
+	if !validateEntity(c, row) {
+		return
+	}
+	if err := config.DB().Create(&row).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, "ไม่สามารถบันทึกรายวิชาเทียบเท่าได้")
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"message": "เพิ่มรายวิชาเทียบเท่าสำเร็จ", "data": row})
+}
+
+// DELETE /all-courses/:id/equivalents/:equivalentID
+func DeleteCourseEquivalence(c *gin.Context) {
+	id, other := c.Param("id"), c.Param("equivalentID")
+	tx := config.DB().Unscoped().
+		Where("(all_courses_id = ? AND equivalent_id = ?) OR (all_courses_id = ? AND equivalent_id = ?)", id, other, other, id).
+		Delete(&entity.CourseEquivalence{})
+	if tx.Error != nil || tx.RowsAffected == 0 {
+		respondError(c, http.StatusNotFound, "ไม่พบรายวิชาเทียบเท่า")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "ลบรายวิชาเทียบเท่าสำเร็จ"})
+}
+
+// PUT /offered-courses/:id/aliases ให้วิชาที่เปิดสอนรับผู้เรียนของรายวิชาเทียบเท่าในกลุ่มเรียนเดียวกัน
+func UpdateOfferedCourseAliases(c *gin.Context) {
+	var oc entity.OfferedCourses
+	if err := config.DB().Preload("AllCourses").First(&oc, c.Param("id")).Error; err != nil {
+		respondError(c, http.StatusNotFound, "ไม่พบรายวิชาที่เปิดสอน")
+		return
+	}
+
+	var input OfferedCourseAliasesInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	eq, err := config.LoadEquivalence()
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "ไม่สามารถดึงรายวิชาเทียบเท่าได้")
+		return
+	}
+
+	seen := map[uint]bool{}
+	aliases := []entity.OfferedCourseAlias{}
+	for _, id := range input.AllCoursesIDs {
+		if id == oc.AllCoursesID || seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		var ac entity.AllCourses
+		if err := config.DB().First(&ac, id).Error; err != nil {
+			respondError(c, http.StatusBadRequest, fmt.Sprintf("ไม่พบรายวิชา ID %d", id))
+			return
+		}
+		if !eq.Equivalent(oc.AllCoursesID, ac.ID) {
+			respondError(c, http.StatusBadRequest, fmt.Sprintf("%s ไม่ได้เทียบเท่ากับ %s", ac.Code, oc.AllCourses.Code))
+			return
+		}
+		owner, err := servingOffering(ac.ID, oc.Year, oc.Term, oc.ID)
+		if err != nil {
+			respondError(c, http.StatusInternalServerError, "ไม่สามารถตรวจสอบรายวิชาที่เปิดสอนได้")
+			return
+		}
+		if owner != nil {
+			respondError(c, http.StatusConflict, fmt.Sprintf("%s เปิดสอนในเทอมนี้แล้วกับวิชา %s", ac.Code, owner.AllCourses.Code))
+			return
+		}
+		aliases = append(aliases, entity.OfferedCourseAlias{OfferedCoursesID: oc.ID, AllCoursesID: ac.ID})
+	}
+	if !validateEntity(c, aliases) {
+		return
+	}
+
+	if err := config.DB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("offered_courses_id = ?", oc.ID).Delete(&entity.OfferedCourseAlias{}).Error; err != nil {
+			return err
+		}
+		for i := range aliases {
+			if err := tx.Create(&aliases[i]).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		respondError(c, http.StatusInternalServerError, "ไม่สามารถบันทึกรายวิชาเทียบเท่าของวิชาที่เปิดสอนได้")
+		return
+	}
+
+	config.DB().Preload("AllCourses").Preload("Aliases.AllCourses").First(&oc, oc.ID)
+	c.JSON(http.StatusOK, gin.H{"message": "บันทึกรายวิชาเทียบเท่าสำเร็จ", "codes": offeredCodes(oc)})
+}
+
+// rejectAliasedCourse ไม่ให้เปิดสอนรายวิชาที่เรียนรวมเป็นวิชาเทียบเท่าในวิชาอื่นของเทอมเดียวกันแล้ว
+func rejectAliasedCourse(c *gin.Context, allCoursesID, year, term, exceptID uint) bool {
+	var owner entity.OfferedCourses
+	err := config.DB().Preload("AllCourses").
+		Joins("JOIN offered_course_aliases a ON a.offered_courses_id = offered_courses.id AND a.deleted_at IS NULL").
+		Where("a.all_courses_id = ? AND offered_courses.year = ? AND offered_courses.term = ? AND offered_courses.id <> ?", allCoursesID, year, term, exceptID).
+		First(&owner).Error
+	if err == gorm.ErrRecordNotFound {
+		return false
+	}
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "ไม่สามารถตรวจสอบรายวิชาเทียบเท่าได้")
+		return true
+	}
+	respondError(c, http.StatusConflict, fmt.Sprintf("รายวิชานี้เรียนรวมกับวิชา %s ในเทอมนี้แล้ว", owner.AllCourses.Code))
+	return true
+}
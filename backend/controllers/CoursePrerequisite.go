@@ -0,0 +1,261 @@
+package controllers
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/Nichakorn25/CPE-Teaching-Schedule/config"
+	"github.com/Nichakorn25/CPE-Teaching-Schedule/entity"
+)
+
+type (
+	RequisiteInput struct {
+		RequiresID uint   `json:"requires_id" binding:"required"`
+		Kind       string `json:"kind"` // prerequisite (ค่าเริ่มต้น) | corequisite
+	}
+
+	RequisiteResp struct {
+		ID          uint
+		Kind        string
+		CourseID    uint
+		Code        string
+		ThaiName    string
+		EnglishName string
+	}
+
+	GraphCourse struct {
+		ID           uint
+		Code         string
+		ThaiName     string
+		EnglishName  string
+		Credit       uint
+		CurriculumID uint
+	}
+
+	GraphYear struct {
+		AcademicYearID *uint
+		Level          string
+		Courses        []GraphCourse
+	}
+
+	GraphEdge struct {
+		From uint // วิชาที่มีเงื่อนไข
+		To   uint // วิชาที่ต้องเรียนก่อน/เรียนร่วม
+		Kind string
+	}
+)
+
+func requisiteResp(id uint, kind string, ac entity.AllCourses) RequisiteResp {
+	return RequisiteResp{
+		ID:          id,
+		Kind:        kind,
+		CourseID:    ac.ID,
+		Code:        ac.Code,
+		ThaiName:    ac.ThaiName,
+		EnglishName: ac.EnglishName,
+	}
+}
+
+func graphCourse(ac entity.AllCourses) GraphCourse {
+	return GraphCourse{
+		ID:           ac.ID,
+		Code:         ac.Code,
+		ThaiName:     ac.ThaiName,
+		EnglishName:  ac.EnglishName,
+		Credit:       ac.Credit.Unit,
+		CurriculumID: ac.CurriculumID,
+	}
+}
+
+// GET /all-courses/:id/prerequisites
+func GetCourseRequisites(c *gin.Context) {
+	var course entity.AllCourses
+	if err := config.DB().First(&course, c.Param("id")).Error; err != nil {
+		respondError(c, http.StatusNotFound, "ไม่พบรายวิชา")
+		return
+	}
+
+	var requires []entity.CoursePrerequisite
+	if err := config.DB().Preload("Requires").Where("all_courses_id = ?", course.ID).Order("id").Find(&requires).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, "ไม่สามารถดึงวิชาบังคับก่อนได้")
+		return
+	}
+	var requiredBy []entity.CoursePrerequisite
+	if err := config.DB().Preload("AllCourses").Where("requires_id = ?", course.ID).Order("id").Find(&requiredBy).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, "ไม่สามารถดึงวิชาบังคับก่อนได้")
+		return
+	}
+
+	prereqs, coreqs, next := []RequisiteResp{}, []RequisiteResp{}, []RequisiteResp{}
+	for _, r := range requires {
+		if r.Kind == entity.RequisiteCo {
+			coreqs = append(coreqs, requisiteResp(r.ID, r.Kind, r.Requires))
+		} else {
+			prereqs = append(prereqs, requisiteResp(r.ID, r.Kind, r.Requires))
+		}
+	}
+	for _, r := range requiredBy {
+		next = append(next, requisiteResp(r.ID, r.Kind, r.AllCourses))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"courseId":      course.ID,
+		"prerequisites": prereqs,
+		"corequisites":  coreqs,
+		"requiredBy":    next,
+	})
+}
+
+// POST /all-courses/:id/prerequisites
+func CreateCourseRequisite(c *gin.Context) {
+	var course entity.AllCourses
+	if err := config.DB().First(&course, c.Param("id")).Error; err != nil {
+		respondError(c, http.StatusNotFound, "ไม่พบรายวิชา")
+		return
+	}
+
+	var input RequisiteInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		respondBindError(c, err)
+		return
+	}
+	if input.Kind == "" {
+		input.Kind = entity.RequisitePre
+	}
+	if input.Kind != entity.RequisitePre && input.Kind != entity.RequisiteCo {
+		respondError(c, http.StatusBadRequest, "kind ต้องเป็น prerequisite หรือ corequisite")
+		return
+	}
+
+	var requires entity.AllCourses
+	if err := config.DB().First(&requires, input.RequiresID).Error; err != nil {
+		respondError(c, http.StatusBadRequest, "ไม่พบรายวิชาที่ต้องเรียนก่อน")
+		return
+	}
+
+	row := entity.CoursePrerequisite{Kind: input.Kind, AllCoursesID: course.ID, RequiresID: requires.ID}
+	if !validateEntity(c, row) {
+		return
+	}
+	var cycle []uint
+	if err := config.DB().Transaction(func(tx *gorm.DB) error {
+		var rows []entity.CoursePrerequisite
+		if err := tx.Find(&rows).Error; err != nil {
+			return err
+		}
+		if cycle = config.NewRequisiteGraph(rows).CyclePath(course.ID, requires.ID, input.Kind); cycle != nil {
+			return nil
+		}
+		return tx.Create(&row).Error
+	}); config.IsDuplicateKey(err) {
+		respondError(c, http.StatusConflict, "มีความสัมพันธ์ระหว่างสองวิชานี้อยู่แล้ว")
+		return
+	} else if err != nil {
+		respondServerError(c, http.StatusInternalServerError, "ไม่สามารถบันทึกวิชาบังคับก่อนได้", err)
+		return
+	}
+
+	if cycle != nil {
+		var courses []entity.AllCourses
+		config.DB().Where("id IN ?", cycle).Find(&courses)
+		codes := make(map[uint]string, len(courses))
+		for _, ac := range courses {
+			codes[ac.ID] = ac.Code
+		}
+		path := make([]string, 0, len(cycle))
+		for _, id := range cycle {
+			path = append(path, codes[id])
+		}
+		respondError(c, http.StatusBadRequest, "วิชาบังคับก่อนวนกันเป็นวงกลม: " + strings.Join(path, " → "), gin.H{
+			"cycle": cycle,
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "เพิ่มวิชาบังคับก่อนสำเร็จ", "data": row})
+}
+
+// DELETE /all-courses/:id/prerequisites/:requiresID
+func DeleteCourseRequisite(c *gin.Context) {
+	tx := config.DB().Unscoped().
+		Where("all_courses_id = ? AND requires_id = ?", c.Param("id"), c.Param("requiresID")).
+		Delete(&entity.CoursePrerequisite{})
+	if tx.Error != nil || tx.RowsAffected == 0 {
+		respondError(c, http.StatusNotFound, "ไม่พบวิชาบังคับก่อน")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "ลบวิชาบังคับก่อนสำเร็จ"})
+}
+
+// GET /curriculum/:id/graph รายวิชาของหลักสูตรแยกตามชั้นปี พร้อมเส้นความสัมพันธ์วิชาบังคับก่อน/เรียนร่วม
+func GetCurriculumGraph(c *gin.Context) {
+	var cur entity.Curriculum
+	if err := config.DB().First(&cur, c.Param("id")).Error; err != nil {
+		respondError(c, http.StatusNotFound, "ไม่พบหลักสูตรที่ต้องการ")
+		return
+	}
+
+	var courses []entity.AllCourses
+	if err := config.DB().
+		Preload("AcademicYear").
+		Preload("Credit").
+		Preload("Requisites.Requires.Credit").
+		Where("curriculum_id = ?", cur.ID).
+		Order("code").
+		Find(&courses).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, "ไม่สามารถดึงรายวิชาของหลักสูตรได้")
+		return
+	}
+
+	inCurriculum := make(map[uint]bool, len(courses))
+	for _, ac := range courses {
+		inCurriculum[ac.ID] = true
+	}
+
+	yearIndex := map[uint]int{}
+	years := []GraphYear{}
+	unassigned := GraphYear{Level: "ไม่ระบุชั้นปี", Courses: []GraphCourse{}}
+	edges := []GraphEdge{}
+	external := []GraphCourse{}
+	externalSeen := map[uint]bool{}
+
+	for _, ac := range courses {
+		if ac.AcademicYearID == nil {
+			unassigned.Courses = append(unassigned.Courses, graphCourse(ac))
+		} else {
+			i, ok := yearIndex[*ac.AcademicYearID]
+			if !ok {
+				i = len(years)
+				yearIndex[*ac.AcademicYearID] = i
+				years = append(years, GraphYear{AcademicYearID: ac.AcademicYearID, Level: ac.AcademicYear.Level, Courses: []GraphCourse{}})
+			}
+			years[i].Courses = append(years[i].Courses, graphCourse(ac))
+		}
+
+		for _, r := range ac.Requisites {
+			edges = append(edges, GraphEdge{From: ac.ID, To: r.RequiresID, Kind: r.Kind})
+			// วิชาที่ต้องเรียนก่อนจากหลักสูตรอื่น (เช่น วิชาศึกษาทั่วไป)
+			if !inCurriculum[r.RequiresID] && !externalSeen[r.RequiresID] {
+				externalSeen[r.RequiresID] = true
+				external = append(external, graphCourse(r.Requires))
+			}
+		}
+	}
+
+	sort.SliceStable(years, func(i, j int) bool { return *years[i].AcademicYearID < *years[j].AcademicYearID })
+	if len(unassigned.Courses) > 0 {
+		years = append(years, unassigned)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"curriculumId":   cur.ID,
+		"curriculumName": cur.CurriculumName,
+		"years":          years,
+		"edges":          edges,
+		"external":       external,
+	})
+}
@@ -0,0 +1,152 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Nichakorn25/CPE-Teaching-Schedule/config"
+	"github.com/Nichakorn25/CPE-Teaching-Schedule/entity"
+)
+
+type creditHourRuleInput struct {
+	TypeOfCoursesID  *uint `json:"typeOfCoursesId"`
+	AllCoursesID     *uint `json:"allCoursesId"`
+	LecturePerCredit *uint `json:"lecturePerCredit"`
+	LabPerCredit     *uint `json:"labPerCredit"`
+	LectureHours     *uint `json:"lectureHours"`
+	LabHours         *uint `json:"labHours"`
+	MaxLectureBlock  *uint `json:"maxLectureBlock"`
+	MaxLabBlock      *uint `json:"maxLabBlock"`
+}
+
+// apply ตรวจว่าผูกกับประเภทรายวิชาหรือรายวิชาอย่างใดอย่างหนึ่ง แล้วคัดลอกค่าลง rule
+func (in creditHourRuleInput) apply(rule *entity.CreditHourRule) string {
+	if (in.TypeOfCoursesID == nil) == (in.AllCoursesID == nil) {
+		return "ต้องระบุ typeOfCoursesId หรือ allCoursesId อย่างใดอย่างหนึ่ง"
+	}
+	if in.TypeOfCoursesID != nil {
+		if err := config.DB().First(&entity.TypeOfCourses{}, *in.TypeOfCoursesID).Error; err != nil {
+			return "ไม่พบประเภทรายวิชา"
+		}
+	}
+	if in.AllCoursesID != nil {
+		if err := config.DB().First(&entity.AllCourses{}, *in.AllCoursesID).Error; err != nil {
+			return "ไม่พบรายวิชา"
+		}
+	}
+	if in.MaxLectureBlock != nil && *in.MaxLectureBlock == 0 {
+		return "maxLectureBlock ต้องมากกว่า 0"
+	}
+
+	rule.TypeOfCoursesID = in.TypeOfCoursesID
+	rule.AllCoursesID = in.AllCoursesID
+	rule.LecturePerCredit = in.LecturePerCredit
+	rule.LabPerCredit = in.LabPerCredit
+	rule.LectureHours = in.LectureHours
+	rule.LabHours = in.LabHours
+	rule.MaxLectureBlock = in.MaxLectureBlock
+	rule.MaxLabBlock = in.MaxLabBlock
+	return ""
+}
+
+// GET /credit-hour-rules
+func GetCreditHourRules(c *gin.Context) {
+	var rules []entity.CreditHourRule
+	if err := config.DB().
+		Preload("TypeOfCourses").
+		Preload("AllCourses").
+		Order("id").
+		Find(&rules).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, "ไม่สามารถดึงกติกาชั่วโมงสอนได้")
+		return
+	}
+	c.JSON(http.StatusOK, rules)
+}
+
+// GET /all-courses/:id/hours ชั่วโมงต่อสัปดาห์ของรายวิชาตามกติกาที่ใช้จริง
+func GetCourseHours(c *gin.Context) {
+	var ac entity.AllCourses
+	if err := config.DB().Preload("Credit").First(&ac, c.Param("id")).Error; err != nil {
+		respondError(c, http.StatusNotFound, "ไม่พบรายวิชา")
+		return
+	}
+
+	rules, err := config.LoadHoursRules()
+	if err != nil {
+		respondServerError(c, http.StatusInternalServerError, "โหลดกติกาชั่วโมงสอนไม่สำเร็จ", err)
+		return
+	}
+	hours := rules.For(ac)
+
+	c.JSON(http.StatusOK, gin.H{
+		"allCoursesId":    ac.ID,
+		"lectureHours":    hours.Lecture,
+		"labHours":        hours.Lab,
+		"maxLectureBlock": hours.MaxLectureBlock,
+		"maxLabBlock":     hours.MaxLabBlock,
+		"labBlocks":       hours.LabBlocks(),
+	})
+}
+
+// POST /credit-hour-rules
+func CreateCreditHourRule(c *gin.Context) {
+	var input creditHourRuleInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	var rule entity.CreditHourRule
+	if msg := input.apply(&rule); msg != "" {
+		respondError(c, http.StatusBadRequest, msg)
+		return
+	}
+
+	if !validateEntity(c, rule) {
+		return
+	}
+	if err := config.DB().Create(&rule).Error; err != nil {
+		respondError(c, http.StatusConflict, "มีกติกาของประเภทรายวิชา/รายวิชานี้อยู่แล้ว")
+		return
+	}
+	c.JSON(http.StatusCreated, rule)
+}
+
+// PUT /credit-hour-rules/:id
+func UpdateCreditHourRule(c *gin.Context) {
+	var rule entity.CreditHourRule
+	if err := config.DB().First(&rule, c.Param("id")).Error; err != nil {
+		respondError(c, http.StatusNotFound, "ไม่พบกติกาชั่วโมงสอน")
+		return
+	}
+
+	var input creditHourRuleInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		respondBindError(c, err)
+		return
+	}
+	if msg := input.apply(&rule); msg != "" {
+		respondError(c, http.StatusBadRequest, msg)
+		return
+	}
+
+	if !validateEntity(c, rule) {
+		return
+	}
+	if err := config.DB().Save(&rule).Error; err != nil {
+		respondError(c, http.StatusConflict, "มีกติกาของประเภทรายวิชา/รายวิชานี้อยู่แล้ว")
+		return
+	}
+	c.JSON(http.StatusOK, rule)
+}
+
+// DELETE /credit-hour-rules/:id
+func DeleteCreditHourRule(c *gin.Context) {
+	// ลบถาวรเพื่อให้สร้างกติกาใหม่ของประเภท/รายวิชาเดิมได้ (uniqueIndex)
+	if tx := config.DB().Unscoped().Delete(&entity.CreditHourRule{}, c.Param("id")); tx.Error != nil || tx.RowsAffected == 0 {
+		respondError(c, http.StatusNotFound, "ไม่พบกติกาชั่วโมงสอน")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "ลบกติกาชั่วโมงสอนสำเร็จ"})
+}
@@ -3,7 +3,6 @@ package controllers
 import (
 	"fmt"
 	"net/http"
-	"strings"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -30,9 +29,9 @@ func GetCurriculumById(c *gin.Context) {
 		Preload("Major.Department").
 		First(&cur, id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "ไม่พบหลักสูตรที่ต้องการ"})
+			respondError(c, http.StatusNotFound, "ไม่พบหลักสูตรที่ต้องการ")
 		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "ไม่สามารถโหลดข้อมูลหลักสูตรได้"})
+			respondError(c, http.StatusInternalServerError, "ไม่สามารถโหลดข้อมูลหลักสูตรได้")
 		}
 		return
 	}
@@ -67,13 +66,13 @@ type createCurriculumInput struct {
 func CreateCurriculum(c *gin.Context) {
     var in createCurriculumInput
     if err := c.ShouldBindJSON(&in); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "ข้อมูลไม่ถูกต้อง"})
+        respondError(c, http.StatusBadRequest, "ข้อมูลไม่ถูกต้อง")
         return
     }
 
     var major entity.Major
     if err := config.DB().First(&major, in.MajorID).Error; err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "ไม่พบสาขา (Major) ที่ระบุ"})
+        respondError(c, http.StatusBadRequest, "ไม่พบสาขา (Major) ที่ระบุ")
         return
     }
 
@@ -84,8 +83,11 @@ func CreateCurriculum(c *gin.Context) {
         MajorID:        in.MajorID,
     }
 
+    if !validateEntity(c, cur) {
+        return
+    }
     if err := config.DB().Create(&cur).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "ไม่สามารถสร้างหลักสูตรได้"})
+        respondError(c, http.StatusInternalServerError, "ไม่สามารถสร้างหลักสูตรได้")
         return
     }
 
@@ -116,20 +118,20 @@ func UpdateCurriculum(c *gin.Context) {
 
 	var cur entity.Curriculum
 	if err := config.DB().First(&cur, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "ไม่พบหลักสูตรที่ต้องการแก้ไข"})
+		respondError(c, http.StatusNotFound, "ไม่พบหลักสูตรที่ต้องการแก้ไข")
 		return
 	}
 
 	var in updateCurriculumInput
 	if err := c.ShouldBindJSON(&in); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "ข้อมูลไม่ถูกต้อง"})
+		respondError(c, http.StatusBadRequest, "ข้อมูลไม่ถูกต้อง")
 		return
 	}
 
 	if in.MajorID != nil {
 		var major entity.Major
 		if err := config.DB().First(&major, *in.MajorID).Error; err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "ไม่พบสาขา (Major) ที่ระบุ"})
+			respondError(c, http.StatusBadRequest, "ไม่พบสาขา (Major) ที่ระบุ")
 			return
 		}
 	}
@@ -147,8 +149,11 @@ func UpdateCurriculum(c *gin.Context) {
 		cur.MajorID = *in.MajorID
 	}
 
+	if !validateEntity(c, cur) {
+		return
+	}
 	if err := config.DB().Save(&cur).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "ไม่สามารถแก้ไขหลักสูตรได้"})
+		respondError(c, http.StatusInternalServerError, "ไม่สามารถแก้ไขหลักสูตรได้")
 		return
 	}
 
@@ -166,64 +171,102 @@ func UpdateCurriculum(c *gin.Context) {
 }
 
 
-func DuplicateAllCoursesIntoCurriculum(c *gin.Context) {
-	targetCurID := c.Param("id")
+type cloneCoursesInput struct {
+	SourceCurriculumID uint   `json:"sourceCurriculumId" binding:"required"`
+	CourseIDs          []uint `json:"courseIds"` // ว่าง = ทุกวิชาของหลักสูตรต้นทาง
+}
 
+// POST /curriculum-into-allcourse/:id คัดลอกรายวิชาจากหลักสูตรต้นทางเข้าหลักสูตรปลายทาง (:id)
+// รหัสวิชาคงเดิม พร้อมหน่วยกิต ประเภทวิชา ผู้สอน และวิชาบังคับก่อน/เรียนร่วม
+// วิชาที่มีรหัสเดียวกันอยู่ในหลักสูตรปลายทางแล้วจะข้าม แต่ยังใช้เป็นปลายทางของวิชาบังคับก่อนได้
+func DuplicateAllCoursesIntoCurriculum(c *gin.Context) {
 	var targetCur entity.Curriculum
-	if err := config.DB().First(&targetCur, targetCurID).Error; err != nil {
-    	c.JSON(http.StatusBadRequest, gin.H{"error": "ไม่พบหลักสูตรปลายทาง"})
-    	return
+	if err := config.DB().First(&targetCur, c.Param("id")).Error; err != nil {
+		respondError(c, http.StatusBadRequest, "ไม่พบหลักสูตรปลายทาง")
+		return
+	}
+
+	var in cloneCoursesInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		respondError(c, http.StatusBadRequest, "ต้องระบุหลักสูตรต้นทาง (sourceCurriculumId)")
+		return
+	}
+	if in.SourceCurriculumID == targetCur.ID {
+		respondError(c, http.StatusBadRequest, "หลักสูตรต้นทางและปลายทางต้องไม่ใช่หลักสูตรเดียวกัน")
+		return
+	}
+	var sourceCur entity.Curriculum
+	if err := config.DB().First(&sourceCur, in.SourceCurriculumID).Error; err != nil {
+		respondError(c, http.StatusBadRequest, "ไม่พบหลักสูตรต้นทาง")
+		return
 	}
 
-	// ดึงวิชาต้นทางทั้งหมด (ยกเว้นของหลักสูตรปลายทาง)
 	var sourceCourses []entity.AllCourses
 	if err := config.DB().
-		Where("curriculum_id <> ?", targetCurID).
+		Preload("UserAllCourses").
+		Preload("Requisites").
+		Where("curriculum_id = ?", sourceCur.ID).
+		Order("code").
 		Find(&sourceCourses).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "ไม่สามารถดึงรายวิชาต้นทางได้"})
+		respondError(c, http.StatusInternalServerError, "ไม่สามารถดึงรายวิชาต้นทางได้")
 		return
 	}
 
-	// ดึงวิชาของหลักสูตรปลายทางมาสร้างดัชนีป้องกันซ้ำแบบ normalize(Code)
+	inSource := make(map[uint]bool, len(sourceCourses))
+	for _, ac := range sourceCourses {
+		inSource[ac.ID] = true
+	}
+	selected := sourceCourses
+	if len(in.CourseIDs) > 0 {
+		want := make(map[uint]bool, len(in.CourseIDs))
+		for _, id := range in.CourseIDs {
+			if !inSource[id] {
+				respondError(c, http.StatusBadRequest, fmt.Sprintf("รายวิชา ID %d ไม่ได้อยู่ในหลักสูตรต้นทาง", id))
+				return
+			}
+			want[id] = true
+		}
+		selected = selected[:0:0]
+		for _, ac := range sourceCourses {
+			if want[ac.ID] {
+				selected = append(selected, ac)
+			}
+		}
+	}
+
+	// รายวิชาที่มีอยู่แล้วในหลักสูตรปลายทาง (รหัสวิชาไม่ซ้ำภายในหลักสูตร)
 	var targetCourses []entity.AllCourses
-	if err := config.DB().
-		Where("curriculum_id = ?", targetCurID).
-		Find(&targetCourses).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "ไม่สามารถดึงรายวิชาปลายทางได้"})
+	if err := config.DB().Where("curriculum_id = ?", targetCur.ID).Find(&targetCourses).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, "ไม่สามารถดึงรายวิชาปลายทางได้")
 		return
 	}
-
-	// เซ็ตเก็บ normalize(code) ที่มีอยู่แล้วในหลักสูตรเป้าหมาย
-	existsNormalized := map[string]bool{}
+	targetByCode := make(map[string]uint, len(targetCourses))
 	for _, ac := range targetCourses {
-		existsNormalized[normalizeCode(ac.Code)] = true
+		targetByCode[ac.Code] = ac.ID
 	}
 
-	created := make([]entity.AllCourses, 0, len(sourceCourses))
+	// idMap วิชาต้นทาง -> วิชาปลายทาง (ทั้งที่สร้างใหม่และที่มีอยู่แล้ว) ใช้แปลงวิชาบังคับก่อน
+	idMap := make(map[uint]uint, len(sourceCourses))
+	for _, ac := range sourceCourses {
+		if id, ok := targetByCode[ac.Code]; ok {
+			idMap[ac.ID] = id
+		}
+	}
 
-	err := config.DB().Transaction(func(tx *gorm.DB) error {
-		for _, src := range sourceCourses {
-			norm := normalizeCode(src.Code)
+	created := make([]entity.AllCourses, 0, len(selected))
+	skipped := []string{}
+	warnings := []string{}
 
-			// ถ้าเจอ (normalize(Code), target curriculum) อยู่แล้ว ให้ข้าม
-			if existsNormalized[norm] {
+	err := config.DB().Transaction(func(tx *gorm.DB) error {
+		copied := make(map[uint]uint, len(selected))
+		for _, src := range selected {
+			if _, ok := targetByCode[src.Code]; ok {
+				skipped = append(skipped, src.Code)
 				continue
 			}
 
-			// สร้าง code ใหม่ให้ไม่ชน unique ของคอลัมน์ code (ทั้งตาราง)
-			base := norm
-			candidate := base
-			var cnt int64
-			tx.Model(&entity.AllCourses{}).Where("code = ?", candidate).Count(&cnt)
-			i := 0
-			for cnt > 0 {
-				i++
-				candidate = base + strings.Repeat("*", i)
-				tx.Model(&entity.AllCourses{}).Where("code = ?", candidate).Count(&cnt)
-			}
-
 			dup := entity.AllCourses{
-				Code:            candidate,
+				Code:            src.Code,
 				EnglishName:     src.EnglishName,
 				ThaiName:        src.ThaiName,
 				Ismain:          src.Ismain,
@@ -232,31 +275,69 @@ func DuplicateAllCoursesIntoCurriculum(c *gin.Context) {
 				TypeOfCoursesID: src.TypeOfCoursesID,
 				CreditID:        src.CreditID,
 			}
-
 			if err := tx.Create(&dup).Error; err != nil {
 				return err
 			}
+			for _, uac := range src.UserAllCourses {
+				if err := tx.Create(&entity.UserAllCourses{UserID: uac.UserID, AllCoursesID: dup.ID}).Error; err != nil {
+					return err
+				}
+			}
 
-			// อัปเดตดัชนีว่า normalize(code) นี้ถูกใช้ในหลักสูตรเป้าหมายแล้ว
-			existsNormalized[norm] = true
+			targetByCode[dup.Code] = dup.ID
+			idMap[src.ID] = dup.ID
+			copied[src.ID] = dup.ID
 			created = append(created, dup)
 		}
+
+		// วิชาบังคับก่อนของวิชาที่คัดลอกใหม่เท่านั้น
+		var rows []entity.CoursePrerequisite
+		for _, src := range selected {
+			if _, ok := copied[src.ID]; ok {
+				rows = append(rows, src.Requisites...)
+			}
+		}
+		mapped, dropped := config.RemapRequisites(rows, idMap, inSource)
+		codes := make(map[uint]string, len(sourceCourses))
+		for _, ac := range sourceCourses {
+			codes[ac.ID] = ac.Code
+		}
+		for _, r := range dropped {
+			warnings = append(warnings, fmt.Sprintf("%s: ไม่ได้คัดลอกวิชาบังคับก่อน %s เพราะไม่มีในหลักสูตรปลายทาง", codes[r.AllCoursesID], codes[r.RequiresID]))
+		}
+
+		var existing []entity.CoursePrerequisite
+		if err := tx.Find(&existing).Error; err != nil {
+			return err
+		}
+		targetCodes := make(map[uint]string, len(targetByCode))
+		for code, id := range targetByCode {
+			targetCodes[id] = code
+		}
+		graph := config.NewRequisiteGraph(existing)
+		for _, r := range mapped {
+			if graph.CyclePath(r.AllCoursesID, r.RequiresID, r.Kind) != nil {
+				warnings = append(warnings, fmt.Sprintf("%s: ไม่ได้คัดลอกวิชาบังคับก่อนเพราะทำให้เกิดวงวน", targetCodes[r.AllCoursesID]))
+				continue
+			}
+			row := r
+			if err := tx.Create(&row).Error; err != nil {
+				return err
+			}
+			graph.Add(row.AllCoursesID, row.RequiresID, row.Kind)
+		}
 		return nil
 	})
 
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "คัดลอกรายวิชาไม่สำเร็จ"})
+		respondError(c, http.StatusInternalServerError, "คัดลอกรายวิชาไม่สำเร็จ")
 		return
 	}
 
 	c.JSON(http.StatusCreated, gin.H{
-		"message": fmt.Sprintf("คัดลอกรายวิชาสำเร็จ %d ตัว", len(created)),
-		"data":    created,
+		"message":  fmt.Sprintf("คัดลอกรายวิชาสำเร็จ %d ตัว", len(created)),
+		"data":     created,
+		"skipped":  skipped,
+		"warnings": warnings,
 	})
 }
-
-// normalizeCode ลบ "*" ทั้งหมดออกจาก code
-func normalizeCode(s string) string {
-	return strings.ReplaceAll(s, "*", "")
-}
-
@@ -0,0 +1,180 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Nichakorn25/CPE-Teaching-Schedule/config"
+	"github.com/Nichakorn25/CPE-Teaching-Schedule/entity"
+)
+
+type CourseMappingInput struct {
+	OldCourseID uint   `json:"old_course_id" binding:"required"`
+	NewCourseID uint   `json:"new_course_id" binding:"required"`
+	Note        string `json:"note"`
+}
+
+func loadCurriculumCourses(curriculumID uint) ([]entity.AllCourses, error) {
+	var courses []entity.AllCourses
+	err := config.DB().
+		Preload("Credit").
+		Preload("TypeOfCourses").
+		Preload("AcademicYear").
+		Where("curriculum_id = ?", curriculumID).
+		Order("code").
+		Find(&courses).Error
+	return courses, err
+}
+
+// curriculumPair หลักสูตรเก่า (from) และหลักสูตรใหม่ (to) จาก query string
+func curriculumPair(c *gin.Context) (from, to entity.Curriculum, ok bool) {
+	if c.Query("from") == "" || c.Query("to") == "" {
+		respondError(c, http.StatusBadRequest, "ต้องระบุ from และ to")
+		return from, to, false
+	}
+	if err := config.DB().First(&from, c.Query("from")).Error; err != nil {
+		respondError(c, http.StatusNotFound, "ไม่พบหลักสูตรเดิม")
+		return from, to, false
+	}
+	if err := config.DB().First(&to, c.Query("to")).Error; err != nil {
+		respondError(c, http.StatusNotFound, "ไม่พบหลักสูตรฉบับปรับปรุง")
+		return from, to, false
+	}
+	if from.ID == to.ID {
+		respondError(c, http.StatusBadRequest, "ต้องเป็นหลักสูตรคนละฉบับ")
+		return from, to, false
+	}
+	return from, to, true
+}
+
+// mappingsBetween การจับคู่รายวิชาจากหลักสูตร from ไปยังหลักสูตร to
+func mappingsBetween(fromID, toID uint) ([]entity.CourseMapping, error) {
+	var rows []entity.CourseMapping
+	err := config.DB().
+		Preload("OldCourse").
+		Preload("NewCourse").
+		Joins("JOIN all_courses o ON o.id = course_mappings.old_course_id").
+		Joins("JOIN all_courses n ON n.id = course_mappings.new_course_id").
+		Where("o.curriculum_id = ? AND n.curriculum_id = ?", fromID, toID).
+		Order("course_mappings.id").
+		Find(&rows).Error
+	return rows, err
+}
+
+// GET /curriculum-compare?from=1&to=2 เทียบรายวิชาที่เพิ่ม ลบ และเปลี่ยนแปลงระหว่างหลักสูตรสองฉบับ
+func CompareCurricula(c *gin.Context) {
+	from, to, ok := curriculumPair(c)
+	if !ok {
+		return
+	}
+
+	oldCourses, err := loadCurriculumCourses(from.ID)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "ไม่สามารถดึงรายวิชาของหลักสูตรเดิมได้")
+		return
+	}
+	newCourses, err := loadCurriculumCourses(to.ID)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "ไม่สามารถดึงรายวิชาของหลักสูตรฉบับปรับปรุงได้")
+		return
+	}
+	rows, err := mappingsBetween(from.ID, to.ID)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "ไม่สามารถดึงตารางจับคู่รายวิชาได้")
+		return
+	}
+	mapping := make(map[uint]uint, len(rows))
+	for _, m := range rows {
+		mapping[m.OldCourseID] = m.NewCourseID
+	}
+
+	diff := config.CompareCurricula(oldCourses, newCourses, mapping)
+	c.JSON(http.StatusOK, gin.H{
+		"from": gin.H{"id": from.ID, "curriculumName": from.CurriculumName, "year": from.Year, "started": from.Started},
+		"to":   gin.H{"id": to.ID, "curriculumName": to.CurriculumName, "year": to.Year, "started": to.Started},
+		"summary": gin.H{
+			"added":     len(diff.Added),
+			"removed":   len(diff.Removed),
+			"changed":   len(diff.Changed),
+			"unchanged": len(diff.Unchanged),
+		},
+		"added":     diff.Added,
+		"removed":   diff.Removed,
+		"changed":   diff.Changed,
+		"unchanged": diff.Unchanged,
+	})
+}
+
+// GET /course-mappings?from=1&to=2
+func GetCourseMappings(c *gin.Context) {
+	from, to, ok := curriculumPair(c)
+	if !ok {
+		return
+	}
+	rows, err := mappingsBetween(from.ID, to.ID)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "ไม่สามารถดึงตารางจับคู่รายวิชาได้")
+		return
+	}
+	c.JSON(http.StatusOK, rows)
+}
+
+// POST /course-mappings จับคู่รายวิชาเก่ากับรายวิชาในหลักสูตรฉบับปรับปรุง (หนึ่งต่อหนึ่งต่อคู่หลักสูตร)
+func CreateCourseMapping(c *gin.Context) {
+	var input CourseMappingInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	var oldCourse, newCourse entity.AllCourses
+	if err := config.DB().First(&oldCourse, input.OldCourseID).Error; err != nil {
+		respondError(c, http.StatusBadRequest, "ไม่พบรายวิชาเดิม")
+		return
+	}
+	if err := config.DB().First(&newCourse, input.NewCourseID).Error; err != nil {
+		respondError(c, http.StatusBadRequest, "ไม่พบรายวิชาใหม่")
+		return
+	}
+	if oldCourse.CurriculumID == newCourse.CurriculumID {
+		respondError(c, http.StatusBadRequest, "รายวิชาทั้งสองต้องอยู่คนละหลักสูตร")
+		return
+	}
+
+	rows, err := mappingsBetween(oldCourse.CurriculumID, newCourse.CurriculumID)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "ไม่สามารถดึงตารางจับคู่รายวิชาได้")
+		return
+	}
+	for _, m := range rows {
+		if m.OldCourseID == oldCourse.ID {
+			respondError(c, http.StatusConflict, "รายวิชา " + oldCourse.Code + " จับคู่กับ " + m.NewCourse.Code + " อยู่แล้ว")
+			return
+		}
+		if m.NewCourseID == newCourse.ID {
+			respondError(c, http.StatusConflict, "รายวิชา " + newCourse.Code + " ถูกจับคู่กับ " + m.OldCourse.Code + " อยู่แล้ว")
+			return
+		}
+	}
+
+	row := entity.CourseMapping{OldCourseID: oldCourse.ID, NewCourseID: newCourse.ID, Note: input.Note}
+	if !validateEntity(c, row) {
+		return
+	}
+	if err := config.DB().Create(&row).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, "ไม่สามารถบันทึกการจับคู่รายวิชาได้")
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"message": "จับคู่รายวิชาสำเร็จ", "data": row})
+}
+
+// DELETE /course-mappings/:id
+func DeleteCourseMapping(c *gin.Context) {
+	tx := config.DB().Unscoped().Delete(&entity.CourseMapping{}, c.Param("id"))
+	if tx.Error != nil || tx.RowsAffected == 0 {
+		respondError(c, http.StatusNotFound, "ไม่พบการจับคู่รายวิชา")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "ลบการจับคู่รายวิชาสำเร็จ"})
+}
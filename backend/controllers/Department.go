@@ -13,7 +13,7 @@ func GetAllDepartment(c *gin.Context) {
 
 	err := config.DB().Preload("Majors").Find(&departments).Error
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "ไม่สามารถดึงข้อมูลแผนกได้"})
+		respondError(c, http.StatusInternalServerError, "ไม่สามารถดึงข้อมูลแผนกได้")
 		return
 	}
 
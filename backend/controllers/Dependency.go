@@ -0,0 +1,106 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/Nichakorn25/CPE-Teaching-Schedule/config"
+)
+
+// deleteWithDependents ลบข้อมูลชนิด kind แบบ soft delete หลังตรวจแถวที่อ้างถึง
+// ถ้ามีแถวอ้างถึงและไม่ได้ส่ง ?cascade=true จะตอบ 409 พร้อมรายการ dependents
+// คืน false เมื่อได้ตอบข้อผิดพลาดไปแล้ว
+func deleteWithDependents(c *gin.Context, kind string, id uint, failMsg string) (*config.DeletePlan, bool) {
+	cascade := false
+	if raw := c.Query("cascade"); raw != "" {
+		v, err := strconv.ParseBool(raw)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, "ค่า cascade ต้องเป็น true หรือ false")
+			return nil, false
+		}
+		cascade = v
+	}
+
+	var plan *config.DeletePlan
+	err := config.DB().Transaction(func(tx *gorm.DB) error {
+		p, err := config.PlanDelete(tx, kind, id)
+		if err != nil {
+			return err
+		}
+		plan = p
+		if p.Blocked() && !cascade {
+			return nil
+		}
+		return p.Execute(tx)
+	})
+	if err != nil {
+		respondServerError(c, http.StatusInternalServerError, failMsg, err)
+		return nil, false
+	}
+	if plan.Blocked() && !cascade {
+		respondError(c, http.StatusConflict,
+			fmt.Sprintf("ไม่สามารถลบ%sได้ เนื่องจากมีข้อมูลอื่นอ้างอิงอยู่ (ส่ง cascade=true เพื่อลบข้อมูลที่อ้างอิงไปด้วย)", config.DependencyLabel(kind)),
+			gin.H{"dependents": plan.Blockers})
+		return nil, false
+	}
+	return plan, true
+}
+
+// restoreDeleted กู้คืนข้อมูลชนิด kind ที่ถูกลบ พร้อมแถวที่ถูกลบตามไปในคราวเดียวกัน
+// ตอบ 409 ถ้าข้อมูลที่อ้างถึงยังถูกลบอยู่ หรือรหัส/ชื่อที่ไม่ซ้ำถูกข้อมูลใหม่ใช้ไปแล้ว
+func restoreDeleted(c *gin.Context, kind string) {
+	label := config.DependencyLabel(kind)
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "รหัสไม่ถูกต้อง")
+		return
+	}
+
+	var (
+		found, deleted bool
+		parents        []config.DeletedParent
+		restored       []config.Dependent
+	)
+	err = config.DB().Transaction(func(tx *gorm.DB) error {
+		var stamps []*time.Time
+		if err := tx.Unscoped().Model(config.DependencyModel(kind)).Where("id = ?", id).Pluck("deleted_at", &stamps).Error; err != nil {
+			return err
+		}
+		found = len(stamps) > 0
+		deleted = found && stamps[0] != nil
+		if !deleted {
+			return nil
+		}
+
+		var err error
+		if parents, err = config.DeletedParents(tx, kind, uint(id)); err != nil || len(parents) > 0 {
+			return err
+		}
+		restored, err = config.RestoreDeleted(tx, kind, uint(id), *stamps[0])
+		return err
+	})
+
+	switch {
+	case config.IsDuplicateKey(err):
+		respondServerError(c, http.StatusConflict, "ไม่สามารถกู้คืน"+label+"ได้ เนื่องจากมีข้อมูลใหม่ใช้รหัสหรือชื่อเดียวกันแล้ว", err)
+	case err != nil:
+		respondServerError(c, http.StatusInternalServerError, "ไม่สามารถกู้คืน"+label+"ได้", err)
+	case !found:
+		respondError(c, http.StatusNotFound, "ไม่พบ"+label)
+	case !deleted:
+		respondError(c, http.StatusConflict, label+"นี้ยังไม่ถูกลบ")
+	case len(parents) > 0:
+		respondError(c, http.StatusConflict, "ต้องกู้คืนข้อมูลที่"+label+"อ้างถึงก่อน", gin.H{"parents": parents})
+	default:
+		c.JSON(http.StatusOK, gin.H{
+			"message":  "กู้คืน" + label + "สำเร็จ",
+			"id":       id,
+			"restored": restored,
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package controllers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"reflect"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Nichakorn25/CPE-Teaching-Schedule/services"
+)
+
+// respondError ตอบข้อผิดพลาดในรูปแบบกลาง {code, error, fields} ตามภาษาใน Accept-Language
+// message ว่างได้ (ใช้ข้อความมาตรฐานของ status) ส่วน extra คือข้อมูลประกอบ เช่น details หรือรายการที่ชนกัน
+func respondError(c *gin.Context, status int, message string, extra ...gin.H) {
+	respondAPIError(c, services.APIError{Status: status, Message: message}, extra...)
+}
+
+// respondServerError ตอบข้อผิดพลาดด้วยข้อความของ handler และเก็บข้อผิดพลาดดิบ (เช่นจาก Postgres) ไว้ใน log ของเซิร์ฟเวอร์เท่านั้น
+func respondServerError(c *gin.Context, status int, message string, err error, extra ...gin.H) {
+	log.Printf("%s %s: %s: %v", c.Request.Method, c.FullPath(), message, err)
+	respondError(c, status, message, extra...)
+}
+
+// respondBindError ตอบ 400 เมื่อ ShouldBindJSON ไม่ผ่าน บอกฟิลด์ที่ผิดถ้ารู้ ไม่เช่นนั้นตอบข้อความทั่วไป
+func respondBindError(c *gin.Context, err error) {
+	log.Printf("%s %s: bind: %v", c.Request.Method, c.FullPath(), err)
+	if fields := services.BindErrors(err); len(fields) > 0 {
+		respondFieldErrors(c, fields)
+		return
+	}
+	respondError(c, http.StatusBadRequest, "ข้อมูลไม่ถูกต้อง")
+}
+
+func respondAPIError(c *gin.Context, e services.APIError, extra ...gin.H) {
+	body := gin.H(e.Body(services.PreferredLanguage(c.GetHeader("Accept-Language"))))
+	for _, h := range extra {
+		for k, v := range h {
+			if _, taken := body[k]; !taken {
+				body[k] = v
+			}
+		}
+	}
+	c.JSON(e.Status, body)
+}
+
+// validateEntity ตรวจแท็ก valid ของ entity ก่อนสร้าง/แก้ไข ถ้าไม่ผ่านตอบ 400 พร้อมรายการฟิลด์และคืน false
+// ส่ง slice ได้ ฟิลด์จะขึ้นต้นด้วยลำดับแถว เช่น 0.DayOfWeek
+func validateEntity(c *gin.Context, values ...interface{}) bool {
+	fields := []services.FieldError{}
+	for _, v := range values {
+		rv := reflect.ValueOf(v)
+		if rv.Kind() != reflect.Slice {
+			fields = append(fields, services.ValidateEntity(v)...)
+			continue
+		}
+		for i := 0; i < rv.Len(); i++ {
+			for _, f := range services.ValidateEntity(rv.Index(i).Interface()) {
+				f.Field = fmt.Sprintf("%d.%s", i, f.Field)
+				fields = append(fields, f)
+			}
+		}
+	}
+	return respondFieldErrors(c, fields)
+}
+
+// validateChanges ตรวจแท็ก valid ของ after เหมือน validateEntity แต่ข้ามฟิลด์ที่ค่าเท่ากับใน before
+// ใช้ตอนแก้ไขแถวที่บันทึกไว้ก่อนมีกฎ (เช่น บัญชี admin ที่ seed ไว้) เพื่อให้แก้ฟิลด์อื่นได้โดยไม่ต้องแก้ค่าเดิม
+func validateChanges(c *gin.Context, before, after interface{}) bool {
+	old := reflect.Indirect(reflect.ValueOf(before))
+	cur := reflect.Indirect(reflect.ValueOf(after))
+	fields := []services.FieldError{}
+	for _, f := range services.ValidateEntity(after) {
+		o, n := old.FieldByName(f.Field), cur.FieldByName(f.Field)
+		if f.Field != "" && o.IsValid() && n.IsValid() && reflect.DeepEqual(o.Interface(), n.Interface()) {
+			continue
+		}
+		fields = append(fields, f)
+	}
+	return respondFieldErrors(c, fields)
+}
+
+func respondFieldErrors(c *gin.Context, fields []services.FieldError) bool {
+	if len(fields) == 0 {
+		return true
+	}
+	respondAPIError(c, services.APIError{
+		Status: http.StatusBadRequest,
+		Code:   services.CodeValidation,
+		Fields: fields,
+	})
+	return false
+}
@@ -1,10 +1,14 @@
 package controllers
 
 import (
+	"math"
 	"net/http"
+	"sort"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 
 	"github.com/Nichakorn25/CPE-Teaching-Schedule/config"
 	"github.com/Nichakorn25/CPE-Teaching-Schedule/entity"
@@ -21,19 +25,13 @@ func GetLaboratory(c *gin.Context) {
 type createLaboratoryInput struct {
 	Room     string `json:"room"     binding:"required"`
 	Building string `json:"building" binding:"required"`
-	Capacity string `json:"capacity" binding:"required"` // เก็บเป็น string ตาม entity
+	Capacity uint   `json:"capacity" binding:"required,gt=0"`
 }
 
 func CreateLaboratory(c *gin.Context) {
 	var in createLaboratoryInput
 	if err := c.ShouldBindJSON(&in); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "ข้อมูลไม่ถูกต้อง"})
-		return
-	}
-
-	// ตรวจว่า capacity เป็นตัวเลขได้ (แม้เก็บเป็น string)
-	if _, err := strconv.Atoi(in.Capacity); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "capacity ต้องเป็นตัวเลข"})
+		respondError(c, http.StatusBadRequest, "ข้อมูลไม่ถูกต้อง")
 		return
 	}
 
@@ -44,7 +42,7 @@ func CreateLaboratory(c *gin.Context) {
 		Where("room = ? AND building = ?", in.Room, in.Building).
 		Count(&dupe)
 	if dupe > 0 {
-		c.JSON(http.StatusConflict, gin.H{"error": "ห้องนี้ในอาคารนี้มีอยู่แล้ว"})
+		respondError(c, http.StatusConflict, "ห้องนี้ในอาคารนี้มีอยู่แล้ว")
 		return
 	}
 
@@ -54,8 +52,17 @@ func CreateLaboratory(c *gin.Context) {
 		Capacity: in.Capacity,
 	}
 
-	if err := config.DB().Create(&lab).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "ไม่สามารถสร้างห้องปฏิบัติการได้"})
+	if !validateEntity(c, lab) {
+		return
+	}
+	// สร้างห้องปฏิบัติการและห้องในแคตตาล็อกในธุรกรรมเดียวกัน
+	if err := config.DB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&lab).Error; err != nil {
+			return err
+		}
+		return config.SyncLaboratoryRoom(tx, lab)
+	}); err != nil {
+		respondServerError(c, http.StatusInternalServerError, "ไม่สามารถสร้างห้องปฏิบัติการได้", err)
 		return
 	}
 
@@ -77,7 +84,7 @@ func GetLaboratoryByID(c *gin.Context) {
 	idParam := c.Param("id")
 	idUint64, err := strconv.ParseUint(idParam, 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "รหัสไม่ถูกต้อง"})
+		respondError(c, http.StatusBadRequest, "รหัสไม่ถูกต้อง")
 		return
 	}
 	id := uint(idUint64)
@@ -85,7 +92,7 @@ func GetLaboratoryByID(c *gin.Context) {
 	// ค้นหา record
 	var lab entity.Laboratory
 	if err := config.DB().First(&lab, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "ไม่พบห้องปฏิบัติการ"})
+		respondError(c, http.StatusNotFound, "ไม่พบห้องปฏิบัติการ")
 		return
 	}
 
@@ -107,7 +114,7 @@ func GetLaboratoryByID(c *gin.Context) {
 type updateLaboratoryInput struct {
 	Room     string `json:"room" binding:"required"`
 	Building string `json:"building" binding:"required"`
-	Capacity string `json:"capacity" binding:"required"`
+	Capacity uint   `json:"capacity" binding:"required,gt=0"`
 }
 
 func UpdateLaboratory(c *gin.Context) {
@@ -115,7 +122,7 @@ func UpdateLaboratory(c *gin.Context) {
 	idParam := c.Param("id")
 	idUint64, err := strconv.ParseUint(idParam, 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "รหัสไม่ถูกต้อง"})
+		respondError(c, http.StatusBadRequest, "รหัสไม่ถูกต้อง")
 		return
 	}
 	id := uint(idUint64)
@@ -123,20 +130,14 @@ func UpdateLaboratory(c *gin.Context) {
 	// หา record เดิม
 	var lab entity.Laboratory
 	if err := config.DB().First(&lab, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "ไม่พบห้องปฏิบัติการ"})
+		respondError(c, http.StatusNotFound, "ไม่พบห้องปฏิบัติการ")
 		return
 	}
 
 	// bind body
 	var in updateLaboratoryInput
 	if err := c.ShouldBindJSON(&in); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "ข้อมูลไม่ถูกต้อง"})
-		return
-	}
-
-	// ตรวจว่า capacity เป็นตัวเลขได้ (แม้เก็บเป็น string)
-	if _, err := strconv.Atoi(in.Capacity); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "capacity ต้องเป็นตัวเลข"})
+		respondError(c, http.StatusBadRequest, "ข้อมูลไม่ถูกต้อง")
 		return
 	}
 
@@ -146,11 +147,11 @@ func UpdateLaboratory(c *gin.Context) {
 		Model(&entity.Laboratory{}).
 		Where("room = ? AND building = ? AND id <> ?", in.Room, in.Building, id).
 		Count(&dupe).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "ไม่สามารถตรวจสอบข้อมูลซ้ำได้"})
+		respondError(c, http.StatusInternalServerError, "ไม่สามารถตรวจสอบข้อมูลซ้ำได้")
 		return
 	}
 	if dupe > 0 {
-		c.JSON(http.StatusConflict, gin.H{"error": "ห้องนี้ในอาคารนี้มีอยู่แล้ว"})
+		respondError(c, http.StatusConflict, "ห้องนี้ในอาคารนี้มีอยู่แล้ว")
 		return
 	}
 
@@ -159,8 +160,16 @@ func UpdateLaboratory(c *gin.Context) {
 	lab.Building = in.Building
 	lab.Capacity = in.Capacity
 
-	if err := config.DB().Save(&lab).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "ไม่สามารถอัปเดตห้องปฏิบัติการได้"})
+	if !validateEntity(c, lab) {
+		return
+	}
+	if err := config.DB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&lab).Error; err != nil {
+			return err
+		}
+		return config.SyncLaboratoryRoom(tx, lab)
+	}); err != nil {
+		respondServerError(c, http.StatusInternalServerError, "ไม่สามารถอัปเดตห้องปฏิบัติการได้", err)
 		return
 	}
 
@@ -182,7 +191,7 @@ func DeleteLaboratory(c *gin.Context) {
 	idParam := c.Param("id")
 	idUint64, err := strconv.ParseUint(idParam, 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "รหัสไม่ถูกต้อง"})
+		respondError(c, http.StatusBadRequest, "รหัสไม่ถูกต้อง")
 		return
 	}
 	id := uint(idUint64)
@@ -190,13 +199,13 @@ func DeleteLaboratory(c *gin.Context) {
 	// ตรวจว่ามีอยู่จริง
 	var lab entity.Laboratory
 	if err := config.DB().First(&lab, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "ไม่พบห้องปฏิบัติการ"})
+		respondError(c, http.StatusNotFound, "ไม่พบห้องปฏิบัติการ")
 		return
 	}
 
-	// ลบ
-	if err := config.DB().Delete(&lab).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "ไม่สามารถลบห้องปฏิบัติการได้"})
+	// ลบ (ห้องเรียนของห้องปฏิบัติการถูกลบตามไป รายวิชาที่ใช้ห้องจะถูกตัดการอ้างอิงเมื่อขอ cascade)
+	plan, ok := deleteWithDependents(c, config.KindLaboratory, lab.ID, "ไม่สามารถลบห้องปฏิบัติการได้")
+	if !ok {
 		return
 	}
 
@@ -205,5 +214,304 @@ func DeleteLaboratory(c *gin.Context) {
 		"data": gin.H{
 			"id": lab.ID,
 		},
+		"cascaded": plan.Cascaded,
+		"detached": plan.Detached,
+	})
+}
+
+// RestoreLaboratory กู้คืนห้องปฏิบัติการที่ถูกลบพร้อมห้องเรียนของห้องนั้น
+func RestoreLaboratory(c *gin.Context) {
+	restoreDeleted(c, config.KindLaboratory)
+}
+
+type (
+	LabDayUsage struct {
+		DayOfWeek     string
+		OccupiedHours float64
+		Percent       float64
+	}
+
+	LabUtilizationResp struct {
+		LaboratoryID  uint
+		Room          string
+		Building      string
+		Capacity      uint
+		OccupiedHours float64
+		Percent       float64
+		Days          []LabDayUsage
+	}
+
+	FreeLabResp struct {
+		LaboratoryID uint
+		Room         string
+		Building     string
+		Capacity     uint
+	}
+)
+
+type clockInterval struct{ start, end int }
+
+// mergedMinutes รวมช่วงเวลาที่ทับกันแล้วคืนจำนวนนาทีที่ถูกใช้จริง
+func mergedMinutes(intervals []clockInterval) int {
+	if len(intervals) == 0 {
+		return 0
+	}
+	sort.Slice(intervals, func(i, j int) bool { return intervals[i].start < intervals[j].start })
+
+	total := 0
+	cur := intervals[0]
+	for _, iv := range intervals[1:] {
+		if iv.start <= cur.end {
+			if iv.end > cur.end {
+				cur.end = iv.end
+			}
+			continue
+		}
+		total += cur.end - cur.start
+		cur = iv
+	}
+	total += cur.end - cur.start
+	return total
+}
+
+// labSchedules ดึงคาบเรียนของ NameTable ที่ใช้ห้องปฏิบัติการ
+// (คาบที่ระบุห้องปฏิบัติการ หรือคาบเก่าที่ยังไม่ระบุห้องของวิชาที่ผูกกับห้องปฏิบัติการ)
+func labSchedules(nameTable string) ([]entity.Schedule, error) {
+	var schedules []entity.Schedule
+	err := config.DB().
+		Preload("OfferedCourses").
+		Preload("Room").
+		Joins("JOIN offered_courses ON schedules.offered_courses_id = offered_courses.id").
+		Joins("LEFT JOIN rooms ON schedules.room_id = rooms.id").
+		Where("schedules.name_table = ? AND offered_courses.deleted_at IS NULL", nameTable).
+		Where(`(rooms.laboratory_id IS NOT NULL)
+			OR (schedules.room_id IS NULL AND offered_courses.laboratory_id IS NOT NULL)`).
+		Find(&schedules).Error
+	return schedules, err
+}
+
+// scheduleLabID คืนห้องปฏิบัติการที่คาบนั้นใช้
+func scheduleLabID(s entity.Schedule) uint {
+	if s.RoomID != nil && s.Room.LaboratoryID != nil {
+		return *s.Room.LaboratoryID
+	}
+	return *s.OfferedCourses.LaboratoryID
+}
+
+// GET /lab-utilization?name_table=ปีการศึกษา 2568 เทอม 2&major_name=...
+// major_name ใช้เลือกตารางเวลาของภาควิชา ถ้าไม่ระบุใช้ตารางเวลาเริ่มต้น
+func GetLaboratoryUtilization(c *gin.Context) {
+	nameTable := c.Query("name_table")
+	if nameTable == "" {
+		respondError(c, http.StatusBadRequest, "ต้องระบุ name_table")
+		return
+	}
+
+	var deptID uint
+	if major := c.Query("major_name"); major != "" {
+		if err := config.DB().Table("majors").Select("department_id").Where("major_name = ?", major).Scan(&deptID).Error; err != nil {
+			respondServerError(c, http.StatusInternalServerError, "ไม่สามารถดึง department ของ major ได้", err)
+			return
+		}
+	}
+
+	var labs []entity.Laboratory
+	if err := config.DB().Order("room").Find(&labs).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, "ไม่สามารถดึงข้อมูลห้องปฏิบัติการได้")
+		return
+	}
+
+	schedules, err := labSchedules(nameTable)
+	if err != nil {
+		respondServerError(c, http.StatusInternalServerError, "ไม่สามารถดึงตารางสอนได้", err)
+		return
+	}
+
+	// occupied[labID][day] = ช่วงเวลาที่ถูกใช้
+	occupied := make(map[uint]map[string][]clockInterval)
+	for _, s := range schedules {
+		labID := scheduleLabID(s)
+		if occupied[labID] == nil {
+			occupied[labID] = make(map[string][]clockInterval)
+		}
+		occupied[labID][s.DayOfWeek] = append(occupied[labID][s.DayOfWeek],
+			clockInterval{start: clockMinutes(s.StartTime), end: clockMinutes(s.EndTime)})
+	}
+
+	// ชั่วโมงที่ใช้ได้ต่อวันคิดจากตารางเวลาของภาควิชา (วันทำการ ช่วงเวลาทำการ เว้นช่วงห้ามจัด)
+	grid, err := loadTimeGrid(deptID)
+	if err != nil {
+		respondServerError(c, http.StatusInternalServerError, "ตารางเวลาของภาควิชาไม่ถูกต้อง", err)
+		return
+	}
+	hoursPerDay := make(map[string]float64, len(grid.days))
+	totalHours := 0.0
+	for _, d := range grid.days {
+		day := getDayName(d)
+		hoursPerDay[day] = float64(grid.openMinutes(day)) / 60
+		totalHours += hoursPerDay[day]
+	}
+
+	percent := func(used, available float64) float64 {
+		if available <= 0 {
+			return 0
+		}
+		return math.Round(used/available*10000) / 100
+	}
+
+	resp := make([]LabUtilizationResp, 0, len(labs))
+	for _, lab := range labs {
+		item := LabUtilizationResp{
+			LaboratoryID: lab.ID,
+			Room:         lab.Room,
+			Building:     lab.Building,
+			Capacity:     lab.Capacity,
+			Days:         make([]LabDayUsage, 0, len(grid.days)),
+		}
+		for _, d := range grid.days {
+			day := getDayName(d)
+			hours := float64(mergedMinutes(occupied[lab.ID][day])) / 60
+			item.Days = append(item.Days, LabDayUsage{
+				DayOfWeek:     day,
+				OccupiedHours: hours,
+				Percent:       percent(hours, hoursPerDay[day]),
+			})
+			item.OccupiedHours += hours
+		}
+		item.Percent = percent(item.OccupiedHours, totalHours)
+		resp = append(resp, item)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"name_table":    nameTable,
+		"hours_per_day": hoursPerDay,
+		"data":          resp,
 	})
-}
\ No newline at end of file
+}
+
+// GET /free-labs?name_table=...&day=จันทร์&start=09:00&end=12:00&capacity=40
+func GetFreeLaboratories(c *gin.Context) {
+	nameTable := c.Query("name_table")
+	day := c.Query("day")
+	if nameTable == "" || day == "" {
+		respondError(c, http.StatusBadRequest, "ต้องระบุ name_table และ day")
+		return
+	}
+
+	start, err1 := time.Parse("15:04", c.Query("start"))
+	end, err2 := time.Parse("15:04", c.Query("end"))
+	if err1 != nil || err2 != nil {
+		respondError(c, http.StatusBadRequest, "รูปแบบเวลาไม่ถูกต้อง (HH:mm)")
+		return
+	}
+	startMin := start.Hour()*60 + start.Minute()
+	endMin := end.Hour()*60 + end.Minute()
+	if endMin <= startMin {
+		respondError(c, http.StatusBadRequest, "เวลาสิ้นสุดต้องมากกว่าเวลาเริ่ม")
+		return
+	}
+
+	var required uint
+	if v := c.Query("capacity"); v != "" {
+		n, err := strconv.ParseUint(v, 10, 32)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, "capacity ต้องเป็นตัวเลข")
+			return
+		}
+		required = uint(n)
+	}
+
+	var labs []entity.Laboratory
+	if err := config.DB().Order("room").Find(&labs).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, "ไม่สามารถดึงข้อมูลห้องปฏิบัติการได้")
+		return
+	}
+
+	schedules, err := labSchedules(nameTable)
+	if err != nil {
+		respondServerError(c, http.StatusInternalServerError, "ไม่สามารถดึงตารางสอนได้", err)
+		return
+	}
+
+	busy := make(map[uint]bool)
+	for _, s := range schedules {
+		if s.DayOfWeek != day {
+			continue
+		}
+		if startMin < clockMinutes(s.EndTime) && endMin > clockMinutes(s.StartTime) {
+			busy[scheduleLabID(s)] = true
+		}
+	}
+
+	resp := make([]FreeLabResp, 0)
+	for _, lab := range labs {
+		if busy[lab.ID] {
+			continue
+		}
+		if lab.Capacity < required {
+			continue
+		}
+		resp = append(resp, FreeLabResp{
+			LaboratoryID: lab.ID,
+			Room:         lab.Room,
+			Building:     lab.Building,
+			Capacity:     lab.Capacity,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": resp})
+}
+
+type LabCapacityMismatch struct {
+	OfferedCoursesID uint
+	Code             string
+	Name             string
+	Year             uint
+	Term             uint
+	Capacity         uint
+	LaboratoryID     uint
+	Room             string
+	LabCapacity      uint
+	Excess           uint
+}
+
+// GET /lab-capacity-mismatches?year=2568&term=2
+func GetLaboratoryCapacityMismatches(c *gin.Context) {
+	db := config.DB().
+		Preload("AllCourses").
+		Preload("Laboratory").
+		Joins("JOIN laboratories ON offered_courses.laboratory_id = laboratories.id").
+		Where("offered_courses.capacity > laboratories.capacity").
+		Where("laboratories.deleted_at IS NULL")
+
+	if y, err := strconv.Atoi(c.Query("year")); err == nil && y > 0 {
+		db = db.Where("offered_courses.year = ?", y)
+	}
+	if t, err := strconv.Atoi(c.Query("term")); err == nil && t > 0 {
+		db = db.Where("offered_courses.term = ?", t)
+	}
+
+	var offered []entity.OfferedCourses
+	if err := db.Order("offered_courses.year, offered_courses.term").Find(&offered).Error; err != nil {
+		respondServerError(c, http.StatusInternalServerError, "ไม่สามารถตรวจสอบความจุห้องปฏิบัติการได้", err)
+		return
+	}
+
+	resp := make([]LabCapacityMismatch, 0, len(offered))
+	for _, oc := range offered {
+		resp = append(resp, LabCapacityMismatch{
+			OfferedCoursesID: oc.ID,
+			Code:             oc.AllCourses.Code,
+			Name:             oc.AllCourses.EnglishName,
+			Year:             oc.Year,
+			Term:             oc.Term,
+			Capacity:         oc.Capacity,
+			LaboratoryID:     oc.Laboratory.ID,
+			Room:             oc.Laboratory.Room,
+			LabCapacity:      oc.Laboratory.Capacity,
+			Excess:           oc.Capacity - oc.Laboratory.Capacity,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"count": len(resp), "data": resp})
+}
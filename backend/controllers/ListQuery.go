@@ -0,0 +1,38 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Nichakorn25/CPE-Teaching-Schedule/services"
+)
+
+// listQuery อ่าน page/size/sort/ตัวกรองของ endpoint รายการ ถ้าไม่ถูกต้องตอบ 400 และคืน false
+func listQuery(c *gin.Context, spec services.ListSpec) (services.ListQuery, bool) {
+	q, err := services.ParseListQuery(c.Request.URL.Query(), spec)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return q, false
+	}
+	return q, true
+}
+
+// respondList ตอบรายการพร้อมจำนวนทั้งหมด (X-Total-Count)
+// ระบุ page มาจะตอบแบบ envelope ไม่ระบุจะตอบรูปแบบเดิม (legacy) เพื่อไม่ให้หน้าเว็บเดิมพัง
+func respondList(c *gin.Context, q services.ListQuery, total int64, items interface{}, legacy interface{}) {
+	c.Header("X-Total-Count", strconv.FormatInt(total, 10))
+	if !q.Paged {
+		c.JSON(http.StatusOK, legacy)
+		return
+	}
+	pages := (total + int64(q.Size) - 1) / int64(q.Size)
+	c.JSON(http.StatusOK, gin.H{
+		"data":       items,
+		"page":       q.Page,
+		"size":       q.Size,
+		"total":      total,
+		"totalPages": pages,
+	})
+}
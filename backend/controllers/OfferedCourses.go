@@ -4,12 +4,13 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
-	"strings"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 
 	"github.com/Nichakorn25/CPE-Teaching-Schedule/config"
 	"github.com/Nichakorn25/CPE-Teaching-Schedule/entity"
+	"github.com/Nichakorn25/CPE-Teaching-Schedule/services"
 )
 
 type (
@@ -31,6 +32,7 @@ type (
 		Year         uint
 		Term         uint
 		Code         string
+		Codes        []string // รหัสวิชาหลักและวิชาเทียบเท่าที่เรียนรวม
 		Name         string
 		Credit       string
 		Major        string
@@ -76,77 +78,101 @@ func GetCountAllOffered(c *gin.Context) {
 		Count(&count).Error
 
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "เกิดข้อผิดพลาดในการนับ"})
+		respondError(c, http.StatusInternalServerError, "เกิดข้อผิดพลาดในการนับ")
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"count": count})
 }
 
+// GET /offered?year=&term=&major_name= (กรองสาขาใน SQL แทนการโหลดทั้งเทอม)
 func GetOffered(c *gin.Context) {
-	yearQ := c.Query("year")
-	termQ := c.Query("term")
-	majorName := c.Query("major_name")
+	q, ok := listQuery(c, offeredListSpec)
+	if !ok {
+		return
+	}
 
-	var offered []entity.OfferedCourses
 	var count int64
+	if err := offeredListBase(q).Count(&count).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, "เกิดข้อผิดพลาดในการดึงข้อมูล")
+		return
+	}
 
-	db := config.DB().
+	offered := []entity.OfferedCourses{}
+	if err := q.Paginate(offeredListBase(q)).
 		Preload("AllCourses").
 		Preload("AllCourses.Curriculum").
 		Preload("AllCourses.Curriculum.Major").
-		Where("year = ? AND term = ?", yearQ, termQ).
-		Find(&offered)
-
-	if db.Error != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "เกิดข้อผิดพลาดในการดึงข้อมูล"})
+		Find(&offered).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, "เกิดข้อผิดพลาดในการดึงข้อมูล")
 		return
 	}
 
-	filtered := make([]entity.OfferedCourses, 0)
-	for _, o := range offered {
-		if o.AllCourses.Curriculum.Major.MajorName == majorName {
-			filtered = append(filtered, o)
-		}
-	}
-
-	count = int64(len(filtered))
-
-	c.JSON(http.StatusOK, gin.H{
+	respondList(c, q, count, offered, gin.H{
 		"count":   count,
-		"results": filtered,
+		"results": offered,
 	})
 }
 
+// ตัวกรองวิชาที่เปิดสอน (ใช้ร่วมกับ join all_courses/curriculums/majors ใน offeredListBase)
+var offeredFilters = map[string]services.ListFilter{
+	"year":          {Kind: services.FilterUint, Where: "offered_courses.year = ?"},
+	"term":          {Kind: services.FilterUint, Where: "offered_courses.term = ?"},
+	"major_id":      {Kind: services.FilterUint, Where: "curriculums.major_id = ?"},
+	"major_name":    {Kind: services.FilterString, Where: "majors.major_name = ?"},
+	"department_id": {Kind: services.FilterUint, Where: "majors.department_id = ?"},
+	"type_id":       {Kind: services.FilterUint, Where: "all_courses.type_of_courses_id = ?"},
+	"instructor_id": {Kind: services.FilterUint, Where: `(offered_courses.user_id = ? OR EXISTS (SELECT 1 FROM section_instructors si
+		WHERE si.offered_courses_id = offered_courses.id AND si.user_id = ? AND si.deleted_at IS NULL))`},
+	"search": {Kind: services.FilterSearch, Where: `(all_courses.code ILIKE ? OR all_courses.english_name ILIKE ? OR all_courses.thai_name ILIKE ?
+		OR offered_courses.id IN (SELECT a.offered_courses_id FROM offered_course_aliases a
+			JOIN all_courses ac ON ac.id = a.all_courses_id
+			WHERE a.deleted_at IS NULL AND ac.code ILIKE ?))`},
+}
+
+var offeredListSpec = services.ListSpec{
+	Sorts: map[string]string{
+		"id":       "offered_courses.id",
+		"year":     "offered_courses.year",
+		"term":     "offered_courses.term",
+		"code":     "all_courses.code",
+		"name":     "all_courses.english_name",
+		"sections": "offered_courses.section",
+		"capacity": "offered_courses.capacity",
+	},
+	Filters:     offeredFilters,
+	DefaultSort: "year,term,code",
+}
+
+func offeredListBase(q services.ListQuery) *gorm.DB {
+	return q.Filter(config.DB().Model(&entity.OfferedCourses{}).
+		Joins("JOIN all_courses ON all_courses.id = offered_courses.all_courses_id").
+		Joins("JOIN curriculums ON curriculums.id = all_courses.curriculum_id").
+		Joins("JOIN majors ON majors.id = curriculums.major_id"))
+}
+
+// GET /open-courses?year=&term=&page=&size=&sort=&major_id=&department_id=&type_id=&instructor_id=&search=
 func GetOpenCourses(c *gin.Context) {
-	yearQ := c.Query("year")
-	termQ := c.Query("term")
-	search := strings.TrimSpace(c.Query("search"))
+	q, ok := listQuery(c, offeredListSpec)
+	if !ok {
+		return
+	}
+
+	var total int64
+	if err := offeredListBase(q).Count(&total).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, "ดึงข้อมูลล้มเหลว")
+		return
+	}
 
-	db := config.DB().
-		Model(&entity.OfferedCourses{}).
+	var offered []entity.OfferedCourses
+	if err := q.Paginate(offeredListBase(q)).
 		Preload("User.Title").
 		Preload("AllCourses.Credit").
 		Preload("AllCourses.Curriculum.Major").
 		Preload("AllCourses.Curriculum.Major.Department").
 		Preload("AllCourses.TypeOfCourses").
-		Order("year, term, all_courses_id")
-
-	if y, err := strconv.Atoi(yearQ); err == nil && y > 0 {
-		db = db.Where("year = ?", y)
-	}
-	if t, err := strconv.Atoi(termQ); err == nil && t > 0 {
-		db = db.Where("term = ?", t)
-	}
-	if search != "" {
-		like := "%" + search + "%"
-		db = db.
-			Joins("JOIN all_courses ON all_courses.id = offered_courses.all_courses_id").
-			Where("all_courses.code LIKE ? OR all_courses.english_name LIKE ? OR all_courses.thai_name LIKE ?", like, like, like)
-	}
-
-	var offered []entity.OfferedCourses
-	if err := db.Find(&offered).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "ดึงข้อมูลล้มเหลว"})
+		Preload("Aliases.AllCourses").
+		Find(&offered).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, "ดึงข้อมูลล้มเหลว")
 		return
 	}
 
@@ -193,11 +219,11 @@ func GetOpenCourses(c *gin.Context) {
 			}
 		} else {
 			var schedules []entity.Schedule
-			if err := config.DB().Where("offered_courses_id = ?", oc.ID).Find(&schedules).Error; err == nil {
+			if err := config.DB().Preload("Room").Where("offered_courses_id = ?", oc.ID).Find(&schedules).Error; err == nil {
 				for _, sched := range schedules {
 					groupInfos = append(groupInfos, GroupInfo{
 						Group: sched.SectionNumber,
-						Room:  "",
+						Room:  sched.Room.Name,
 						Day:   sched.DayOfWeek,
 						TimeSpan: fmt.Sprintf("%s‑%s",
 							sched.StartTime.Format("15:04"),
@@ -212,6 +238,7 @@ func GetOpenCourses(c *gin.Context) {
 			Year:         oc.Year,
 			Term:         oc.Term,
 			Code:         ac.Code,
+			Codes:        offeredCodes(oc),
 			Name:         ac.EnglishName,
 			Credit:       credit,
 			Major:        ac.Curriculum.Major.MajorName,
@@ -227,14 +254,55 @@ func GetOpenCourses(c *gin.Context) {
 		})
 	}
 
-	c.JSON(http.StatusOK, gin.H{"data": resp})
+	respondList(c, q, total, resp, gin.H{"data": resp})
+}
+
+// labCapacityConflict คืนห้องปฏิบัติการเมื่อจำนวนที่นั่งต่อกลุ่มเกินความจุของห้อง (nil = ผ่าน)
+func labCapacityConflict(labID *uint, capacity uint) (*entity.Laboratory, error) {
+	if labID == nil || *labID == 0 {
+		return nil, nil
+	}
+
+	var lab entity.Laboratory
+	if err := config.DB().First(&lab, *labID).Error; err != nil {
+		return nil, err
+	}
+	if capacity > lab.Capacity {
+		return &lab, nil
+	}
+	return nil, nil
+}
+
+// rejectLabCapacity ตอบ 400 และคืน true เมื่อห้องปฏิบัติการไม่พบหรือรับจำนวนที่นั่งไม่พอ
+func rejectLabCapacity(c *gin.Context, labID *uint, capacity uint) bool {
+	lab, err := labCapacityConflict(labID, capacity)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "ไม่พบห้องปฏิบัติการที่ระบุ")
+		return true
+	}
+	if lab != nil {
+		respondError(c, http.StatusBadRequest, "จำนวนที่นั่งต่อกลุ่มเกินความจุของห้องปฏิบัติการ", gin.H{
+			"capacity":     capacity,
+			"room":         lab.Room,
+			"lab_capacity": lab.Capacity,
+		})
+		return true
+	}
+	return false
 }
 
 // ////////////////////////////////// add open course //////////////////////////
 func CreateOfferedCourse(c *gin.Context) {
 	var input CreateOfferedCourseInput
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBindError(c, err)
+		return
+	}
+
+	if rejectLabCapacity(c, input.LaboratoryID, input.Capacity) {
+		return
+	}
+	if rejectAliasedCourse(c, input.AllCoursesID, input.Year, input.Term, 0) {
 		return
 	}
 
@@ -248,9 +316,18 @@ func CreateOfferedCourse(c *gin.Context) {
 		AllCoursesID: input.AllCoursesID,
 		LaboratoryID: input.LaboratoryID,
 	}
+	if !validateEntity(c, offered) {
+		return
+	}
 
-	if err := config.DB().Create(&offered).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "ไม่สามารถสร้างวิชาที่เปิดสอนได้"})
+	if err := config.DB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&offered).Error; err != nil {
+			return err
+		}
+		_, err := config.EnsureSections(tx, offered)
+		return err
+	}); err != nil {
+		respondSectionsError(c, err, "ไม่สามารถสร้างวิชาที่เปิดสอนได้")
 		return
 	}
 	c.JSON(http.StatusCreated, offered)
@@ -262,13 +339,13 @@ func UpdateOfferedCourse(c *gin.Context) {
 
 	var offered entity.OfferedCourses
 	if err := config.DB().First(&offered, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "ไม่พบรายวิชาที่จะเปิดสอน"})
+		respondError(c, http.StatusNotFound, "ไม่พบรายวิชาที่จะเปิดสอน")
 		return
 	}
 
 	var input UpdateOfferedCourseInput
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBindError(c, err)
 		return
 	}
 
@@ -293,9 +370,25 @@ func UpdateOfferedCourse(c *gin.Context) {
 	if input.LaboratoryID != nil {
 		offered.LaboratoryID = input.LaboratoryID
 	}
+	if !validateEntity(c, offered) {
+		return
+	}
 
-	if err := config.DB().Save(&offered).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "ไม่สามารถอัปเดตรายวิชาที่เปิดสอนได้"})
+	if rejectLabCapacity(c, offered.LaboratoryID, offered.Capacity) {
+		return
+	}
+	if rejectAliasedCourse(c, offered.AllCoursesID, offered.Year, offered.Term, offered.ID) {
+		return
+	}
+
+	if err := config.DB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&offered).Error; err != nil {
+			return err
+		}
+		_, err := config.EnsureSections(tx, offered)
+		return err
+	}); err != nil {
+		respondSectionsError(c, err, "ไม่สามารถอัปเดตรายวิชาที่เปิดสอนได้")
 		return
 	}
 	c.JSON(http.StatusOK, offered)
@@ -306,16 +399,22 @@ func DeleteOfferedCourse(c *gin.Context) {
 
 	var offered entity.OfferedCourses
 	if err := config.DB().First(&offered, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "ไม่พบรายวิชาที่จะเปิดสอน"})
+		respondError(c, http.StatusNotFound, "ไม่พบรายวิชาที่จะเปิดสอน")
 		return
 	}
 
-	if err := config.DB().Delete(&offered).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "ไม่สามารถลบรายวิชาที่จะเปิดสอนได้"})
+	// กลุ่มเรียนและผู้สอนประจำกลุ่มถูกลบตามไปเสมอ ส่วนตารางสอนและผู้ช่วยสอนต้องขอ cascade
+	plan, ok := deleteWithDependents(c, config.KindOfferedCourse, offered.ID, "ไม่สามารถลบรายวิชาที่จะเปิดสอนได้")
+	if !ok {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "ลบรายวิชาที่เปิดสอนสำเร็จ"})
+	c.JSON(http.StatusOK, gin.H{"message": "ลบรายวิชาที่เปิดสอนสำเร็จ", "cascaded": plan.Cascaded, "detached": plan.Detached})
+}
+
+// RestoreOfferedCourse กู้คืนรายวิชาที่เปิดสอนที่ถูกลบ พร้อมกลุ่มเรียนและตารางสอนที่ถูกลบไปด้วยกัน
+func RestoreOfferedCourse(c *gin.Context) {
+	restoreDeleted(c, config.KindOfferedCourse)
 }
 
 // /////////////////////////// final-offerated
@@ -329,10 +428,12 @@ type TAResponse struct {
 
 type SectionDetail struct {
 	ID                 uint
+	SectionID          uint
 	SectionNumber      uint
 	Room               string
 	DayOfWeek          string
 	Time               string
+	Kind               string
 	Capacity           uint
 	ID_user            uint
 	InstructorNames    []string
@@ -342,6 +443,7 @@ type SectionDetail struct {
 type OfferedCoursesDetail struct {
 	ID                uint
 	Code              string
+	Codes             []string
 	ThaiCourseName    string
 	EnglishCourseName string
 	Credit            string
@@ -356,6 +458,7 @@ type OfferedCoursesDetailbyID struct {
 	CurriculumID      uint
 	Curriculum        string
 	Code              string
+	Codes             []string
 	ThaiCourseName    string
 	EnglishCourseName string
 	Credit            string
@@ -376,6 +479,14 @@ type CourseItem struct {
 	TypeName       string
 }
 
+// scheduleRoomName ใช้ห้องที่ระบบจัดให้คาบนั้น ถ้ายังไม่มีใช้ห้องเริ่มต้นของวิชา
+func scheduleRoomName(sch entity.Schedule, fallback string) string {
+	if sch.RoomID != nil && sch.Room.ID != 0 {
+		return sch.Room.Name
+	}
+	return fallback
+}
+
 func GetOfferedCoursesAndSchedule(c *gin.Context) {
 	majorName := c.Query("major_name")
 	year := c.Query("year")
@@ -387,13 +498,16 @@ func GetOfferedCoursesAndSchedule(c *gin.Context) {
 		Preload("AllCourses.TypeOfCourses").
 		Preload("AllCourses.Curriculum.Major").
 		Preload("AllCourses.UserAllCourses.User.Title").
+		Preload("SectionInstructors.User.Title").
 		Preload("Schedule.TimeFixedCourses").
+		Preload("Schedule.Room").
 		Preload("Laboratory").
-		Preload("Schedule.ScheduleTeachingAssistant.TeachingAssistant").
-		Preload("Schedule.ScheduleTeachingAssistant.TeachingAssistant.Title").
+		Preload("Aliases.AllCourses").
+		Preload("Sections.Room").
+		Preload("Sections.TeachingAssistants.TeachingAssistant.Title").
 		Where("year = ? AND term = ?", year, term).
 		Find(&offeredCourses).Distinct("offered_courses.id").Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondServerError(c, http.StatusInternalServerError, "ไม่สามารถดึงข้อมูลรายวิชาที่เปิดสอนได้", err)
 		return
 	}
 
@@ -417,26 +531,7 @@ func GetOfferedCoursesAndSchedule(c *gin.Context) {
 			instructors = append(instructors, instructorName)
 		}
 
-		// ผู้ช่วยสอน
-		taMap := make(map[uint]TAResponse)
-		for _, sch := range oc.Schedule {
-			for _, sta := range sch.ScheduleTeachingAssistant {
-				ta := sta.TeachingAssistant
-				if ta.ID != 0 {
-					taMap[ta.ID] = TAResponse{
-						ID:        ta.ID,
-						Title:     ta.Title.Title,
-						Firstname: ta.Firstname,
-						Lastname:  ta.Lastname,
-					}
-				}
-			}
-		}
-
-		teachingAssistants := []TAResponse{}
-		for _, ta := range taMap {
-			teachingAssistants = append(teachingAssistants, ta)
-		}
+		sections := sectionsByNumber(oc)
 
 		credit := fmt.Sprintf("%d(%d-%d-%d)",
 			oc.AllCourses.Credit.Unit,
@@ -450,23 +545,14 @@ func GetOfferedCoursesAndSchedule(c *gin.Context) {
 			grouped[oc.AllCourses.Code] = &OfferedCoursesDetail{
 				ID:                oc.ID,
 				Code:              oc.AllCourses.Code,
+				Codes:             offeredCodes(oc),
 				ThaiCourseName:    oc.AllCourses.ThaiName,
 				EnglishCourseName: oc.AllCourses.EnglishName,
 				Credit:            credit,
 				TypeOfCourse:      oc.AllCourses.TypeOfCourses.TypeName,
 				TotalSections:     oc.Section,
 				IsFixCourses:      oc.IsFixCourses,
-				Sections: []SectionDetail{
-					{
-						SectionNumber:      oc.Section,
-						Room:               room,
-						DayOfWeek:          "",
-						Time:               "",
-						Capacity:           oc.Capacity,
-						InstructorNames:    instructors,
-						TeachingAssistants: teachingAssistants,
-					},
-				},
+				Sections:          placeholderSections(oc, sections, room, instructors),
 			}
 		}
 
@@ -478,13 +564,14 @@ func GetOfferedCoursesAndSchedule(c *gin.Context) {
 					key := fmt.Sprintf("%d-%s-%s", tf.Section, tf.DayOfWeek, tf.StartTime)
 					sectionMap[key] = SectionDetail{
 						ID:                 tf.ID,
+						SectionID:          sections[tf.Section].ID,
 						SectionNumber:      tf.Section,
 						Room:               tf.RoomFix,
 						DayOfWeek:          tf.DayOfWeek,
 						Time:               tf.StartTime.Format("15:04") + " - " + tf.EndTime.Format("15:04"),
 						Capacity:           tf.Capacity,
-						InstructorNames:    instructors,
-						TeachingAssistants: teachingAssistants,
+						InstructorNames:    sectionInstructorNames(oc, tf.Section, instructors),
+						TeachingAssistants: sectionTeachingAssistants(sections[tf.Section]),
 					}
 				}
 			}
@@ -493,13 +580,15 @@ func GetOfferedCoursesAndSchedule(c *gin.Context) {
 				key := fmt.Sprintf("%d-%s-%s", sch.SectionNumber, sch.DayOfWeek, sch.StartTime)
 				sectionMap[key] = SectionDetail{
 					ID:                 sch.ID,
+					SectionID:          sections[sch.SectionNumber].ID,
 					SectionNumber:      sch.SectionNumber,
-					Room:               room,
+					Room:               scheduleRoomName(sch, sectionRoomName(sections[sch.SectionNumber], room)),
 					DayOfWeek:          sch.DayOfWeek,
 					Time:               sch.StartTime.Format("15:04") + " - " + sch.EndTime.Format("15:04"),
-					Capacity:           oc.Capacity,
-					InstructorNames:    instructors,
-					TeachingAssistants: teachingAssistants,
+					Kind:               sch.Kind,
+					Capacity:           sectionCapacity(sections[sch.SectionNumber], oc.Capacity),
+					InstructorNames:    sectionInstructorNames(oc, sch.SectionNumber, instructors),
+					TeachingAssistants: sectionTeachingAssistants(sections[sch.SectionNumber]),
 				}
 			}
 		}
@@ -535,7 +624,7 @@ func GetOfferedCoursesAndSchedule(c *gin.Context) {
 	c.JSON(http.StatusOK, responses)
 }
 
-// Request สำหรับลบผู้ช่วยสอน
+// Request สำหรับลบผู้ช่วยสอน (SectionID คือ Section.ID ไม่ใช่ Schedule.ID)
 type RemoveTARequest struct {
     SectionID          uint `json:"section_id"`
     TeachingAssistantID uint `json:"teaching_assistant_id"`
@@ -547,33 +636,33 @@ func RemoveTeachingAssistant(c *gin.Context) {
 
     sectionID, err := strconv.Atoi(sectionIDStr)
     if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid section ID"})
+        respondError(c, http.StatusBadRequest, "รหัสกลุ่มเรียนไม่ถูกต้อง")
         return
     }
 
     taID, err := strconv.Atoi(taIDStr)
     if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid teaching assistant ID"})
+        respondError(c, http.StatusBadRequest, "รหัสผู้ช่วยสอนไม่ถูกต้อง")
         return
     }
 
     var sta entity.ScheduleTeachingAssistant
     if err := config.DB().
-        Where("schedule_id = ? AND teaching_assistant_id = ?", sectionID, taID).
+        Where("section_id = ? AND teaching_assistant_id = ?", sectionID, taID).
         First(&sta).Error; err != nil {
-        c.JSON(http.StatusNotFound, gin.H{"error": "Teaching assistant not found in this section"})
+        respondError(c, http.StatusNotFound, "ไม่พบผู้ช่วยสอนในกลุ่มเรียนนี้")
         return
     }
 
     if err := config.DB().Delete(&sta).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove teaching assistant"})
+        respondError(c, http.StatusInternalServerError, "ไม่สามารถนำผู้ช่วยสอนออกได้")
         return
     }
 
     c.JSON(http.StatusOK, gin.H{"message": "Teaching assistant removed successfully"})
 }
 
-// Request สำหรับอัปเดตผู้ช่วยสอน
+// Request สำหรับอัปเดตผู้ช่วยสอน (SectionID คือ Section.ID ไม่ใช่ Schedule.ID)
 type UpdateTARequest struct {
     SectionID          uint   `json:"section_id"`
     TeachingAssistantIDs []uint `json:"teaching_assistant_ids"` // list ใหม่ทั้งหมด
@@ -583,31 +672,68 @@ type UpdateTARequest struct {
 func UpdateTeachingAssistants(c *gin.Context) {
     var req UpdateTARequest
     if err := c.ShouldBindJSON(&req); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        respondBindError(c, err)
         return
     }
 
-    // ลบ TA เก่าออกทั้งหมด
-    if err := config.DB().
-        Where("schedule_id = ?", req.SectionID).
-        Delete(&entity.ScheduleTeachingAssistant{}).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to clear old teaching assistants"})
+    var section entity.Section
+    if err := config.DB().First(&section, req.SectionID).Error; err != nil {
+        respondError(c, http.StatusNotFound, "ไม่พบกลุ่มเรียน")
+        return
+    }
+
+    // ตัด TA ที่ส่งซ้ำออก (คงลำดับเดิม)
+    uniqTA := map[uint]struct{}{}
+    taIDs := make([]uint, 0, len(req.TeachingAssistantIDs))
+    for _, id := range req.TeachingAssistantIDs {
+        if _, ok := uniqTA[id]; !ok {
+            uniqTA[id] = struct{}{}
+            taIDs = append(taIDs, id)
+        }
+    }
+
+    rows := make([]entity.ScheduleTeachingAssistant, 0, len(taIDs))
+    for _, taID := range taIDs {
+        rows = append(rows, entity.ScheduleTeachingAssistant{SectionID: &section.ID, TeachingAssistantID: taID})
+    }
+    if !validateEntity(c, rows) {
         return
     }
 
-    // เพิ่ม TA ใหม่
-    for _, taID := range req.TeachingAssistantIDs {
-        newSTA := entity.ScheduleTeachingAssistant{
-            ScheduleID:          req.SectionID,
-            TeachingAssistantID: taID,
+    // ตรวจคาบชนของผู้ช่วยสอนชุดใหม่ (ไม่นับการมอบหมายเดิมของกลุ่มนี้ที่จะถูกแทนที่)
+    warnings := []TAAssignmentIssue{}
+    book, err := loadTABookForSection(section.ID)
+    if err != nil {
+        respondError(c, http.StatusInternalServerError, "โหลดภาระงานผู้ช่วยสอนไม่สำเร็จ")
+        return
+    }
+    if book != nil {
+        for taID := range book.assigned {
+            book.remove(taID, section.ID)
         }
-        if err := config.DB().Create(&newSTA).Error; err != nil {
-            c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add new teaching assistant"})
+        var conflicts []TAAssignmentIssue
+        conflicts, warnings = book.checkAll(taIDs, []uint{section.ID})
+        if len(conflicts) > 0 {
+            respondError(c, http.StatusConflict, "ผู้ช่วยสอนมีคาบชนกับกลุ่มเรียนอื่น", gin.H{"conflicts": conflicts})
             return
         }
     }
 
-    c.JSON(http.StatusOK, gin.H{"message": "Teaching assistants updated successfully"})
+    // แทนที่ TA เดิมทั้งชุดในคราวเดียว ถ้าเพิ่มไม่สำเร็จ TA เดิมยังอยู่ครบ
+    if err := config.DB().Transaction(func(tx *gorm.DB) error {
+        if err := tx.Where("section_id = ?", section.ID).Delete(&entity.ScheduleTeachingAssistant{}).Error; err != nil {
+            return err
+        }
+        if len(rows) == 0 {
+            return nil
+        }
+        return tx.Create(&rows).Error
+    }); err != nil {
+        respondError(c, http.StatusInternalServerError, "ไม่สามารถบันทึกผู้ช่วยสอนของกลุ่มเรียนได้")
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"message": "Teaching assistants updated successfully", "warnings": warnings})
 }
 
 
@@ -624,20 +750,24 @@ func GetOfferedCoursesAndSchedulebyID(c *gin.Context) {
 		Preload("AllCourses.Curriculum").
 		Preload("AllCourses.Curriculum.Major").
 		Preload("AllCourses.UserAllCourses.User.Title").
+		Preload("SectionInstructors.User.Title").
 		Preload("Schedule.TimeFixedCourses").
+		Preload("Schedule.Room").
+		Preload("Sections.Room").
+		Preload("Aliases.AllCourses").
 		Preload("Laboratory")
 
 	if id != "" {
 		// ถ้ามี id → filter ด้วย id โดยตรง
 		if err := query.Where("offered_courses.id = ?", id).Find(&offeredCourses).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			respondServerError(c, http.StatusInternalServerError, "ไม่สามารถดึงข้อมูลรายวิชาที่เปิดสอนได้", err)
 			return
 		}
 	} else {
 		// ถ้าไม่มี id → ใช้ year/term/major เหมือนเดิม
 		if err := query.Where("year = ? AND term = ?", year, term).
 			Find(&offeredCourses).Distinct("offered_courses.id").Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			respondServerError(c, http.StatusInternalServerError, "ไม่สามารถดึงข้อมูลรายวิชาที่เปิดสอนได้", err)
 			return
 		}
 	}
@@ -665,6 +795,7 @@ func GetOfferedCoursesAndSchedulebyID(c *gin.Context) {
 		credit := fmt.Sprintf("%d",
 			oc.AllCourses.Credit.Unit,
 		)
+		sections := sectionsByNumber(oc)
 
 		lab := "ไม่มีการสอนปฏิบัติการ"
 		if oc.LaboratoryID != nil && oc.Laboratory.ID != 0 {
@@ -677,20 +808,14 @@ func GetOfferedCoursesAndSchedulebyID(c *gin.Context) {
 				CurriculumID:      oc.AllCourses.Curriculum.ID,
 				Curriculum:        oc.AllCourses.Curriculum.CurriculumName,
 				Code:              oc.AllCourses.Code,
+				Codes:             offeredCodes(oc),
 				ThaiCourseName:    oc.AllCourses.ThaiName,
 				EnglishCourseName: oc.AllCourses.EnglishName,
 				Credit:            credit,
 				TypeOfCourse:      oc.AllCourses.TypeOfCourses.TypeName,
 				TotalSections:     oc.Section,
 				Laboratory:        lab,
-				Sections: []SectionDetail{
-					{
-						SectionNumber:   oc.Section,
-						Room:            lab,
-						Capacity:        oc.Capacity,
-						InstructorNames: instructors,
-					},
-				},
+				Sections:          placeholderSections(oc, sections, lab, instructors),
 			}
 		}
 
@@ -701,12 +826,13 @@ func GetOfferedCoursesAndSchedulebyID(c *gin.Context) {
 					key := fmt.Sprintf("%d-%s-%s", tf.Section, tf.DayOfWeek, tf.StartTime)
 					sectionMap[key] = SectionDetail{
 						ID:              tf.ID,
+						SectionID:       sections[tf.Section].ID,
 						SectionNumber:   tf.Section,
 						Room:            tf.RoomFix,
 						DayOfWeek:       tf.DayOfWeek,
 						Time:            tf.StartTime.Format("15:04") + " - " + tf.EndTime.Format("15:04"),
 						Capacity:        tf.Capacity,
-						InstructorNames: instructors,
+						InstructorNames: sectionInstructorNames(oc, tf.Section, instructors),
 					}
 				}
 			}
@@ -715,12 +841,14 @@ func GetOfferedCoursesAndSchedulebyID(c *gin.Context) {
 				key := fmt.Sprintf("%d-%s-%s", sch.SectionNumber, sch.DayOfWeek, sch.StartTime)
 				sectionMap[key] = SectionDetail{
 					ID:              sch.ID,
+					SectionID:       sections[sch.SectionNumber].ID,
 					SectionNumber:   sch.SectionNumber,
-					Room:            room,
+					Room:            scheduleRoomName(sch, sectionRoomName(sections[sch.SectionNumber], room)),
 					DayOfWeek:       sch.DayOfWeek,
 					Time:            sch.StartTime.Format("15:04") + " - " + sch.EndTime.Format("15:04"),
-					Capacity:        oc.Capacity,
-					InstructorNames: instructors,
+					Kind:            sch.Kind,
+					Capacity:        sectionCapacity(sections[sch.SectionNumber], oc.Capacity),
+					InstructorNames: sectionInstructorNames(oc, sch.SectionNumber, instructors),
 				}
 			}
 		}
@@ -769,7 +897,7 @@ func GetOpenCoursesByFilters(c *gin.Context) {
 	}
 
 	if majorIDStr == "" || deptIDStr == "" || tocIDStr == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "ต้องส่ง major_id, department_id, toc_id"})
+		respondError(c, http.StatusBadRequest, "ต้องส่ง major_id, department_id, toc_id")
 		return
 	}
 
@@ -777,7 +905,7 @@ func GetOpenCoursesByFilters(c *gin.Context) {
 	deptID, err2 := strconv.Atoi(deptIDStr)
 	tocID, err3 := strconv.Atoi(tocIDStr)
 	if err1 != nil || err2 != nil || err3 != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "id ทั้งหมดต้องเป็นตัวเลข"})
+		respondError(c, http.StatusBadRequest, "id ทั้งหมดต้องเป็นตัวเลข")
 		return
 	}
 
@@ -791,7 +919,7 @@ func GetOpenCoursesByFilters(c *gin.Context) {
             WHERE m.id = ? AND d.id = ? AND toc.id = ?`
 	var results []CourseItem
 	if err := config.DB().Raw(sql, majorID, deptID, tocID).Scan(&results).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondServerError(c, http.StatusInternalServerError, "ไม่สามารถดึงรายวิชาได้", err)
 		return
 	}
 	c.JSON(http.StatusOK, results)
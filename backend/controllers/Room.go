@@ -0,0 +1,223 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Nichakorn25/CPE-Teaching-Schedule/config"
+	"github.com/Nichakorn25/CPE-Teaching-Schedule/entity"
+)
+
+type roomInput struct {
+	Name      string   `json:"name"     binding:"required"`
+	Building  string   `json:"building" binding:"required"`
+	RoomType  string   `json:"roomType" binding:"required,oneof=lecture_hall lab computer_room"`
+	Capacity  uint     `json:"capacity" binding:"required,gt=0"`
+	Equipment []string `json:"equipment"`
+}
+
+func roomData(room entity.Room) gin.H {
+	return gin.H{
+		"id":           room.ID,
+		"name":         room.Name,
+		"building":     room.Building,
+		"roomType":     room.RoomType,
+		"capacity":     room.Capacity,
+		"equipment":    splitEquipment(room.Equipment),
+		"laboratoryId": room.LaboratoryID,
+	}
+}
+
+// joinEquipment เก็บแท็กอุปกรณ์เป็นตัวพิมพ์เล็กคั่นด้วย comma ตัดค่าว่าง/ซ้ำ
+func joinEquipment(tags []string) string {
+	seen := map[string]bool{}
+	out := make([]string, 0, len(tags))
+	for _, t := range tags {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if t == "" || seen[t] {
+			continue
+		}
+		seen[t] = true
+		out = append(out, t)
+	}
+	return strings.Join(out, ",")
+}
+
+func splitEquipment(s string) []string {
+	if s == "" {
+		return []string{}
+	}
+	return strings.Split(s, ",")
+}
+
+// GET /rooms?type=lecture_hall&min_capacity=40&equipment=projector
+func GetRooms(c *gin.Context) {
+	db := config.DB().Order("building, name")
+
+	if t := c.Query("type"); t != "" {
+		db = db.Where("room_type = ?", t)
+	}
+	if v := c.Query("min_capacity"); v != "" {
+		n, err := strconv.ParseUint(v, 10, 32)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, "min_capacity ต้องเป็นตัวเลข")
+			return
+		}
+		db = db.Where("capacity >= ?", n)
+	}
+	if eq := strings.ToLower(strings.TrimSpace(c.Query("equipment"))); eq != "" {
+		db = db.Where("(',' || equipment || ',') LIKE ?", "%,"+eq+",%")
+	}
+
+	var rooms []entity.Room
+	if err := db.Find(&rooms).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, "ไม่สามารถดึงข้อมูลห้องเรียนได้")
+		return
+	}
+
+	resp := make([]gin.H, 0, len(rooms))
+	for _, r := range rooms {
+		resp = append(resp, roomData(r))
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func GetRoomByID(c *gin.Context) {
+	var room entity.Room
+	if err := config.DB().First(&room, c.Param("id")).Error; err != nil {
+		respondError(c, http.StatusNotFound, "ไม่พบห้องเรียน")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "ดึงข้อมูลห้องเรียนสำเร็จ", "data": roomData(room)})
+}
+
+func CreateRoom(c *gin.Context) {
+	var in roomInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		respondError(c, http.StatusBadRequest, "ข้อมูลไม่ถูกต้อง")
+		return
+	}
+
+	var dupe int64
+	config.DB().Model(&entity.Room{}).
+		Where("name = ? AND building = ?", in.Name, in.Building).
+		Count(&dupe)
+	if dupe > 0 {
+		respondError(c, http.StatusConflict, "ห้องนี้ในอาคารนี้มีอยู่แล้ว")
+		return
+	}
+
+	room := entity.Room{
+		Name:      in.Name,
+		Building:  in.Building,
+		RoomType:  in.RoomType,
+		Capacity:  in.Capacity,
+		Equipment: joinEquipment(in.Equipment),
+	}
+	if !validateEntity(c, room) {
+		return
+	}
+	if err := config.DB().Create(&room).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, "ไม่สามารถสร้างห้องเรียนได้")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "สร้างห้องเรียนสำเร็จ", "data": roomData(room)})
+}
+
+func UpdateRoom(c *gin.Context) {
+	var room entity.Room
+	if err := config.DB().First(&room, c.Param("id")).Error; err != nil {
+		respondError(c, http.StatusNotFound, "ไม่พบห้องเรียน")
+		return
+	}
+
+	var in roomInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		respondError(c, http.StatusBadRequest, "ข้อมูลไม่ถูกต้อง")
+		return
+	}
+
+	// ห้องที่มาจาก Laboratory ให้แก้ไขผ่านหน้าห้องปฏิบัติการ เพื่อให้ข้อมูลสองฝั่งตรงกัน
+	if room.LaboratoryID != nil {
+		respondError(c, http.StatusConflict, "ห้องนี้ผูกกับห้องปฏิบัติการ กรุณาแก้ไขที่ข้อมูลห้องปฏิบัติการ")
+		return
+	}
+
+	var dupe int64
+	config.DB().Model(&entity.Room{}).
+		Where("name = ? AND building = ? AND id <> ?", in.Name, in.Building, room.ID).
+		Count(&dupe)
+	if dupe > 0 {
+		respondError(c, http.StatusConflict, "ห้องนี้ในอาคารนี้มีอยู่แล้ว")
+		return
+	}
+
+	room.Name = in.Name
+	room.Building = in.Building
+	room.RoomType = in.RoomType
+	room.Capacity = in.Capacity
+	room.Equipment = joinEquipment(in.Equipment)
+
+	if !validateEntity(c, room) {
+		return
+	}
+	if err := config.DB().Save(&room).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, "ไม่สามารถอัปเดตห้องเรียนได้")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "อัปเดตห้องเรียนสำเร็จ", "data": roomData(room)})
+}
+
+func DeleteRoom(c *gin.Context) {
+	var room entity.Room
+	if err := config.DB().First(&room, c.Param("id")).Error; err != nil {
+		respondError(c, http.StatusNotFound, "ไม่พบห้องเรียน")
+		return
+	}
+	if room.LaboratoryID != nil {
+		respondError(c, http.StatusConflict, "ห้องนี้ผูกกับห้องปฏิบัติการ กรุณาลบที่ข้อมูลห้องปฏิบัติการ")
+		return
+	}
+
+	// คาบสอนและกลุ่มเรียนที่ใช้ห้องนี้ต้องขอ cascade (จะถูกตัดการอ้างอิงห้อง)
+	plan, ok := deleteWithDependents(c, config.KindRoom, room.ID, "ไม่สามารถลบห้องเรียนได้")
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "ลบห้องเรียนสำเร็จ", "data": gin.H{"id": room.ID}, "cascaded": plan.Cascaded, "detached": plan.Detached})
+}
+
+// RestoreRoom กู้คืนห้องเรียนที่ถูกลบ
+func RestoreRoom(c *gin.Context) {
+	restoreDeleted(c, config.KindRoom)
+}
+
+// POST /rooms/resolve-fixed จับคู่ RoomFix ที่ยังไม่ผูกห้องกับแคตตาล็อกอีกครั้ง
+func ResolveFixedCourseRooms(c *gin.Context) {
+	unresolved, err := config.ResolveFixedCourseRooms()
+	if err != nil {
+		respondServerError(c, http.StatusInternalServerError, "ไม่สามารถจับคู่ห้องเรียนได้", err)
+		return
+	}
+
+	items := make([]gin.H, 0, len(unresolved))
+	for _, tf := range unresolved {
+		items = append(items, gin.H{
+			"id":           tf.ID,
+			"allCoursesId": tf.AllCoursesID,
+			"section":      tf.Section,
+			"roomFix":      tf.RoomFix,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "จับคู่ห้องเรียนเรียบร้อย",
+		"unresolved": items,
+	})
+}
@@ -0,0 +1,234 @@
+package controllers
+
+import (
+	"net/http"
+	"reflect"
+	"runtime"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Nichakorn25/CPE-Teaching-Schedule/services"
+)
+
+type apiRoute struct {
+	services.APIRoute
+	handler gin.HandlerFunc
+}
+
+// route เส้นทาง /api/v1 พร้อมเส้นทางเดิม (legacy) ที่ชี้มาที่ handler เดียวกัน
+// POST/PUT/PATCH ถือว่ารับ JSON body ยกเว้นระบุ noBody
+func route(method, path string, h gin.HandlerFunc, tag, summary string, legacy ...string) apiRoute {
+	name := runtime.FuncForPC(reflect.ValueOf(h).Pointer()).Name()
+	return apiRoute{
+		APIRoute: services.APIRoute{
+			Method:      method,
+			Path:        path,
+			Legacy:      legacy,
+			Tag:         tag,
+			Summary:     summary,
+			OperationID: name[strings.LastIndex(name, ".")+1:],
+			Body:        method == http.MethodPost || method == http.MethodPut || method == http.MethodPatch,
+		},
+		handler: h,
+	}
+}
+
+func (r apiRoute) query(names ...string) apiRoute {
+	r.Query = names
+	return r
+}
+
+func (r apiRoute) list(spec services.ListSpec) apiRoute {
+	r.List = &spec
+	return r
+}
+
+func (r apiRoute) noBody() apiRoute {
+	r.Body = false
+	return r
+}
+
+var yearTerm = []string{"year", "term"}
+
+func apiRoutes() []apiRoute {
+	const (
+		get, post, put, patch, del = http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete
+	)
+	return []apiRoute{
+		///////////////////// Auth /////////////////////////
+		route(post, "/auth/signin", SignInUser, "Auth", "เข้าสู่ระบบ", "/signin"),
+		route(patch, "/auth/password", ChangePassword, "Auth", "เปลี่ยนรหัสผ่าน", "/change-password"),
+
+		///////////////////// Courses /////////////////////////
+		route(get, "/courses", GetAllCourses, "Courses", "รายวิชาทั้งหมด", "/all-courses").list(allCoursesListSpec),
+		route(post, "/courses", CreateCourses, "Courses", "เพิ่มรายวิชา", "/courses"),
+		route(get, "/courses/:id", GetAllCourseByID, "Courses", "รายวิชาตาม ID", "/all-courses/:id"),
+		route(put, "/courses/:id", UpdateAllCourses, "Courses", "แก้ไขรายวิชา", "/update-courses/:id"),
+		route(del, "/courses/:id", DeleteAllCourses, "Courses", "ลบรายวิชา", "/delete-courses/:id").query("cascade"),
+		route(post, "/courses/:id/restore", RestoreAllCourses, "Courses", "กู้คืนรายวิชาที่ถูกลบ").noBody(),
+		route(get, "/courses/:id/hours", GetCourseHours, "Courses", "ชั่วโมงสอนต่อสัปดาห์ของรายวิชา", "/all-courses/:id/hours"),
+		route(get, "/courses/:id/prerequisites", GetCourseRequisites, "Courses", "วิชาบังคับก่อน/เรียนร่วม", "/all-courses/:id/prerequisites"),
+		route(post, "/courses/:id/prerequisites", CreateCourseRequisite, "Courses", "เพิ่มวิชาบังคับก่อน/เรียนร่วม", "/all-courses/:id/prerequisites"),
+		route(del, "/courses/:id/prerequisites/:requiresID", DeleteCourseRequisite, "Courses", "ลบวิชาบังคับก่อน/เรียนร่วม", "/all-courses/:id/prerequisites/:requiresID"),
+		route(get, "/courses/:id/equivalents", GetCourseEquivalents, "Courses", "รายวิชาเทียบเท่า", "/all-courses/:id/equivalents"),
+		route(post, "/courses/:id/equivalents", CreateCourseEquivalence, "Courses", "เพิ่มรายวิชาเทียบเท่า", "/all-courses/:id/equivalents"),
+		route(del, "/courses/:id/equivalents/:equivalentID", DeleteCourseEquivalence, "Courses", "ลบรายวิชาเทียบเท่า", "/all-courses/:id/equivalents/:equivalentID"),
+		route(get, "/course-mappings", GetCourseMappings, "Courses", "ตารางเทียบรายวิชาระหว่างหลักสูตร", "/course-mappings"),
+		route(post, "/course-mappings", CreateCourseMapping, "Courses", "เพิ่มการเทียบรายวิชา", "/course-mappings"),
+		route(del, "/course-mappings/:id", DeleteCourseMapping, "Courses", "ลบการเทียบรายวิชา", "/course-mappings/:id"),
+		route(get, "/credit-hour-rules", GetCreditHourRules, "Courses", "กติกาแปลงหน่วยกิตเป็นชั่วโมงสอน", "/credit-hour-rules"),
+		route(post, "/credit-hour-rules", CreateCreditHourRule, "Courses", "เพิ่มกติกาชั่วโมงสอน", "/credit-hour-rules"),
+		route(put, "/credit-hour-rules/:id", UpdateCreditHourRule, "Courses", "แก้ไขกติกาชั่วโมงสอน", "/credit-hour-rules/:id"),
+		route(del, "/credit-hour-rules/:id", DeleteCreditHourRule, "Courses", "ลบกติกาชั่วโมงสอน", "/credit-hour-rules/:id"),
+
+		///////////////////// Users /////////////////////////
+		route(get, "/instructors", GetTeachers, "Users", "ผู้สอนสำหรับตัวเลือก", "/all-instructor"),
+		route(get, "/users", GetAllTeachers, "Users", "รายชื่อผู้ใช้", "/all-teachers").list(teachersListSpec),
+		route(post, "/users", CreateUser, "Users", "เพิ่มผู้ใช้", "/users"),
+		route(get, "/users/:id", GetUserByID, "Users", "ผู้ใช้ตาม ID", "/users/:id"),
+		route(put, "/users/:id", UpdateUser, "Users", "แก้ไขผู้ใช้", "/update-users/:id"),
+		route(del, "/users/:id", DeleteUser, "Users", "ลบผู้ใช้", "/delete-users/:id").query("cascade"),
+		route(post, "/users/:id/restore", RestoreUser, "Users", "กู้คืนผู้ใช้ที่ถูกลบ").noBody(),
+
+		///////////////////// OfferedCourses /////////////////////////
+		route(get, "/offered-courses", GetOpenCourses, "OfferedCourses", "รายวิชาที่เปิดสอน", "/open-courses").list(offeredListSpec),
+		route(post, "/offered-courses", CreateOfferedCourse, "OfferedCourses", "เปิดสอนรายวิชา", "/offered-courses"),
+		route(get, "/offered-courses/records", GetOffered, "OfferedCourses", "ข้อมูลรายวิชาที่เปิดสอนแบบ entity พร้อมจำนวน", "/offered").list(offeredListSpec),
+		route(get, "/offered-courses/count", GetCountAllOffered, "OfferedCourses", "จำนวนรายวิชาที่เปิดสอน", "/all-count-offered").query(yearTerm...),
+		route(get, "/offered-courses/schedules", GetOfferedCoursesAndSchedule, "OfferedCourses", "รายวิชาที่เปิดสอนพร้อมตารางสอน", "/offered-courses-schedule").query("major_name", "year", "term"),
+		route(get, "/offered-courses/filter/:major_id/:department_id/:toc_id", GetOpenCoursesByFilters, "OfferedCourses", "รายวิชาตามสาขา ภาควิชา และประเภท", "/offered-course-filter/:major_id/:department_id/:toc_id"),
+		route(put, "/offered-courses/:id", UpdateOfferedCourse, "OfferedCourses", "แก้ไขรายวิชาที่เปิดสอน", "/offered-courses/:id"),
+		route(del, "/offered-courses/:id", DeleteOfferedCourse, "OfferedCourses", "ลบรายวิชาที่เปิดสอน", "/delete-offered-courses/:id").query("cascade"),
+		route(post, "/offered-courses/:id/restore", RestoreOfferedCourse, "OfferedCourses", "กู้คืนรายวิชาที่เปิดสอนที่ถูกลบ").noBody(),
+		route(get, "/offered-courses/:id/schedules", GetOfferedCoursesAndSchedulebyID, "OfferedCourses", "ตารางสอนของรายวิชาที่เปิดสอน", "/offered-courses-schedule/:id").query("major_name", "year", "term"),
+		route(get, "/offered-courses/:id/instructors", GetSectionInstructors, "OfferedCourses", "ผู้สอนรายกลุ่ม", "/offered-courses/:id/instructors"),
+		route(put, "/offered-courses/:id/instructors", UpdateSectionInstructors, "OfferedCourses", "กำหนดผู้สอนรายกลุ่ม", "/offered-courses/:id/instructors"),
+		route(put, "/offered-courses/:id/aliases", UpdateOfferedCourseAliases, "OfferedCourses", "รายวิชาเทียบเท่าที่เปิดสอนร่วม", "/offered-courses/:id/aliases"),
+		route(get, "/offered-courses/:id/sections", GetSections, "OfferedCourses", "กลุ่มเรียนของรายวิชา", "/offered-courses/:id/sections"),
+		route(post, "/fixed-courses", CreateFixedCourse, "OfferedCourses", "เพิ่มวิชาที่มาจากศูนย์บริการ", "/offered-courses/fixed"),
+		route(put, "/fixed-courses/:id", UpdateFixedCourse, "OfferedCourses", "แก้ไขวิชาที่มาจากศูนย์บริการ", "/up-fixed/:id"),
+
+		///////////////////// Sections /////////////////////////
+		route(put, "/sections/teaching-assistants", UpdateTeachingAssistants, "Sections", "กำหนดผู้ช่วยสอนของกลุ่มเรียน", "/update-teaching-assistants"),
+		route(put, "/sections/:id", UpdateSection, "Sections", "แก้ไขกลุ่มเรียน", "/sections/:id"),
+		route(put, "/sections/:id/student-groups", UpdateSectionStudentGroups, "Sections", "กำหนดกลุ่มนักศึกษาของกลุ่มเรียน", "/sections/:id/student-groups"),
+		route(del, "/sections/:sectionID/teaching-assistants/:taID", RemoveTeachingAssistant, "Sections", "นำผู้ช่วยสอนออกจากกลุ่มเรียน", "/remove-teaching-assistant/:sectionID/:taID"),
+
+		///////////////////// Conditions /////////////////////////
+		route(get, "/conditions", GetAllCondition, "Conditions", "เงื่อนไขเวลาที่ไม่ว่างของผู้สอนทั้งหมด", "/conditions").list(conditionListSpec),
+		route(post, "/conditions", CreateConditions, "Conditions", "เพิ่มเงื่อนไขเวลาที่ไม่ว่าง", "/condition"),
+		route(put, "/conditions", UpdateConditions, "Conditions", "แทนที่เงื่อนไขเวลาที่ไม่ว่าง", "/update-conditions"),
+		route(get, "/conditions/:userID", GetConditionByuserID, "Conditions", "เงื่อนไขของผู้สอน", "/conditions/user/:userID"),
+		route(del, "/conditions/:userID", DeleteConditionsByUser, "Conditions", "ลบเงื่อนไขของผู้สอน", "/conditions-user/:userID"),
+		route(get, "/conditions/:userID/preferences", GetConditionPreferences, "Conditions", "ช่วงเวลาที่ผู้สอนต้องการ", "/conditions/user/:userID/preferences"),
+		route(put, "/conditions/:userID/preferences", UpdateConditionPreferences, "Conditions", "แก้ไขช่วงเวลาที่ผู้สอนต้องการ", "/conditions/user/:userID/preferences"),
+		route(get, "/conditions/:userID/ics", GetInstructorICS, "Conditions", "ตารางสอนของผู้สอนแบบ iCalendar", "/conditions/user/:userID/ics").query(yearTerm...),
+		route(get, "/term-calendars/:year/:term", GetTermCalendar, "Conditions", "วันเปิด-ปิดภาคเรียน", "/term-calendars/:year/:term"),
+		route(put, "/term-calendars/:year/:term", UpdateTermCalendar, "Conditions", "กำหนดวันเปิด-ปิดภาคเรียน", "/term-calendars/:year/:term"),
+
+		///////////////////// TeachingAssistants /////////////////////////
+		route(get, "/teaching-assistants", GetAllTeachingAssistants, "TeachingAssistants", "ผู้ช่วยสอนทั้งหมด", "/all-teaching-assistants").list(teachingAssistantListSpec),
+		route(post, "/teaching-assistants", CreateTeachingAssistant, "TeachingAssistants", "เพิ่มผู้ช่วยสอน", "/create-teaching-assistants"),
+		route(get, "/teaching-assistants/:id", GetTeachingAssistantByID, "TeachingAssistants", "ผู้ช่วยสอนตาม ID", "/teaching-assistants/:id"),
+		route(put, "/teaching-assistants/:id", UpdateTeachingAssistant, "TeachingAssistants", "แก้ไขผู้ช่วยสอน", "/update-teaching-assistants/:id"),
+		route(del, "/teaching-assistants/:id", DeleteTeachingAssistant, "TeachingAssistants", "ลบผู้ช่วยสอน", "/delete-teaching-assistants/:id").query("cascade"),
+		route(post, "/teaching-assistants/:id/restore", RestoreTeachingAssistant, "TeachingAssistants", "กู้คืนผู้ช่วยสอนที่ถูกลบ").noBody(),
+		route(get, "/teaching-assistants/:id/availability", GetTAAvailability, "TeachingAssistants", "ช่วงเวลาว่างของผู้ช่วยสอน", "/teaching-assistants/:id/availability"),
+		route(put, "/teaching-assistants/:id/availability", UpdateTAAvailability, "TeachingAssistants", "กำหนดช่วงเวลาว่างของผู้ช่วยสอน", "/teaching-assistants/:id/availability"),
+		route(post, "/schedule-teaching-assistants", CreateScheduleTeachingAssistant, "TeachingAssistants", "เพิ่มผู้ช่วยสอนให้คาบสอน", "/ScheduleTeachingAssistants"),
+		route(post, "/schedule-teaching-assistants/assign", AssignTAToSchedule, "TeachingAssistants", "มอบหมายผู้ช่วยสอนให้รายวิชาในตาราง", "/assign-ta-to-schedule").query("debug"),
+		route(post, "/schedule-teaching-assistants/auto-assign", AutoAssignTA, "TeachingAssistants", "จัดผู้ช่วยสอนอัตโนมัติ", "/auto-assign-ta"),
+
+		///////////////////// Schedules /////////////////////////
+		route(get, "/schedules", GetScheduleByNameTable, "Schedules", "ตารางสอนของเทอม", "/schedules").query("major_name", "year", "term"),
+		route(post, "/schedules/generate", AutoGenerateSchedule, "Schedules", "สร้างตารางสอนอัตโนมัติ", "/auto-generate-schedule").query("major_name", "year", "term").noBody(),
+		route(put, "/schedules/:id", UpdateScheduleTime, "Schedules", "ย้ายคาบสอน", "/up-schedule/:id"),
+		route(get, "/schedule-tables", GetNameTable, "Schedules", "ชื่อตารางสอนทั้งหมด", "/unique-nametables"),
+		route(del, "/schedule-tables/:nameTable", DeleteScheduleByNameTable, "Schedules", "ลบตารางสอนทั้งตาราง", "/delete-schedule/:nameTable"),
+
+		///////////////////// Lookups /////////////////////////
+		route(get, "/course-types", GetTypeOfCourses, "Lookups", "ประเภทรายวิชา", "/course-type"),
+		route(get, "/titles", GetAllTitles, "Lookups", "คำนำหน้า", "/all-title"),
+		route(get, "/positions", GetAllPosition, "Lookups", "ตำแหน่ง", "/all-position"),
+		route(get, "/majors", GetAllMajorOfDepathment, "Lookups", "สาขา", "/all-majors"),
+		route(get, "/roles", GetAllRoles, "Lookups", "บทบาทผู้ใช้", "/all-roles"),
+		route(get, "/academic-years", GetAllAcademicYears, "Lookups", "ชั้นปี", "/all-academic-years"),
+		route(get, "/departments", GetAllDepartment, "Lookups", "ภาควิชา", "/all-department"),
+		route(get, "/departments/:id/time-grid", GetTimeGrid, "Lookups", "ช่วงเวลาจัดตารางของภาควิชา", "/departments/:id/time-grid"),
+		route(put, "/departments/:id/time-grid", UpdateTimeGrid, "Lookups", "กำหนดช่วงเวลาจัดตารางของภาควิชา", "/departments/:id/time-grid"),
+
+		///////////////////// StudentGroups /////////////////////////
+		route(get, "/student-groups", GetStudentGroups, "StudentGroups", "กลุ่มนักศึกษา", "/student-groups").query("major_id"),
+		route(post, "/student-groups", CreateStudentGroup, "StudentGroups", "เพิ่มกลุ่มนักศึกษา", "/student-groups"),
+		route(get, "/student-groups/:id", GetStudentGroupByID, "StudentGroups", "กลุ่มนักศึกษาตาม ID", "/student-groups/:id"),
+		route(put, "/student-groups/:id", UpdateStudentGroup, "StudentGroups", "แก้ไขกลุ่มนักศึกษา", "/student-groups/:id"),
+		route(del, "/student-groups/:id", DeleteStudentGroup, "StudentGroups", "ลบกลุ่มนักศึกษา", "/student-groups/:id"),
+		route(get, "/student-groups/:id/timetable", GetStudentGroupTimetable, "StudentGroups", "ตารางเรียนของกลุ่มนักศึกษา", "/student-groups/:id/timetable").query(yearTerm...),
+
+		///////////////////// Curricula /////////////////////////
+		route(get, "/curricula", GetAllCurriculum, "Curricula", "หลักสูตรทั้งหมด", "/all-curriculum"),
+		route(post, "/curricula", CreateCurriculum, "Curricula", "เพิ่มหลักสูตร", "/curriculum"),
+		route(get, "/curricula/compare", CompareCurricula, "Curricula", "เปรียบเทียบรายวิชาระหว่างสองหลักสูตร", "/curriculum-compare").query("from", "to"),
+		route(get, "/curricula/:id", GetCurriculumById, "Curricula", "หลักสูตรตาม ID", "/curriculum/:id"),
+		route(put, "/curricula/:id", UpdateCurriculum, "Curricula", "แก้ไขหลักสูตร", "/curriculum/:id"),
+		route(get, "/curricula/:id/graph", GetCurriculumGraph, "Curricula", "กราฟวิชาบังคับก่อนของหลักสูตร", "/curriculum/:id/graph"),
+		route(get, "/curricula/:id/study-plan", GetStudyPlan, "Curricula", "แผนการศึกษา", "/curriculum/:id/study-plan"),
+		route(put, "/curricula/:id/study-plan", UpdateStudyPlan, "Curricula", "แก้ไขแผนการศึกษา", "/curriculum/:id/study-plan"),
+		route(post, "/curricula/:id/courses", DuplicateAllCoursesIntoCurriculum, "Curricula", "คัดลอกรายวิชาจากหลักสูตรอื่น", "/curriculum-into-allcourse/:id"),
+
+		///////////////////// Rooms /////////////////////////
+		route(get, "/laboratories", GetLaboratory, "Rooms", "ห้องปฏิบัติการทั้งหมด", "/all-laboratory"),
+		route(post, "/laboratories", CreateLaboratory, "Rooms", "เพิ่มห้องปฏิบัติการ", "/lab"),
+		route(get, "/laboratories/free", GetFreeLaboratories, "Rooms", "ห้องปฏิบัติการที่ว่าง", "/free-labs").query("name_table", "day", "start", "end", "capacity"),
+		route(get, "/laboratories/:id", GetLaboratoryByID, "Rooms", "ห้องปฏิบัติการตาม ID", "/lab/:id"),
+		route(put, "/laboratories/:id", UpdateLaboratory, "Rooms", "แก้ไขห้องปฏิบัติการ", "/lab/:id"),
+		route(del, "/laboratories/:id", DeleteLaboratory, "Rooms", "ลบห้องปฏิบัติการ", "/lab/:id").query("cascade"),
+		route(post, "/laboratories/:id/restore", RestoreLaboratory, "Rooms", "กู้คืนห้องปฏิบัติการที่ถูกลบ").noBody(),
+		route(get, "/rooms", GetRooms, "Rooms", "ห้องเรียนทั้งหมด", "/rooms").query("type", "min_capacity", "equipment"),
+		route(post, "/rooms", CreateRoom, "Rooms", "เพิ่มห้องเรียน", "/rooms"),
+		route(post, "/rooms/resolve-fixed", ResolveFixedCourseRooms, "Rooms", "จับคู่ห้องของวิชาจากศูนย์บริการกับแคตตาล็อกห้อง", "/rooms/resolve-fixed").noBody(),
+		route(get, "/rooms/:id", GetRoomByID, "Rooms", "ห้องเรียนตาม ID", "/rooms/:id"),
+		route(put, "/rooms/:id", UpdateRoom, "Rooms", "แก้ไขห้องเรียน", "/rooms/:id"),
+		route(del, "/rooms/:id", DeleteRoom, "Rooms", "ลบห้องเรียน", "/rooms/:id").query("cascade"),
+		route(post, "/rooms/:id/restore", RestoreRoom, "Rooms", "กู้คืนห้องเรียนที่ถูกลบ").noBody(),
+
+		///////////////////// Reports /////////////////////////
+		route(get, "/reports/teaching-load", GetTeachingLoad, "Reports", "ภาระงานสอน", "/teaching-load").query("year", "term", "major_name", "max_hours", "min_hours"),
+		route(get, "/reports/preferences", GetPreferenceReport, "Reports", "รายงานความต้องการด้านเวลาของผู้สอน", "/preference-report").query(yearTerm...),
+		route(get, "/reports/condition-conflicts", GetConditionConflicts, "Reports", "คาบสอนที่ทับเงื่อนไขเวลาที่ไม่ว่าง", "/condition-conflicts").query(yearTerm...),
+		route(get, "/reports/lab-utilization", GetLaboratoryUtilization, "Reports", "การใช้ห้องปฏิบัติการ", "/lab-utilization").query("name_table", "major_name"),
+		route(get, "/reports/lab-capacity-mismatches", GetLaboratoryCapacityMismatches, "Reports", "กลุ่มเรียนที่ที่นั่งเกินความจุห้องปฏิบัติการ", "/lab-capacity-mismatches").query(yearTerm...),
+		route(get, "/reports/ta-timesheet", GetTATimesheet, "Reports", "ใบลงเวลาผู้ช่วยสอน", "/ta-timesheet").query("year", "term", "ta_id", "format"),
+
+		///////////////////// Search /////////////////////////
+		route(get, "/search", Search, "Search", "ค้นหารายวิชา ผู้สอน ผู้ช่วยสอน และห้องเรียน", "/search").query("q", "limit", "types"),
+	}
+}
+
+// APIRoutes ตารางเส้นทางทั้งหมดของ /api/v1 (ไม่รวม handler) สำหรับสร้างเอกสารและตรวจสอบ
+func APIRoutes() []services.APIRoute {
+	routes := apiRoutes()
+	out := make([]services.APIRoute, len(routes))
+	for i, rt := range routes {
+		out[i] = rt.APIRoute
+	}
+	return out
+}
+
+// RegisterRoutes ลงทะเบียน /api/v1 เอกสาร OpenAPI และเส้นทางเดิมที่หน้าเว็บยังเรียกอยู่
+func RegisterRoutes(r gin.IRouter) {
+	routes := apiRoutes()
+	v1 := r.Group(services.APIVersionPrefix)
+	for _, rt := range routes {
+		v1.Handle(rt.Method, rt.Path, rt.handler)
+		for _, legacy := range rt.Legacy {
+			r.Handle(rt.Method, legacy, rt.handler)
+		}
+	}
+
+	doc := services.OpenAPIDocument(APIRoutes())
+	v1.GET("/openapi.json", func(c *gin.Context) {
+		c.JSON(http.StatusOK, doc)
+	})
+}
@@ -11,7 +11,7 @@ import (
 
 // {
 //   "TeachingAssistantID": 1,
-//   "ScheduleID": 1
+//   "SectionID": 1
 // }
 
 // POST /ScheduleTeachingAssistants
@@ -20,28 +20,80 @@ func CreateScheduleTeachingAssistant(c *gin.Context) {
 
 	// รับข้อมูล JSON จาก client
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBindError(c, err)
 		return
 	}
 
 	db := config.DB()
 
-	// สร้าง ScheduleTeachingAssistant object
+	// ผู้ช่วยสอนผูกกับกลุ่มเรียน ถ้าส่งมาเป็น ScheduleID ให้หา Section ของคาบนั้น
+	sectionID := input.SectionID
+	if sectionID == nil && input.ScheduleID != nil {
+		var schedule entity.Schedule
+		if err := db.First(&schedule, *input.ScheduleID).Error; err != nil {
+			respondError(c, http.StatusNotFound, "ไม่พบตารางสอน")
+			return
+		}
+		sectionID = schedule.SectionID
+		if sectionID == nil {
+			sectionID = config.SectionIDOf(schedule.OfferedCoursesID, schedule.SectionNumber)
+		}
+	}
+	if sectionID == nil {
+		respondError(c, http.StatusBadRequest, "ต้องระบุ SectionID หรือ ScheduleID")
+		return
+	}
+
 	scheduleTA := entity.ScheduleTeachingAssistant{
 		TeachingAssistantID: input.TeachingAssistantID,
-		ScheduleID:          input.ScheduleID,
+		SectionID:           sectionID,
+	}
+	if !validateEntity(c, scheduleTA) {
+		return
+	}
+
+	var existing int64
+	if err := db.Model(&entity.ScheduleTeachingAssistant{}).
+		Where("section_id = ? AND teaching_assistant_id = ?", *sectionID, input.TeachingAssistantID).
+		Count(&existing).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, "ไม่สามารถตรวจสอบผู้ช่วยสอนของกลุ่มเรียนได้")
+		return
+	}
+	if existing > 0 {
+		respondError(c, http.StatusConflict, "ผู้ช่วยสอนคนนี้อยู่ในกลุ่มเรียนนี้แล้ว")
+		return
+	}
+
+	// ผู้ช่วยสอนห้ามมีคาบชนกันในตารางเดียวกัน นอกเวลาว่าง/เกินชั่วโมงแจ้งเตือนเท่านั้น
+	warnings := []TAAssignmentIssue{}
+	book, err := loadTABookForSection(*sectionID)
+	if err != nil {
+		respondServerError(c, http.StatusInternalServerError, "โหลดข้อมูลผู้ช่วยสอนไม่สำเร็จ", err)
+		return
+	}
+	if book != nil {
+		var conflicts []TAAssignmentIssue
+		conflicts, warnings = book.checkAll([]uint{input.TeachingAssistantID}, []uint{*sectionID})
+		if len(conflicts) > 0 {
+			respondError(c, http.StatusConflict, "ผู้ช่วยสอนมีคาบชนกับกลุ่มเรียนอื่น", gin.H{"conflicts": conflicts})
+			return
+		}
 	}
 
 	// บันทึกลงฐานข้อมูล
-	if err := db.Create(&scheduleTA).Error; err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := db.Create(&scheduleTA).Error; config.IsDuplicateKey(err) {
+		respondError(c, http.StatusConflict, "ผู้ช่วยสอนคนนี้อยู่ในกลุ่มเรียนนี้แล้ว")
+		return
+	} else if err != nil {
+		respondError(c, http.StatusInternalServerError, "ไม่สามารถเพิ่มผู้ช่วยสอนได้")
 		return
 	}
 
 	// ตอบกลับ
 	c.JSON(http.StatusCreated, gin.H{
-		"message": "ScheduleTeachingAssistant created successfully",
-		"data":    scheduleTA,
+		"message":  "ScheduleTeachingAssistant created successfully",
+		"data":     scheduleTA,
+		"warnings": warnings,
 	})
 }
 
@@ -97,7 +149,7 @@ type AssignTA struct {
 func AssignTAToSchedule(c *gin.Context) {
     var req AssignTA
     if err := c.ShouldBindJSON(&req); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "invalid body", "details": err.Error()})
+        respondBindError(c, err)
         return
     }
 
@@ -112,7 +164,7 @@ func AssignTAToSchedule(c *gin.Context) {
         db = config.DB()
     }
     if db == nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "db not found"})
+        respondError(c, http.StatusInternalServerError, "ไม่สามารถเชื่อมต่อฐานข้อมูลได้")
         return
     }
     // เปิดโหมด debug จาก query ได้ เช่น ?debug=1
@@ -125,18 +177,18 @@ func AssignTAToSchedule(c *gin.Context) {
     if err := db.
         Where("offered_courses_id = ? AND name_table = ?", req.OfferedCoursesID, req.NameTable).
         Find(&schedules).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to find schedules", "details": err.Error()})
+        respondServerError(c, http.StatusInternalServerError, "ค้นหาตารางสอนไม่สำเร็จ", err)
         return
     }
     if len(schedules) == 0 {
-        c.JSON(http.StatusNotFound, gin.H{"error": "no schedules found for this course and nameTable"})
+        respondError(c, http.StatusNotFound, "ไม่พบตารางสอนของรายวิชานี้ในตารางที่ระบุ")
         return
     }
 
     // 2) ตรวจ TA IDs มีจริงทั้งหมด (กัน FK ล้ม)
     var tas []entity.TeachingAssistant
     if err := db.Where("id IN ?", req.TeachingAssistantIDs).Find(&tas).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load teaching assistants", "details": err.Error()})
+        respondServerError(c, http.StatusInternalServerError, "โหลดข้อมูลผู้ช่วยสอนไม่สำเร็จ", err)
         return
     }
     if len(tas) != len(req.TeachingAssistantIDs) {
@@ -146,13 +198,28 @@ func AssignTAToSchedule(c *gin.Context) {
         for _, id := range req.TeachingAssistantIDs {
             if _, ok := exist[id]; !ok { missing = append(missing, id) }
         }
-        c.JSON(http.StatusBadRequest, gin.H{"error": "some teaching_assistant_ids do not exist", "missing": missing})
+        respondError(c, http.StatusBadRequest, "ไม่พบผู้ช่วยสอนบางรายการ", gin.H{"missing": missing})
         return
     }
 
-    // 3) เตรียมชุด pair และตัดซ้ำในคำขอเอง
-    scheduleIDs := make([]uint, 0, len(schedules))
-    for _, sc := range schedules { scheduleIDs = append(scheduleIDs, sc.ID) }
+    // 3) เตรียมชุดกลุ่มเรียนของคาบทั้งหมด และตัด TA ซ้ำในคำขอเอง (ผู้ช่วยสอนผูกกับกลุ่มเรียน ไม่ใช่รายคาบ)
+    sectionSet := map[uint]struct{}{}
+    sectionIDs := make([]uint, 0)
+    for _, sc := range schedules {
+        sid := sc.SectionID
+        if sid == nil {
+            sid = config.SectionIDOf(sc.OfferedCoursesID, sc.SectionNumber)
+        }
+        if sid == nil { continue }
+        if _, ok := sectionSet[*sid]; !ok {
+            sectionSet[*sid] = struct{}{}
+            sectionIDs = append(sectionIDs, *sid)
+        }
+    }
+    if len(sectionIDs) == 0 {
+        respondError(c, http.StatusNotFound, "ไม่พบกลุ่มเรียนของตารางสอนที่ระบุ")
+        return
+    }
 
     uniqTA := map[uint]struct{}{}
     taIDs := make([]uint, 0, len(req.TeachingAssistantIDs))
@@ -164,29 +231,30 @@ func AssignTAToSchedule(c *gin.Context) {
     }
 
     // 4) เช็คคู่ที่มีอยู่แล้ว เพื่อตัดซ้ำ (ไม่ใช้ clause)
-    type pair struct{ ScheduleID, TeachingAssistantID uint }
+    type pair struct{ SectionID, TeachingAssistantID uint }
     var existing []pair
     if err := db.
         Model(&entity.ScheduleTeachingAssistant{}).
-        Select("schedule_id, teaching_assistant_id").
-        Where("schedule_id IN ? AND teaching_assistant_id IN ?", scheduleIDs, taIDs).
+        Select("section_id, teaching_assistant_id").
+        Where("section_id IN ? AND teaching_assistant_id IN ?", sectionIDs, taIDs).
         Find(&existing).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check duplicates", "details": err.Error()})
+        respondServerError(c, http.StatusInternalServerError, "ตรวจสอบข้อมูลซ้ำไม่สำเร็จ", err)
         return
     }
-    has := map[uint]map[uint]struct{}{} // has[scheduleID][taID]
+    has := map[uint]map[uint]struct{}{} // has[sectionID][taID]
     for _, e := range existing {
-        if has[e.ScheduleID] == nil { has[e.ScheduleID] = map[uint]struct{}{} }
-        has[e.ScheduleID][e.TeachingAssistantID] = struct{}{}
+        if has[e.SectionID] == nil { has[e.SectionID] = map[uint]struct{}{} }
+        has[e.SectionID][e.TeachingAssistantID] = struct{}{}
     }
 
     // 5) เตรียม insert เฉพาะที่ยังไม่มี
-    toInsert := make([]entity.ScheduleTeachingAssistant, 0, len(scheduleIDs)*len(taIDs))
-    for _, sid := range scheduleIDs {
+    toInsert := make([]entity.ScheduleTeachingAssistant, 0, len(sectionIDs)*len(taIDs))
+    for _, sid := range sectionIDs {
         for _, tid := range taIDs {
             if _, ok := has[sid][tid]; !ok {
+                sectionID := sid
                 toInsert = append(toInsert, entity.ScheduleTeachingAssistant{
-                    ScheduleID:          sid,
+                    SectionID:           &sectionID,
                     TeachingAssistantID: tid,
                 })
             }
@@ -198,14 +266,27 @@ func AssignTAToSchedule(c *gin.Context) {
         return
     }
 
+    // 5.1) ตรวจผู้ช่วยสอนคาบชนข้ามกลุ่มใน NameTable เดียวกัน (ปฏิเสธ) และนอกเวลาว่าง/เกินชั่วโมง (แจ้งเตือน)
+    book, err := loadTABook(req.NameTable)
+    if err != nil {
+        respondServerError(c, http.StatusInternalServerError, "โหลดภาระงานผู้ช่วยสอนไม่สำเร็จ", err)
+        return
+    }
+    conflicts, warnings := book.checkAll(taIDs, sectionIDs)
+    if len(conflicts) > 0 {
+        respondError(c, http.StatusConflict, "ผู้ช่วยสอนมีคาบชนกับกลุ่มเรียนอื่น", gin.H{"conflicts": conflicts})
+        return
+    }
+
     // 6) บันทึก
     if err := db.Create(&toInsert).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "ไม่สามารถบันทึกผู้ช่วยสอนได้", "details": err.Error()})
+        respondServerError(c, http.StatusInternalServerError, "ไม่สามารถบันทึกผู้ช่วยสอนได้", err)
         return
     }
 
     c.JSON(http.StatusOK, gin.H{
         "message":       "บันทึกผู้ช่วยสอนสำเร็จ",
         "inserted_rows": len(toInsert),
+        "warnings":      warnings,
     })
 }
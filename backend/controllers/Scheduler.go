@@ -5,29 +5,36 @@ import (
 	"math/rand"
 	"net/http"
 	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 
 	"github.com/Nichakorn25/CPE-Teaching-Schedule/config"
 	"github.com/Nichakorn25/CPE-Teaching-Schedule/entity"
+	"github.com/Nichakorn25/CPE-Teaching-Schedule/services"
 )
 
+// lectureMinutesPerDay ชั่วโมงบรรยายรวมต่อวันต่อกลุ่มที่ตัวจัดตารางวางได้ (ค่าเดิม 3 ชม.)
+const lectureMinutesPerDay = 3 * 60
+
 func getDayName(index int) string {
-	days := []string{"จันทร์", "อังคาร", "พุธ", "พฤหัสบดี", "ศุกร์", "เสาร์", "อาทิตย์"}
-	return days[index%7]
+	return thaiDays[index%7]
 }
 
-func calcWeeklyHours(credit entity.Credit) (int, int) {
-	lecHours := int(credit.Lecture) * 1
-	labHours := int(credit.Lab) * 3
-	return lecHours, labHours
+// clockMinutes เวลาเป็นนาทีนับจากเที่ยงคืนตามเวลาไทย (ดู services.ClockMinutes)
+func clockMinutes(t time.Time) int {
+	return services.ClockMinutes(t)
 }
 
+// isConflictWithConditions ตรวจเฉพาะเงื่อนไขรายสัปดาห์ (เทียบเวลาในวัน เพราะ Condition เก็บบนวันที่ 2000-01-01)
+// เงื่อนไขตามช่วงวันที่/ครั้งเดียวไม่กันการจัด แต่จะถูกเตือนหลังจัดเสร็จ
 func isConflictWithConditions(day string, start, end time.Time, conditions []entity.Condition) bool {
 	for _, c := range conditions {
-		if c.DayOfWeek == day &&
-			(start.Before(c.EndTime) && end.After(c.StartTime)) {
+		if c.DayOfWeek == day && c.IsWeekly() &&
+			overlapMinutes(clockMinutes(start), clockMinutes(end), clockMinutes(c.StartTime), clockMinutes(c.EndTime)) > 0 {
 			return true
 		}
 	}
@@ -43,38 +50,124 @@ func isConsecutiveSlot(schedules []entity.Schedule, day string, start time.Time,
 	return true
 }
 
-func isInstructorConflict(day string, start, end time.Time, schedules []entity.Schedule, userID uint) bool {
+// adjacentSession หาคาบชนิดเดียวกันของกลุ่มเดียวกันที่จบตรงเวลา start ในห้องเดิม คืน -1 ถ้าไม่มี
+func adjacentSession(schedules []entity.Schedule, courseID, sec uint, day, kind string, roomID *uint, start time.Time) int {
+	for i, s := range schedules {
+		if s.OfferedCoursesID != courseID || s.SectionNumber != sec || s.DayOfWeek != day || s.Kind != kind {
+			continue
+		}
+		if (s.RoomID == nil) != (roomID == nil) || (roomID != nil && *s.RoomID != *roomID) {
+			continue
+		}
+		if s.ID != 0 && clockMinutes(s.EndTime) == clockMinutes(start) {
+			return i
+		}
+	}
+	return -1
+}
+
+// lectureRunMinutes ความยาวช่วงบรรยายต่อเนื่องของกลุ่มนี้ในวันนั้น ถ้าเพิ่มช่วง [start, end) (นาที) เข้าไป
+// นับคาบบรรยายที่ติดกันทั้งก่อนและหลังโดยไม่สนใจห้อง
+func lectureRunMinutes(schedules []entity.Schedule, courseID, sec uint, day string, start, end int) int {
+	from, to := start, end
+	for grown := true; grown; {
+		grown = false
+		for _, s := range schedules {
+			if s.OfferedCoursesID != courseID || s.SectionNumber != sec || s.DayOfWeek != day || s.Kind != entity.SessionKindLecture {
+				continue
+			}
+			ss, se := clockMinutes(s.StartTime), clockMinutes(s.EndTime)
+			if se == from {
+				from, grown = ss, true
+			}
+			if ss == to {
+				to, grown = se, true
+			}
+		}
+	}
+	return to - from
+}
+
+// sessionMinutes นาทีรวมของคาบชนิด kind ของกลุ่มนี้ในวันนั้น
+func sessionMinutes(schedules []entity.Schedule, courseID, sec uint, day, kind string) int {
+	total := 0
 	for _, s := range schedules {
-		if s.DayOfWeek != day || !(start.Before(s.EndTime) && end.After(s.StartTime)) {
+		if s.OfferedCoursesID == courseID && s.SectionNumber == sec && s.DayOfWeek == day && s.Kind == kind {
+			total += clockMinutes(s.EndTime) - clockMinutes(s.StartTime)
+		}
+	}
+	return total
+}
+
+// isInstructorConflict ตรวจว่าผู้สอนคนใดใน userIDs มีคาบอื่นทับช่วงเวลานี้ โดยดูผู้สอนรายกลุ่มจาก ix
+func isInstructorConflict(day string, start, end time.Time, schedules []entity.Schedule, userIDs []uint, ix *instructorIndex) bool {
+	for _, s := range schedules {
+		if s.DayOfWeek != day ||
+			overlapMinutes(clockMinutes(start), clockMinutes(end), clockMinutes(s.StartTime), clockMinutes(s.EndTime)) == 0 {
 			continue
 		}
-		var oc entity.OfferedCourses
-		if err := config.DB().First(&oc, s.OfferedCoursesID).Error; err == nil {
-			if oc.UserID == userID {
-				return true
+		for _, other := range ix.teachers(s.OfferedCoursesID, s.SectionNumber) {
+			for _, uid := range userIDs {
+				if other == uid {
+					return true
+				}
 			}
 		}
 	}
 	return false
 }
 
-func isAcademicYearConflict(day string, start, end time.Time, schedules []entity.Schedule, course entity.OfferedCourses) bool {
+// isAcademicYearConflict ตรวจว่ามีวิชาของกลุ่มผู้เรียนเดียวกันทับช่วงเวลานี้ (กลุ่มผู้เรียนได้จากแผนการศึกษาของเทอมที่จัด)
+// offered ต้องมี AllCourses และ Aliases.AllCourses ของทุกวิชาในเทอม คู่กลุ่มเรียนที่ระบุกลุ่มนักศึกษาไว้ทั้งคู่ตรวจด้วย isGroupConflict แทน
+func isAcademicYearConflict(day string, start, end time.Time, schedules []entity.Schedule, course entity.OfferedCourses, sectionID *uint, cohorts *cohortIndex, groups *groupIndex, offered map[uint]entity.OfferedCourses) bool {
+	self, ok := offered[course.ID]
+	if !ok {
+		self = course
+	}
 	for _, s := range schedules {
-		if s.DayOfWeek != day || !(start.Before(s.EndTime) && end.After(s.StartTime)) {
+		if s.DayOfWeek != day || s.OfferedCoursesID == course.ID ||
+			overlapMinutes(clockMinutes(start), clockMinutes(end), clockMinutes(s.StartTime), clockMinutes(s.EndTime)) == 0 {
+			continue
+		}
+		if groups.has(sectionID) && groups.has(s.SectionID) {
 			continue
 		}
 
-		var oc entity.OfferedCourses
-		if err := config.DB().
-			Preload("AllCourses.AcademicYear").
-			Preload("Laboratory").
-			First(&oc, s.OfferedCoursesID).Error; err == nil {
+		oc, ok := offered[s.OfferedCoursesID]
+		if !ok || !servedConflict(oc, self, cohorts) {
+			continue
+		}
+		if oc.LaboratoryID != nil && course.LaboratoryID != nil &&
+			*oc.LaboratoryID != *course.LaboratoryID {
+			continue
+		}
+		return true
+	}
+	return false
+}
 
-			if oc.AllCourses.AcademicYearID == course.AllCourses.AcademicYearID {
-				if oc.LaboratoryID != nil && course.LaboratoryID != nil &&
-					*oc.LaboratoryID != *course.LaboratoryID {
-					continue
-				}
+// isGroupConflict ตรวจว่ากลุ่มนักศึกษาที่เรียนกลุ่มเรียนนี้มีคาบอื่นทับช่วงเวลานี้ (รวมกลุ่มเรียนอื่นของวิชาเดียวกัน)
+func isGroupConflict(day string, start, end time.Time, schedules []entity.Schedule, sectionID *uint, groups *groupIndex) bool {
+	if !groups.has(sectionID) {
+		return false
+	}
+	for _, s := range schedules {
+		if s.DayOfWeek != day || (s.SectionID != nil && *s.SectionID == *sectionID) ||
+			overlapMinutes(clockMinutes(start), clockMinutes(end), clockMinutes(s.StartTime), clockMinutes(s.EndTime)) == 0 {
+			continue
+		}
+		if groups.shares(sectionID, s.SectionID) {
+			return true
+		}
+	}
+	return false
+}
+
+// servedConflict วิชาที่เปิดสอนสองวิชามีผู้เรียนกลุ่มเดียวกัน (ตรวจทุกรายวิชาเทียบเท่าที่เรียนรวม)
+func servedConflict(a, b entity.OfferedCourses, cohorts *cohortIndex) bool {
+	for _, x := range servedCourses(a) {
+		for _, y := range servedCourses(b) {
+			if cohorts.conflicts(x, y) {
 				return true
 			}
 		}
@@ -84,7 +177,12 @@ func isAcademicYearConflict(day string, start, end time.Time, schedules []entity
 
 func isLabConflict(day string, start, end time.Time, schedules []entity.Schedule, labID uint) bool {
 	for _, s := range schedules {
-		if s.DayOfWeek != day || !(start.Before(s.EndTime) && end.After(s.StartTime)) {
+		if s.DayOfWeek != day ||
+			overlapMinutes(clockMinutes(start), clockMinutes(end), clockMinutes(s.StartTime), clockMinutes(s.EndTime)) == 0 {
+			continue
+		}
+		// คาบที่ระบุห้องแล้วตรวจด้วย isRoomConflict แทน
+		if s.RoomID != nil {
 			continue
 		}
 		var oc entity.OfferedCourses
@@ -97,6 +195,37 @@ func isLabConflict(day string, start, end time.Time, schedules []entity.Schedule
 	return false
 }
 
+func isRoomConflict(day string, start, end time.Time, schedules []entity.Schedule, roomID uint) bool {
+	for _, s := range schedules {
+		if s.RoomID == nil || *s.RoomID != roomID || s.DayOfWeek != day {
+			continue
+		}
+		if clockMinutes(start) < clockMinutes(s.EndTime) && clockMinutes(end) > clockMinutes(s.StartTime) {
+			return true
+		}
+	}
+	return false
+}
+
+// pickRoom เลือกห้องแรกที่ว่างในช่วงเวลานั้น (rooms เรียงจากความจุน้อยไปมากแล้ว) คืน nil ถ้าไม่มีห้องว่าง
+func pickRoom(day string, start, end time.Time, schedules []entity.Schedule, rooms []entity.Room) *uint {
+	for _, r := range rooms {
+		if !isRoomConflict(day, start, end, schedules, r.ID) {
+			id := r.ID
+			return &id
+		}
+	}
+	return nil
+}
+
+// ScheduleWarning ข้อสังเกตจากการจัดตารางอัตโนมัติที่ไม่ทำให้การจัดล้มเหลว
+type ScheduleWarning struct {
+	OfferedCoursesID uint
+	Code             string
+	Section          uint
+	Message          string
+}
+
 // ========================= GetScheduleByNameTable =========================
 func GetScheduleByNameTable(c *gin.Context) {
 	majorName := c.Query("major_name")
@@ -104,9 +233,7 @@ func GetScheduleByNameTable(c *gin.Context) {
 	term := c.Query("term")
 
 	if majorName == "" || year == "" || term == "" {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "ต้องระบุ major_name, year และ term",
-		})
+		respondError(c, http.StatusBadRequest, "ต้องระบุ major_name, year และ term")
 		return
 	}
 
@@ -117,7 +244,7 @@ func GetScheduleByNameTable(c *gin.Context) {
 		Select("department_id").
 		Where("major_name = ?", majorName).
 		Scan(&deptID).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "ไม่สามารถดึง department ของ major ได้", "details": err.Error()})
+		respondServerError(c, http.StatusInternalServerError, "ไม่สามารถดึง department ของ major ได้", err)
 		return
 	}
 
@@ -136,10 +263,10 @@ func GetScheduleByNameTable(c *gin.Context) {
 		Preload("OfferedCourses.AllCourses.Credit").
 		Preload("OfferedCourses.AllCourses.UserAllCourses").
 		Preload("OfferedCourses.AllCourses.UserAllCourses.User").
+		Preload("OfferedCourses.Aliases.AllCourses").
 		Preload("TimeFixedCourses").
-		Preload("ScheduleTeachingAssistant").
-		Preload("ScheduleTeachingAssistant.TeachingAssistant").
-		Preload("ScheduleTeachingAssistant.TeachingAssistant.Title").
+		Preload("Room").
+		Preload("Section.TeachingAssistants.TeachingAssistant.Title").
 		Joins("JOIN offered_courses ON schedules.offered_courses_id = offered_courses.id").
 		Joins("JOIN all_courses ON offered_courses.all_courses_id = all_courses.id").
 		Joins("JOIN curriculums ON all_courses.curriculum_id = curriculums.id").
@@ -165,10 +292,7 @@ func GetScheduleByNameTable(c *gin.Context) {
 		Find(&schedules).Error
 
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "ไม่สามารถดึงตารางสอนได้",
-			"details": err.Error(),
-		})
+		respondServerError(c, http.StatusInternalServerError, "ไม่สามารถดึงตารางสอนได้", err)
 		return
 	}
 
@@ -189,7 +313,7 @@ func AutoGenerateSchedule(c *gin.Context) {
 		Select("department_id").
 		Where("major_name = ?", user_major).
 		Scan(&deptID).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "ไม่สามารถดึง department ของ major ได้", "details": err.Error()})
+		respondServerError(c, http.StatusInternalServerError, "ไม่สามารถดึง department ของ major ได้", err)
 		return
 	}
 
@@ -215,10 +339,38 @@ func AutoGenerateSchedule(c *gin.Context) {
 		Preload("AllCourses.Curriculum.Major").
 		Preload("Laboratory").
 		Find(&offeredCourses).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "โหลด OfferedCourses ไม่สำเร็จ", "details": err.Error()})
+		respondServerError(c, http.StatusInternalServerError, "โหลด OfferedCourses ไม่สำเร็จ", err)
 		return
 	}
 
+	// กลุ่มเรียนของทุกวิชาที่จะจัด (สร้างให้ครบตามจำนวนกลุ่มก่อน)
+	sections := make(map[sectionKey]entity.Section)
+	if err := config.DB().Transaction(func(tx *gorm.DB) error {
+		for _, course := range offeredCourses {
+			if _, err := config.EnsureSections(tx, course); err != nil {
+				return err
+			}
+			var rows []entity.Section
+			if err := tx.Where("offered_courses_id = ?", course.ID).Find(&rows).Error; err != nil {
+				return err
+			}
+			for _, sec := range rows {
+				sections[sectionKey{course.ID, sec.SectionNumber}] = sec
+			}
+		}
+		return nil
+	}); err != nil {
+		respondSectionsError(c, err, "เตรียมกลุ่มเรียนไม่สำเร็จ")
+		return
+	}
+	sectionIDOf := func(courseID, number uint) *uint {
+		if sec, ok := sections[sectionKey{courseID, number}]; ok {
+			id := sec.ID
+			return &id
+		}
+		return nil
+	}
+
 	// ========== ลบ auto schedules ของสาขาผู้ใช้เหมือนเดิม ==========
 	subQuery := config.DB().
 		Table("schedules").
@@ -234,7 +386,7 @@ func AutoGenerateSchedule(c *gin.Context) {
 	if err := config.DB().
 		Where("id IN (?)", subQuery).
 		Delete(&entity.Schedule{}).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "ลบ auto schedules เดิมไม่สำเร็จ", "details": err.Error()})
+		respondServerError(c, http.StatusInternalServerError, "ลบ auto schedules เดิมไม่สำเร็จ", err)
 		return
 	}
 
@@ -279,7 +431,10 @@ func AutoGenerateSchedule(c *gin.Context) {
 					DayOfWeek:        fixed.DayOfWeek,
 					StartTime:        fixed.StartTime,
 					EndTime:          fixed.EndTime,
+					Kind:             entity.SessionKindLecture,
 					OfferedCoursesID: course.ID,
+					SectionID:        sectionIDOf(course.ID, fixed.Section),
+					RoomID:           fixed.RoomID,
 				}
 				if err := config.DB().Create(&schedule).Error; err != nil {
 					continue
@@ -294,7 +449,7 @@ func AutoGenerateSchedule(c *gin.Context) {
 	if err := config.DB().
 		Where("name_table = ?", nameTable).
 		Find(&allSchedules).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "โหลด schedules ทั้งหมดไม่สำเร็จ", "details": err.Error()})
+		respondServerError(c, http.StatusInternalServerError, "โหลด schedules ทั้งหมดไม่สำเร็จ", err)
 		return
 	}
 
@@ -314,7 +469,7 @@ func AutoGenerateSchedule(c *gin.Context) {
 		Preload("AllCourses.Curriculum.Major").
 		Preload("Laboratory").
 		Find(&autoCourses).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "โหลด auto courses ไม่สำเร็จ", "details": err.Error()})
+		respondServerError(c, http.StatusInternalServerError, "โหลด auto courses ไม่สำเร็จ", err)
 		return
 	}
 
@@ -351,140 +506,366 @@ func AutoGenerateSchedule(c *gin.Context) {
 	// seed random แค่ครั้งเดียว
 	rand.Seed(time.Now().UnixNano())
 
+	warnings := []ScheduleWarning{}
+
+	// แคตตาล็อกห้อง: ห้องบรรยาย/คอมพิวเตอร์สำหรับคาบบรรยาย และห้องที่คู่กับ Laboratory สำหรับคาบปฏิบัติ
+	var lectureRooms []entity.Room
+	if err := config.DB().
+		Where("room_type IN ?", []string{entity.RoomTypeLectureHall, entity.RoomTypeComputerRoom}).
+		Order("capacity, id").
+		Find(&lectureRooms).Error; err != nil {
+		respondServerError(c, http.StatusInternalServerError, "โหลดข้อมูลห้องเรียนไม่สำเร็จ", err)
+		return
+	}
+	var labRooms []entity.Room
+	if err := config.DB().Where("laboratory_id IS NOT NULL").Find(&labRooms).Error; err != nil {
+		respondServerError(c, http.StatusInternalServerError, "โหลดข้อมูลห้องปฏิบัติการไม่สำเร็จ", err)
+		return
+	}
+	labRoomByLab := make(map[uint]uint)
+	for _, r := range labRooms {
+		labRoomByLab[*r.LaboratoryID] = r.ID
+	}
+
+	// ตารางเวลาของภาควิชา (วันทำการ ช่องเวลา ช่วงห้ามจัด ช่วงที่ต้องการ/สำรอง)
+	grid, err := loadTimeGrid(deptID)
+	if err != nil {
+		respondServerError(c, http.StatusInternalServerError, "ตารางเวลาของภาควิชาไม่ถูกต้อง", err)
+		return
+	}
+
+	// กติกาแปลงหน่วยกิตเป็นชั่วโมง (ต่อประเภทรายวิชา/รายวิชา)
+	hoursRules, err := config.LoadHoursRules()
+	if err != nil {
+		respondServerError(c, http.StatusInternalServerError, "โหลดกติกาชั่วโมงสอนไม่สำเร็จ", err)
+		return
+	}
+
+	// ผู้สอนรายกลุ่มของทุกวิชาในเทอม (รวมสาขาอื่น) สำหรับตรวจอาจารย์สอนชน
+	instructors, err := loadInstructorIndex(year, term)
+	if err != nil {
+		respondServerError(c, http.StatusInternalServerError, "โหลดข้อมูลผู้สอนรายกลุ่มไม่สำเร็จ", err)
+		return
+	}
+
+	// กลุ่มผู้เรียนที่ห้ามเรียนชนกัน (จากแผนการศึกษาของเทอมนี้) และรายวิชาของทุกคาบในเทอม
+	cohorts, err := loadCohortIndex(term)
+	if err != nil {
+		respondServerError(c, http.StatusInternalServerError, "โหลดแผนการศึกษาไม่สำเร็จ", err)
+		return
+	}
+	var termCourses []entity.OfferedCourses
+	if err := config.DB().Preload("AllCourses").Preload("Aliases.AllCourses").Where("year = ? AND term = ?", year, term).Find(&termCourses).Error; err != nil {
+		respondServerError(c, http.StatusInternalServerError, "โหลด OfferedCourses ไม่สำเร็จ", err)
+		return
+	}
+	offeredByID := make(map[uint]entity.OfferedCourses, len(termCourses))
+	for _, oc := range termCourses {
+		offeredByID[oc.ID] = oc
+	}
+	groups, err := loadGroupIndex()
+	if err != nil {
+		respondServerError(c, http.StatusInternalServerError, "โหลดกลุ่มนักศึกษาไม่สำเร็จ", err)
+		return
+	}
+
+	// ความต้องการด้านเวลาแบบถ่วงน้ำหนักและจำนวนวัน/ชั่วโมงสอนสูงสุดของผู้สอน (soft constraint)
+	prefs, err := loadTeacherPrefs()
+	if err != nil {
+		respondServerError(c, http.StatusInternalServerError, "โหลดความต้องการด้านเวลาของผู้สอนไม่สำเร็จ", err)
+		return
+	}
+
 	// 5) วาง auto schedules (พิจารณาชนกับ fixed ด้วย เพราะ allSchedules มี fixed อยู่แล้ว)
 	for _, group := range [][]entity.OfferedCourses{coreCourses, electiveCourses} {
 		for _, course := range group {
-			days := []int{0, 1, 2, 3, 4} // จันทร์-ศุกร์
-			preferredHours := []int{8, 9, 10, 11, 13, 14, 15}
-			fallbackHours := []int{16, 17, 18, 19, 20}
-			rand.Shuffle(len(days), func(i, j int) { days[i], days[j] = days[j], days[i] })
-			rand.Shuffle(len(preferredHours), func(i, j int) { preferredHours[i], preferredHours[j] = preferredHours[j], preferredHours[i] })
-			rand.Shuffle(len(fallbackHours), func(i, j int) { fallbackHours[i], fallbackHours[j] = fallbackHours[j], fallbackHours[i] })
+			if course.LaboratoryID != nil && course.Laboratory.ID != 0 &&
+				course.Capacity > course.Laboratory.Capacity {
+				warnings = append(warnings, ScheduleWarning{
+					OfferedCoursesID: course.ID,
+					Code:             course.AllCourses.Code,
+					Message: fmt.Sprintf("จำนวนที่นั่งต่อกลุ่ม (%d) เกินความจุห้อง %s (%d)",
+						course.Capacity, course.Laboratory.Room, course.Laboratory.Capacity),
+				})
+			}
+
+			baseDays := append([]int(nil), grid.days...)
+			rand.Shuffle(len(baseDays), func(i, j int) { baseDays[i], baseDays[j] = baseDays[j], baseDays[i] })
 
-			credit := course.AllCourses.Credit
-			lecHours, labHours := calcWeeklyHours(credit)
+			hours := hoursRules.For(course.AllCourses)
+			lecHours := hours.Lecture
+			lecMinutes := hours.Lecture * 60
+
+			// ห้องของคาบปฏิบัติ = ห้องที่คู่กับ Laboratory ของวิชา
+			var labRoomID *uint
+			if course.LaboratoryID != nil {
+				if id, ok := labRoomByLab[*course.LaboratoryID]; ok {
+					labRoomID = &id
+				}
+			}
 
 			for sec := uint(1); sec <= course.Section; sec++ {
+				section, hasSection := sections[sectionKey{course.ID, sec}]
+				sectionID := sectionIDOf(course.ID, sec)
+				capacity := course.Capacity
+				if hasSection && section.Capacity > 0 {
+					capacity = section.Capacity
+				}
+
+				// ห้องที่จุผู้เรียนได้สำหรับคาบบรรยาย ถ้ากลุ่มกำหนดห้องไว้ใช้ห้องนั้นเท่านั้น
+				// ถ้าไม่มีเลยจะวางคาบโดยไม่ระบุห้อง (หรือใช้ห้องปฏิบัติการเดิม)
+				fitRooms := make([]entity.Room, 0, len(lectureRooms))
+				for _, r := range lectureRooms {
+					if hasSection && section.RoomID != nil && r.ID != *section.RoomID {
+						continue
+					}
+					if r.Capacity >= capacity {
+						fitRooms = append(fitRooms, r)
+					}
+				}
+				if lecHours > 0 && len(lectureRooms) > 0 && len(fitRooms) == 0 {
+					warnings = append(warnings, ScheduleWarning{
+						OfferedCoursesID: course.ID,
+						Code:             course.AllCourses.Code,
+						Section:          sec,
+						Message:          fmt.Sprintf("ไม่มีห้องบรรยายที่จุได้ %d คน", capacity),
+					})
+				}
+
+				teachers := instructors.teachers(course.ID, sec)
 				var conditions []entity.Condition
-				_ = config.DB().Where("user_id = ?", course.UserID).Find(&conditions).Error
+				_ = config.DB().Where("user_id IN ?", append([]uint{0}, teachers...)).Find(&conditions).Error
 
+				// วันที่ผู้สอนให้คะแนนความต้องการสูงกว่ามาก่อน (วันที่คะแนนเท่ากันคงลำดับสุ่ม)
+				days := append([]int(nil), baseDays...)
+				sort.SliceStable(days, func(i, j int) bool {
+					di, dj := getDayName(days[i]), getDayName(days[j])
+					return prefs.Score(teachers, di, grid.dayStart, grid.dayEnd) > prefs.Score(teachers, dj, grid.dayStart, grid.dayEnd)
+				})
+
+				labBlocks := hours.LabBlocks()
+				labPlaced := make([]bool, len(labBlocks))
+				labDays := make(map[int]bool)
 				labDayIndex := -1
-				scheduledLecture := 0
+				scheduledLecture := 0 // นาที
+				relaxed := false // มีคาบที่วางเกินจำนวนวัน/ชั่วโมงสอนต่อวันที่ผู้สอนขอไว้
 
-				// --- วาง LAB ก่อน ---
-				if labHours > 0 {
-				LAB_LOOP:
-					for _, day := range days {
-						dayName := getDayName(day)
-						for hour := 8; hour <= (21 - labHours); hour++ {
-							// ข้ามช่วงเที่ยง
-							if hour == 12 || (hour < 12 && hour+labHours > 12) {
+				// รอบแรกเคารพจำนวนวัน/ชั่วโมงสอนต่อวันที่ผู้สอนขอไว้ ถ้ายังวางไม่ครบจึงผ่อนในรอบที่สอง
+				for _, strict := range []bool{true, false} {
+					if !strict {
+						pending := scheduledLecture < lecMinutes
+						for _, ok := range labPlaced {
+							pending = pending || !ok
+						}
+						if !pending {
+							break
+						}
+					}
+
+					// --- วาง LAB ก่อน (แบ่งช่วงตาม MaxLabBlock ช่วงละวัน ไม่คร่อมช่วงห้ามจัด) ---
+					for b, blockHours := range labBlocks {
+						if labPlaced[b] {
+							continue
+						}
+						labMinutes := blockHours * 60
+					LAB_LOOP:
+						for _, day := range days {
+							if labDays[day] {
 								continue
 							}
-							conflict := false
-							var tempSlots []entity.Schedule
-							for i := 0; i < labHours; i++ {
-								start := time.Date(2006, 1, 2, hour+i, 0, 0, 0, time.FixedZone("Asia/Bangkok", 7*60*60))
-								end := start.Add(time.Hour)
-
+							dayName := getDayName(day)
+							overLimit := !prefs.WithinLimits(teachers, dayName, labMinutes, allSchedules, instructors.teachesSchedule)
+							if strict && overLimit {
+								continue
+							}
+							starts := grid.starts(dayName, grid.dayStart, grid.dayEnd, labMinutes)
+							prefs.RankStarts(starts, teachers, dayName, labMinutes)
+							for _, m := range starts {
+								start, end := gridTime(m), gridTime(m+labMinutes)
 								if isConflictWithConditions(dayName, start, end, conditions) ||
-									isInstructorConflict(dayName, start, end, allSchedules, course.UserID) ||
+									isInstructorConflict(dayName, start, end, allSchedules, teachers, instructors) ||
+									isGroupConflict(dayName, start, end, allSchedules, sectionID, groups) ||
 									(course.LaboratoryID != nil && isLabConflict(dayName, start, end, allSchedules, *course.LaboratoryID)) ||
+									(labRoomID != nil && isRoomConflict(dayName, start, end, allSchedules, *labRoomID)) ||
 									!isConsecutiveSlot(allSchedules, dayName, start, course.ID, sec) {
-									conflict = true
-									break
+									continue
 								}
 
-								tempSlots = append(tempSlots, entity.Schedule{
+								s := entity.Schedule{
 									NameTable:        nameTable,
 									SectionNumber:    sec,
 									DayOfWeek:        dayName,
 									StartTime:        start,
 									EndTime:          end,
+									Kind:             entity.SessionKindLab,
 									OfferedCoursesID: course.ID,
-								})
-							}
-
-							if !conflict {
-								for _, s := range tempSlots {
-									allSchedules = append(allSchedules, s)
-									_ = config.DB().Create(&s).Error
+									SectionID:        sectionID,
+									RoomID:           labRoomID,
+								}
+								_ = config.DB().Create(&s).Error
+								allSchedules = append(allSchedules, s)
+								labDays[day] = true
+								labPlaced[b] = true
+								relaxed = relaxed || overLimit
+								if labDayIndex == -1 || day < labDayIndex {
+									labDayIndex = day
 								}
-								labDayIndex = day
 								break LAB_LOOP
 							}
 						}
 					}
-				}
-
-				// --- วาง LECTURE (พยายามวางก่อนวัน LAB และไม่เกิน 2 ชม./วัน) ---
-				for _, day := range days {
-					if day == labDayIndex {
-						continue
-					}
-					if labDayIndex != -1 && day > labDayIndex {
-						continue
-					}
-
-					dayName := getDayName(day)
-					slotsToday := 0
-					maxPerDay := 3
 
-					tryPlace := func(hour int) bool {
-						if hour == 12 {
-							return false
+					// --- วาง LECTURE (พยายามวางก่อนวัน LAB) ทีละช่องเวลาของตาราง ---
+					// บรรยายต่อเนื่องไม่เกิน MaxLectureBlock และรวมต่อวันไม่เกิน 3 ชม. (หรือ MaxLectureBlock ถ้ายาวกว่า)
+					for _, day := range days {
+						if labDays[day] {
+							continue
 						}
-						start := time.Date(2006, 1, 2, hour, 0, 0, 0, time.FixedZone("Asia/Bangkok", 7*60*60))
-						end := start.Add(time.Hour)
-						// ไม่ข้ามช่วงเที่ยง
-						if start.Hour() < 12 && end.Hour() > 12 {
-							return false
+						if labDayIndex != -1 && day > labDayIndex {
+							continue
 						}
-						if isConflictWithConditions(dayName, start, end, conditions) ||
-							isInstructorConflict(dayName, start, end, allSchedules, course.UserID) ||
-							isAcademicYearConflict(dayName, start, end, allSchedules, course) ||
-							(course.LaboratoryID != nil && isLabConflict(dayName, start, end, allSchedules, *course.LaboratoryID)) ||
-							!isConsecutiveSlot(allSchedules, dayName, start, course.ID, sec) {
-							return false
+
+						dayName := getDayName(day)
+						minutesToday := sessionMinutes(allSchedules, course.ID, sec, dayName, entity.SessionKindLecture)
+						maxBlock := hours.MaxLectureBlock * 60
+						if maxBlock <= 0 {
+							maxBlock = lecMinutes
 						}
+						maxPerDay := max(lectureMinutesPerDay, maxBlock)
+
+						tryPlace := func(m int) bool {
+							if grid.isBlocked(dayName, m, m+grid.slot) ||
+								lectureRunMinutes(allSchedules, course.ID, sec, dayName, m, m+grid.slot) > maxBlock {
+								return false
+							}
+							overLimit := !prefs.WithinLimits(teachers, dayName, grid.slot, allSchedules, instructors.teachesSchedule)
+							if strict && overLimit {
+								return false
+							}
+							start, end := gridTime(m), gridTime(m+grid.slot)
+							if isConflictWithConditions(dayName, start, end, conditions) ||
+								isInstructorConflict(dayName, start, end, allSchedules, teachers, instructors) ||
+								isAcademicYearConflict(dayName, start, end, allSchedules, course, sectionID, cohorts, groups, offeredByID) ||
+								isGroupConflict(dayName, start, end, allSchedules, sectionID, groups) ||
+								!isConsecutiveSlot(allSchedules, dayName, start, course.ID, sec) {
+								return false
+							}
 
-						s := entity.Schedule{
-							NameTable:        nameTable,
-							SectionNumber:    sec,
-							DayOfWeek:        dayName,
-							StartTime:        start,
-							EndTime:          end,
-							OfferedCoursesID: course.ID,
+							// เลือกห้องบรรยาย ถ้าแคตตาล็อกไม่มีห้องที่จุได้ให้ใช้ห้องปฏิบัติการของวิชาแบบเดิม
+							var roomID *uint
+							if len(fitRooms) > 0 {
+								roomID = pickRoom(dayName, start, end, allSchedules, fitRooms)
+								if roomID == nil {
+									return false
+								}
+							} else if course.LaboratoryID != nil {
+								if isLabConflict(dayName, start, end, allSchedules, *course.LaboratoryID) ||
+									(labRoomID != nil && isRoomConflict(dayName, start, end, allSchedules, *labRoomID)) {
+									return false
+								}
+								roomID = labRoomID
+							}
+
+							// ต่อท้ายคาบบรรยายที่ติดกันในห้องเดียวกันให้เป็นช่วงเดียว
+							if j := adjacentSession(allSchedules, course.ID, sec, dayName, entity.SessionKindLecture, roomID, start); j >= 0 {
+								allSchedules[j].EndTime = end
+								_ = config.DB().Model(&entity.Schedule{}).Where("id = ?", allSchedules[j].ID).Update("end_time", end).Error
+							} else {
+								s := entity.Schedule{
+									NameTable:        nameTable,
+									SectionNumber:    sec,
+									DayOfWeek:        dayName,
+									StartTime:        start,
+									EndTime:          end,
+									Kind:             entity.SessionKindLecture,
+									OfferedCoursesID: course.ID,
+									SectionID:        sectionID,
+									RoomID:           roomID,
+								}
+								_ = config.DB().Create(&s).Error
+								allSchedules = append(allSchedules, s)
+							}
+							scheduledLecture += grid.slot
+							minutesToday += grid.slot
+							relaxed = relaxed || overLimit
+							return true
 						}
-						allSchedules = append(allSchedules, s)
-						_ = config.DB().Create(&s).Error
-						scheduledLecture++
-						slotsToday++
-						return true
-					}
 
-					// preferred ก่อน
-					for _, h := range preferredHours {
-						if scheduledLecture >= lecHours || slotsToday >= maxPerDay {
-							break
+						// ช่วงเวลาที่ต้องการก่อน แล้วค่อยช่วงสำรอง เมื่อวางช่องแรกได้แล้วต่อช่องถัดไปให้ติดกัน
+						windows := [][2]int{{grid.prefStart, grid.prefEnd}}
+						if grid.fallEnd > 0 {
+							windows = append(windows, [2]int{grid.fallStart, grid.fallEnd})
 						}
-						_ = tryPlace(h)
-					}
-					// fallback ช่วงเย็น
-					if scheduledLecture < lecHours && slotsToday < maxPerDay {
-						for _, h := range fallbackHours {
-							if scheduledLecture >= lecHours || slotsToday >= maxPerDay {
-								break
+						for _, w := range windows {
+							candidates := grid.starts(dayName, w[0], w[1], grid.slot)
+							rand.Shuffle(len(candidates), func(i, j int) { candidates[i], candidates[j] = candidates[j], candidates[i] })
+							prefs.RankStarts(candidates, teachers, dayName, grid.slot)
+							for _, m := range candidates {
+								if scheduledLecture >= lecMinutes || minutesToday >= maxPerDay {
+									break
+								}
+								if !tryPlace(m) {
+									continue
+								}
+								for next := m + grid.slot; next+grid.slot <= w[1]; next += grid.slot {
+									if scheduledLecture >= lecMinutes || minutesToday >= maxPerDay || !tryPlace(next) {
+										break
+									}
+								}
 							}
-							_ = tryPlace(h)
 						}
 					}
 				}
+
+				if relaxed {
+					warnings = append(warnings, ScheduleWarning{
+						OfferedCoursesID: course.ID,
+						Code:             course.AllCourses.Code,
+						Section:          sec,
+						Message:          "จัดเกินจำนวนวันสอนหรือชั่วโมงสอนต่อวันที่ผู้สอนกำหนดไว้",
+					})
+				}
 			}
 		}
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "สร้างตารางสอนอัตโนมัติสำเร็จ"})
+	// 6) เตือนคาบที่ตกในวันที่ผู้สอนลา/ไม่ว่างเป็นช่วง ๆ (เงื่อนไขรายสัปดาห์ถูกกันไว้ตั้งแต่ตอนจัดแล้ว)
+	autoCodes := make(map[uint]string)
+	for _, group := range [][]entity.OfferedCourses{coreCourses, electiveCourses} {
+		for _, course := range group {
+			autoCodes[course.ID] = course.AllCourses.Code
+		}
+	}
+	var autoSchedules []entity.Schedule
+	for _, s := range allSchedules {
+		if _, ok := autoCodes[s.OfferedCoursesID]; ok {
+			autoSchedules = append(autoSchedules, s)
+		}
+	}
+	var datedConditions []entity.Condition
+	_ = config.DB().Preload("User.Title").Where("date IS NOT NULL OR effective_from IS NOT NULL OR effective_to IS NOT NULL").Find(&datedConditions).Error
+	cal, _ := loadTermCalendar(year, term)
+	for _, cf := range findConditionConflicts(autoSchedules, datedConditions, instructors, cal) {
+		msg := fmt.Sprintf("%s ไม่ว่างวัน%s %s-%s", cf.Fullname, cf.DayOfWeek, cf.Start, cf.End)
+		if len(cf.Dates) > 0 {
+			msg += " วันที่ " + strings.Join(cf.Dates, ", ")
+		}
+		if cf.Note != "" {
+			msg += " (" + cf.Note + ")"
+		}
+		warnings = append(warnings, ScheduleWarning{
+			OfferedCoursesID: cf.OfferedCoursesID,
+			Code:             autoCodes[cf.OfferedCoursesID],
+			Section:          cf.SectionNumber,
+			Message:          msg,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":           "สร้างตารางสอนอัตโนมัติสำเร็จ",
+		"warnings":          warnings,
+		"preference_report": preferenceReport(prefs, allSchedules, instructors),
+	})
 }
 
 // ///////////////////////////////////////// ดึงตารางสอนไปแสดงตาม nametable
@@ -495,7 +876,7 @@ func GetNameTable(c *gin.Context) {
 		Model(&entity.Schedule{}).
 		Distinct("name_table").
 		Pluck("name_table", &nameTables).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "ไม่สามารถดึงข้อมูล NameTable ได้"})
+		respondError(c, http.StatusInternalServerError, "ไม่สามารถดึงข้อมูล NameTable ได้")
 		return
 	}
 
@@ -510,16 +891,17 @@ func UpdateScheduleTime(c *gin.Context) {
 		DayOfWeek string
 		StartTime time.Time
 		EndTime   time.Time
+		RoomID    *uint
 	}
 
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "ข้อมูลไม่ถูกต้อง"})
+		respondError(c, http.StatusBadRequest, "ข้อมูลไม่ถูกต้อง")
 		return
 	}
 
 	var schedule entity.Schedule
 	if err := config.DB().First(&schedule, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "ไม่พบตาราง"})
+		respondError(c, http.StatusNotFound, "ไม่พบตาราง")
 		return
 	}
 
@@ -527,8 +909,90 @@ func UpdateScheduleTime(c *gin.Context) {
 	schedule.StartTime = input.StartTime
 	schedule.EndTime = input.EndTime
 
+	// คาบที่ภาควิชาจัดเองต้องอยู่ในตารางเวลาของภาควิชา (วิชาจากศูนย์บริการไม่ตรวจ)
+	var oc entity.OfferedCourses
+	if err := config.DB().First(&oc, schedule.OfferedCoursesID).Error; err == nil && !oc.IsFixCourses {
+		grid, err := loadTimeGrid(departmentOfOfferedCourse(oc.ID))
+		if err != nil {
+			respondServerError(c, http.StatusInternalServerError, "ตารางเวลาของภาควิชาไม่ถูกต้อง", err)
+			return
+		}
+		if reason := grid.violation(schedule.DayOfWeek, schedule.StartTime, schedule.EndTime); reason != "" {
+			respondError(c, http.StatusBadRequest, reason)
+			return
+		}
+	}
+
+	if input.RoomID != nil {
+		var room entity.Room
+		if err := config.DB().First(&room, *input.RoomID).Error; err != nil {
+			respondError(c, http.StatusBadRequest, "ไม่พบห้องเรียนที่ระบุ")
+			return
+		}
+		schedule.RoomID = &room.ID
+	}
+
+	// ห้องต้องไม่ชนกับคาบอื่นในตารางเดียวกัน
+	if schedule.RoomID != nil {
+		var others []entity.Schedule
+		if err := config.DB().
+			Where("name_table = ? AND id <> ? AND room_id = ?", schedule.NameTable, schedule.ID, *schedule.RoomID).
+			Find(&others).Error; err != nil {
+			respondError(c, http.StatusInternalServerError, "ไม่สามารถตรวจสอบห้องเรียนได้")
+			return
+		}
+		if isRoomConflict(schedule.DayOfWeek, schedule.StartTime, schedule.EndTime, others, *schedule.RoomID) {
+			respondError(c, http.StatusConflict, "ห้องเรียนไม่ว่างในช่วงเวลานี้")
+			return
+		}
+	}
+
+	// กลุ่มนักศึกษาที่เรียนกลุ่มเรียนนี้ต้องไม่มีคาบอื่นทับ
+	if schedule.SectionID != nil {
+		groups, err := loadGroupIndex()
+		if err != nil {
+			respondError(c, http.StatusInternalServerError, "ไม่สามารถตรวจสอบกลุ่มนักศึกษาได้")
+			return
+		}
+		var others []entity.Schedule
+		if err := config.DB().
+			Where("name_table = ? AND id <> ? AND day_of_week = ?", schedule.NameTable, schedule.ID, schedule.DayOfWeek).
+			Find(&others).Error; err != nil {
+			respondError(c, http.StatusInternalServerError, "ไม่สามารถตรวจสอบกลุ่มนักศึกษาได้")
+			return
+		}
+		if isGroupConflict(schedule.DayOfWeek, schedule.StartTime, schedule.EndTime, others, schedule.SectionID, groups) {
+			respondError(c, http.StatusConflict, "กลุ่มนักศึกษามีคาบเรียนอื่นในช่วงเวลานี้")
+			return
+		}
+	}
+
+	// ผู้สอนของกลุ่มเรียนนี้ต้องไม่มีคาบอื่นทับ (ดูผู้สอนรายกลุ่มของเทอมเดียวกัน)
+	if oc.ID != 0 {
+		ix, err := loadInstructorIndex(strconv.Itoa(int(oc.Year)), strconv.Itoa(int(oc.Term)))
+		if err != nil {
+			respondError(c, http.StatusInternalServerError, "ไม่สามารถตรวจสอบผู้สอนได้")
+			return
+		}
+		var others []entity.Schedule
+		if err := config.DB().
+			Where("name_table = ? AND id <> ? AND day_of_week = ?", schedule.NameTable, schedule.ID, schedule.DayOfWeek).
+			Find(&others).Error; err != nil {
+			respondError(c, http.StatusInternalServerError, "ไม่สามารถตรวจสอบผู้สอนได้")
+			return
+		}
+		userIDs := ix.teachers(schedule.OfferedCoursesID, schedule.SectionNumber)
+		if isInstructorConflict(schedule.DayOfWeek, schedule.StartTime, schedule.EndTime, others, userIDs, ix) {
+			respondError(c, http.StatusConflict, "ผู้สอนมีคาบสอนอื่นในช่วงเวลานี้")
+			return
+		}
+	}
+
+	if !validateEntity(c, schedule) {
+		return
+	}
 	if err := config.DB().Save(&schedule).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "ไม่สามารถบันทึกตารางใหม่ได้"})
+		respondError(c, http.StatusInternalServerError, "ไม่สามารถบันทึกตารางใหม่ได้")
 		return
 	}
 
@@ -540,8 +1004,7 @@ func DeleteScheduleByNameTable(c *gin.Context) {
 	nameTable := c.Param("nameTable")
 
 	if nameTable == "" {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":     "nameTable is required",
+		respondError(c, http.StatusBadRequest, "ต้องระบุชื่อตาราง (nameTable)", gin.H{
 			"nameTable": nameTable,
 		})
 		return
@@ -551,16 +1014,14 @@ func DeleteScheduleByNameTable(c *gin.Context) {
 	if err := config.DB().Model(&entity.Schedule{}).
 		Where("name_table = ?", nameTable).
 		Count(&count).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":     err.Error(),
+		respondServerError(c, http.StatusInternalServerError, "ไม่สามารถตรวจสอบตารางสอนตามชื่อตารางได้", err, gin.H{
 			"nameTable": nameTable,
 		})
 		return
 	}
 
 	if count == 0 {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error":     "ไม่พบข้อมูลตารางตามชื่อตาราง",
+		respondError(c, http.StatusNotFound, "ไม่พบข้อมูลตารางตามชื่อตาราง", gin.H{
 			"nameTable": nameTable,
 		})
 		return
@@ -568,8 +1029,7 @@ func DeleteScheduleByNameTable(c *gin.Context) {
 
 	if err := config.DB().Where("name_table = ?", nameTable).
 		Delete(&entity.Schedule{}).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":     err.Error(),
+		respondServerError(c, http.StatusInternalServerError, "ไม่สามารถลบตารางสอนได้", err, gin.H{
 			"nameTable": nameTable,
 		})
 		return
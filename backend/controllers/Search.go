@@ -0,0 +1,180 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Nichakorn25/CPE-Teaching-Schedule/config"
+	"github.com/Nichakorn25/CPE-Teaching-Schedule/services"
+)
+
+const (
+	defaultSearchLimit = 10
+	maxSearchLimit     = 50
+)
+
+type (
+	SearchHit struct {
+		ID       uint
+		Title    string
+		Subtitle string
+		Score    float64
+	}
+
+	searchColumn struct {
+		expr    string
+		compact bool // เทียบแบบไม่มีช่องว่าง (รหัสวิชา)
+	}
+
+	// searchTarget ตารางที่ค้นได้ title/subtitle เป็นนิพจน์ SQL สำหรับแสดงผล
+	searchTarget struct {
+		key      string
+		table    string
+		joins    string
+		where    string
+		title    string
+		subtitle string
+		columns  []searchColumn
+	}
+)
+
+var searchTargets = []searchTarget{
+	{
+		key:      "courses",
+		table:    "all_courses",
+		title:    "all_courses.code",
+		subtitle: "all_courses.thai_name || ' / ' || all_courses.english_name",
+		columns: []searchColumn{
+			{expr: "replace(all_courses.code, ' ', '')", compact: true},
+			{expr: "all_courses.thai_name"},
+			{expr: "all_courses.english_name"},
+		},
+	},
+	{
+		key:      "instructors",
+		table:    "users",
+		joins:    "LEFT JOIN titles ON titles.id = users.title_id LEFT JOIN majors ON majors.id = users.major_id",
+		where:    "users.role_id <> 1",
+		title:    "COALESCE(titles.title, '') || users.firstname || ' ' || users.lastname",
+		subtitle: "COALESCE(majors.major_name, '')",
+		columns: []searchColumn{
+			{expr: "(users.firstname || ' ' || users.lastname)"},
+			{expr: "users.username"},
+			{expr: "users.email"},
+		},
+	},
+	{
+		key:      "teaching_assistants",
+		table:    "teaching_assistants",
+		joins:    "LEFT JOIN titles ON titles.id = teaching_assistants.title_id",
+		title:    "COALESCE(titles.title, '') || teaching_assistants.firstname || ' ' || teaching_assistants.lastname",
+		subtitle: "teaching_assistants.email",
+		columns: []searchColumn{
+			{expr: "(teaching_assistants.firstname || ' ' || teaching_assistants.lastname)"},
+			{expr: "teaching_assistants.email"},
+		},
+	},
+	{
+		key:      "rooms",
+		table:    "rooms",
+		title:    "rooms.name",
+		subtitle: "rooms.building",
+		columns: []searchColumn{
+			{expr: "rooms.name"},
+		},
+	},
+}
+
+// query SQL ค้นหาและจัดอันดับ: ตรงทั้งคำ > ขึ้นต้นด้วย > มีคำค้นอยู่ และคะแนนความคล้าย (pg_trgm) สำหรับพิมพ์ผิดเล็กน้อย
+func (t searchTarget) query(q string, limit int, trigram bool) (string, []interface{}) {
+	var scores, matches []string
+	var scoreArgs, matchArgs []interface{}
+	for _, col := range t.columns {
+		term := q
+		if col.compact {
+			term = services.CompactCode(q)
+		}
+		like := services.EscapeLike(term)
+
+		score := fmt.Sprintf("CASE WHEN lower(%[1]s) = lower(?) THEN 1.0 WHEN %[1]s ILIKE ? THEN 0.8 WHEN %[1]s ILIKE ? THEN 0.6 ELSE 0 END", col.expr)
+		scoreArgs = append(scoreArgs, term, like+"%", "%"+like+"%")
+		match := fmt.Sprintf("%s ILIKE ?", col.expr)
+		matchArgs = append(matchArgs, "%"+like+"%")
+		if trigram {
+			score = fmt.Sprintf("GREATEST(%s, word_similarity(?, %s) * 0.5)", score, col.expr)
+			scoreArgs = append(scoreArgs, term)
+			match = fmt.Sprintf("(%s OR ? <%% %s)", match, col.expr)
+			matchArgs = append(matchArgs, term)
+		}
+		scores = append(scores, score)
+		matches = append(matches, match)
+	}
+
+	where := t.table + ".deleted_at IS NULL AND (" + strings.Join(matches, " OR ") + ")"
+	if t.where != "" {
+		where += " AND " + t.where
+	}
+	sql := fmt.Sprintf(`SELECT %[1]s.id AS id, %[2]s AS title, %[3]s AS subtitle, GREATEST(%[4]s) AS score
+		FROM %[1]s %[5]s WHERE %[6]s ORDER BY score DESC, title LIMIT ?`,
+		t.table, t.title, t.subtitle, strings.Join(scores, ", "), t.joins, where)
+
+	args := append(scoreArgs, matchArgs...)
+	return sql, append(args, limit)
+}
+
+// GET /search?q=คอมพิวเตอร์[&types=courses,rooms][&limit=10] ค้นหารายวิชา ผู้สอน ผู้ช่วยสอน และห้องเรียน
+func Search(c *gin.Context) {
+	q := services.NormalizeSearchQuery(c.Query("q"))
+	if q == "" {
+		respondError(c, http.StatusBadRequest, "ต้องระบุคำค้นหา (q)")
+		return
+	}
+
+	limit := defaultSearchLimit
+	if v := c.Query("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxSearchLimit {
+			respondError(c, http.StatusBadRequest, fmt.Sprintf("limit ต้องอยู่ระหว่าง 1 ถึง %d", maxSearchLimit))
+			return
+		}
+		limit = n
+	}
+
+	wanted := map[string]bool{}
+	if v := c.Query("types"); v != "" {
+		known := map[string]bool{}
+		for _, t := range searchTargets {
+			known[t.key] = true
+		}
+		for _, key := range strings.Split(v, ",") {
+			key = strings.TrimSpace(key)
+			if !known[key] {
+				respondError(c, http.StatusBadRequest, "ไม่รู้จักประเภท " + key)
+				return
+			}
+			wanted[key] = true
+		}
+	}
+
+	results := gin.H{}
+	total := 0
+	for _, t := range searchTargets {
+		if len(wanted) > 0 && !wanted[t.key] {
+			continue
+		}
+		sql, args := t.query(q, limit, config.TrigramEnabled())
+		hits := []SearchHit{}
+		if err := config.DB().Raw(sql, args...).Scan(&hits).Error; err != nil {
+			respondServerError(c, http.StatusInternalServerError, "ค้นหาไม่สำเร็จ", err)
+			return
+		}
+		results[t.key] = hits
+		total += len(hits)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"query": q, "total": total, "results": results})
+}
@@ -0,0 +1,223 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/Nichakorn25/CPE-Teaching-Schedule/config"
+	"github.com/Nichakorn25/CPE-Teaching-Schedule/entity"
+)
+
+type (
+	SectionResp struct {
+		ID                 uint
+		SectionNumber      uint
+		Capacity           uint
+		Enrolled           uint
+		RoomID             *uint
+		Room               string
+		Instructors        []SectionInstructorResp
+		TeachingAssistants []TAResponse
+	}
+
+	UpdateSectionInput struct {
+		Capacity *uint `json:"capacity" binding:"omitempty,gt=0"`
+		Enrolled *uint `json:"enrolled"`
+		RoomID   *uint `json:"room_id"`
+	}
+)
+
+// sectionsByNumber จัด Section ที่ preload มากับรายวิชาเป็น map ตามเลขกลุ่ม
+func sectionsByNumber(oc entity.OfferedCourses) map[uint]entity.Section {
+	m := make(map[uint]entity.Section, len(oc.Sections))
+	for _, s := range oc.Sections {
+		m[s.SectionNumber] = s
+	}
+	return m
+}
+
+func sectionCapacity(sec entity.Section, fallback uint) uint {
+	if sec.ID != 0 && sec.Capacity > 0 {
+		return sec.Capacity
+	}
+	return fallback
+}
+
+func sectionRoomName(sec entity.Section, fallback string) string {
+	if sec.RoomID != nil && sec.Room.ID != 0 {
+		return sec.Room.Name
+	}
+	return fallback
+}
+
+// ต้อง preload TeachingAssistants.TeachingAssistant.Title
+func sectionTeachingAssistants(sec entity.Section) []TAResponse {
+	tas := []TAResponse{}
+	for _, sta := range sec.TeachingAssistants {
+		ta := sta.TeachingAssistant
+		if ta.ID == 0 {
+			continue
+		}
+		tas = append(tas, TAResponse{
+			ID:        ta.ID,
+			Title:     ta.Title.Title,
+			Firstname: ta.Firstname,
+			Lastname:  ta.Lastname,
+		})
+	}
+	return tas
+}
+
+// placeholderSections แถวของทุกกลุ่มเรียนสำหรับวิชาที่ยังไม่มีคาบในตาราง
+func placeholderSections(oc entity.OfferedCourses, sections map[uint]entity.Section, room string, instructors []string) []SectionDetail {
+	if len(sections) == 0 {
+		return []SectionDetail{{
+			SectionNumber:      oc.Section,
+			Room:               room,
+			Capacity:           oc.Capacity,
+			InstructorNames:    instructors,
+			TeachingAssistants: []TAResponse{},
+		}}
+	}
+
+	rows := make([]SectionDetail, 0, len(sections))
+	for n := uint(1); n <= oc.Section; n++ {
+		sec, ok := sections[n]
+		if !ok {
+			continue
+		}
+		rows = append(rows, SectionDetail{
+			SectionID:          sec.ID,
+			SectionNumber:      n,
+			Room:               sectionRoomName(sec, room),
+			Capacity:           sectionCapacity(sec, oc.Capacity),
+			InstructorNames:    sectionInstructorNames(oc, n, instructors),
+			TeachingAssistants: sectionTeachingAssistants(sec),
+		})
+	}
+	return rows
+}
+
+// GET /offered-courses/:id/sections
+func GetSections(c *gin.Context) {
+	var oc entity.OfferedCourses
+	if err := config.DB().First(&oc, c.Param("id")).Error; err != nil {
+		respondError(c, http.StatusNotFound, "ไม่พบรายวิชาที่เปิดสอน")
+		return
+	}
+
+	if err := config.DB().Transaction(func(tx *gorm.DB) error {
+		_, err := config.EnsureSections(tx, oc)
+		return err
+	}); err != nil {
+		respondSectionsError(c, err, "ไม่สามารถเตรียมกลุ่มเรียนได้")
+		return
+	}
+
+	var sections []entity.Section
+	if err := config.DB().
+		Preload("Room").
+		Preload("Instructors.User.Title").
+		Preload("TeachingAssistants.TeachingAssistant.Title").
+		Where("offered_courses_id = ?", oc.ID).
+		Order("section_number").
+		Find(&sections).Error; err != nil {
+		respondServerError(c, http.StatusInternalServerError, "ไม่สามารถดึงข้อมูลกลุ่มเรียนได้", err)
+		return
+	}
+
+	resp := make([]SectionResp, 0, len(sections))
+	for _, sec := range sections {
+		item := SectionResp{
+			ID:                 sec.ID,
+			SectionNumber:      sec.SectionNumber,
+			Capacity:           sec.Capacity,
+			Enrolled:           sec.Enrolled,
+			RoomID:             sec.RoomID,
+			Room:               sectionRoomName(sec, ""),
+			Instructors:        []SectionInstructorResp{},
+			TeachingAssistants: sectionTeachingAssistants(sec),
+		}
+		for _, si := range sec.Instructors {
+			item.Instructors = append(item.Instructors, SectionInstructorResp{
+				UserID:       si.UserID,
+				Fullname:     instructorFullname(si.User),
+				LectureHours: si.LectureHours,
+				LabHours:     si.LabHours,
+			})
+		}
+		resp = append(resp, item)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"offered_courses_id": oc.ID, "sections": resp})
+}
+
+// PUT /sections/:id แก้ไขที่นั่ง จำนวนผู้ลงทะเบียน และห้องประจำกลุ่ม
+func UpdateSection(c *gin.Context) {
+	var sec entity.Section
+	if err := config.DB().First(&sec, c.Param("id")).Error; err != nil {
+		respondError(c, http.StatusNotFound, "ไม่พบกลุ่มเรียน")
+		return
+	}
+
+	var input UpdateSectionInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	if input.Capacity != nil {
+		sec.Capacity = *input.Capacity
+	}
+	if input.Enrolled != nil {
+		sec.Enrolled = *input.Enrolled
+	}
+	if !validateEntity(c, sec) {
+		return
+	}
+	if sec.Enrolled > sec.Capacity {
+		respondError(c, http.StatusBadRequest, "จำนวนผู้ลงทะเบียนเกินจำนวนที่นั่งของกลุ่ม")
+		return
+	}
+
+	updates := map[string]interface{}{
+		"capacity": sec.Capacity,
+		"enrolled": sec.Enrolled,
+	}
+	if input.RoomID != nil {
+		if *input.RoomID == 0 {
+			updates["room_id"] = gorm.Expr("NULL")
+		} else {
+			var room entity.Room
+			if err := config.DB().First(&room, *input.RoomID).Error; err != nil {
+				respondError(c, http.StatusBadRequest, "ไม่พบห้องเรียนที่ระบุ")
+				return
+			}
+			if room.Capacity < sec.Capacity {
+				respondError(c, http.StatusBadRequest, "ห้องเรียนจุไม่พอสำหรับจำนวนที่นั่งของกลุ่ม")
+				return
+			}
+			updates["room_id"] = room.ID
+		}
+	}
+
+	if err := config.DB().Model(&sec).Updates(updates).Error; err != nil {
+		respondServerError(c, http.StatusInternalServerError, "ไม่สามารถแก้ไขกลุ่มเรียนได้", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "แก้ไขกลุ่มเรียนสำเร็จ", "data": sec})
+}
+
+// respondSectionsError ตอบข้อผิดพลาดจากการเตรียมกลุ่มเรียน: 409 เมื่อลดจำนวนกลุ่มที่ยังมีตารางสอน นอกนั้น 500
+func respondSectionsError(c *gin.Context, err error, message string) {
+	var scheduled *config.ScheduledSectionsError
+	if errors.As(err, &scheduled) {
+		respondError(c, http.StatusConflict, "ลดจำนวนกลุ่มเรียนไม่ได้ เนื่องจากกลุ่มที่จะถูกลบยังมีตารางสอนอยู่", gin.H{"sections": scheduled.Sections})
+		return
+	}
+	respondServerError(c, http.StatusInternalServerError, message, err)
+}
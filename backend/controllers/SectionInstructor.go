@@ -0,0 +1,256 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/Nichakorn25/CPE-Teaching-Schedule/config"
+	"github.com/Nichakorn25/CPE-Teaching-Schedule/entity"
+)
+
+type (
+	SectionInstructorInput struct {
+		UserID       uint `binding:"required"`
+		LectureHours *uint
+		LabHours     *uint
+	}
+
+	SectionInstructorsInput struct {
+		SectionNumber uint `binding:"required"`
+		Instructors   []SectionInstructorInput
+	}
+
+	UpdateSectionInstructorsRequest struct {
+		Sections []SectionInstructorsInput
+	}
+
+	SectionInstructorResp struct {
+		UserID       uint
+		Fullname     string
+		LectureHours *uint
+		LabHours     *uint
+	}
+
+	SectionInstructorsResp struct {
+		SectionNumber uint
+		Instructors   []SectionInstructorResp
+	}
+)
+
+type sectionKey struct {
+	OfferedCoursesID uint
+	SectionNumber    uint
+}
+
+// instructorIndex บอกว่าแต่ละกลุ่มเรียนมีผู้สอนคนใดบ้าง ใช้ตรวจอาจารย์สอนชนในตัวจัดตาราง
+// กลุ่มที่ยังไม่กำหนดผู้สอนรายกลุ่มจะใช้ OfferedCourses.UserID ตามเดิม
+type instructorIndex struct {
+	assigned map[sectionKey][]uint
+	owner    map[uint]uint
+}
+
+func loadInstructorIndex(year, term string) (*instructorIndex, error) {
+	ix := &instructorIndex{
+		assigned: make(map[sectionKey][]uint),
+		owner:    make(map[uint]uint),
+	}
+
+	var offered []entity.OfferedCourses
+	if err := config.DB().
+		Preload("SectionInstructors").
+		Where("year = ? AND term = ?", year, term).
+		Find(&offered).Error; err != nil {
+		return nil, err
+	}
+
+	for _, oc := range offered {
+		ix.owner[oc.ID] = oc.UserID
+		for _, si := range oc.SectionInstructors {
+			key := sectionKey{oc.ID, si.SectionNumber}
+			ix.assigned[key] = append(ix.assigned[key], si.UserID)
+		}
+	}
+	return ix, nil
+}
+
+func (ix *instructorIndex) teachers(offeredCoursesID, section uint) []uint {
+	if ids, ok := ix.assigned[sectionKey{offeredCoursesID, section}]; ok {
+		return ids
+	}
+	if uid, ok := ix.owner[offeredCoursesID]; ok && uid != 0 {
+		return []uint{uid}
+	}
+	return nil
+}
+
+func (ix *instructorIndex) teaches(userID, offeredCoursesID, section uint) bool {
+	for _, uid := range ix.teachers(offeredCoursesID, section) {
+		if uid == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// teachesSchedule ผู้สอน userID สอนกลุ่มเรียนของคาบ s หรือไม่
+func (ix *instructorIndex) teachesSchedule(userID uint, s entity.Schedule) bool {
+	return ix.teaches(userID, s.OfferedCoursesID, s.SectionNumber)
+}
+
+func instructorFullname(u entity.User) string {
+	return u.Title.Title + " " + u.Firstname + " " + u.Lastname
+}
+
+// sectionInstructorNames ชื่อผู้สอนของกลุ่มเรียน ถ้ากลุ่มยังไม่กำหนดผู้สอนรายกลุ่มจะใช้ fallback (ผู้สอนทั้งหมดของวิชา)
+// ต้อง preload SectionInstructors.User.Title
+func sectionInstructorNames(oc entity.OfferedCourses, section uint, fallback []string) []string {
+	names := []string{}
+	for _, si := range oc.SectionInstructors {
+		if si.SectionNumber == section {
+			names = append(names, instructorFullname(si.User))
+		}
+	}
+	if len(names) == 0 {
+		return fallback
+	}
+	return names
+}
+
+// GET /offered-courses/:id/instructors
+func GetSectionInstructors(c *gin.Context) {
+	var oc entity.OfferedCourses
+	if err := config.DB().
+		Preload("SectionInstructors", func(db *gorm.DB) *gorm.DB { return db.Order("section_number, id") }).
+		Preload("SectionInstructors.User.Title").
+		First(&oc, c.Param("id")).Error; err != nil {
+		respondError(c, http.StatusNotFound, "ไม่พบรายวิชาที่เปิดสอน")
+		return
+	}
+
+	resp := make([]SectionInstructorsResp, 0, oc.Section)
+	for sec := uint(1); sec <= oc.Section; sec++ {
+		item := SectionInstructorsResp{SectionNumber: sec, Instructors: []SectionInstructorResp{}}
+		for _, si := range oc.SectionInstructors {
+			if si.SectionNumber != sec {
+				continue
+			}
+			item.Instructors = append(item.Instructors, SectionInstructorResp{
+				UserID:       si.UserID,
+				Fullname:     instructorFullname(si.User),
+				LectureHours: si.LectureHours,
+				LabHours:     si.LabHours,
+			})
+		}
+		resp = append(resp, item)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"offered_courses_id": oc.ID, "sections": resp})
+}
+
+// PUT /offered-courses/:id/instructors แทนที่ผู้สอนรายกลุ่มทั้งหมดของวิชาที่เปิดสอน
+func UpdateSectionInstructors(c *gin.Context) {
+	var oc entity.OfferedCourses
+	if err := config.DB().Preload("AllCourses.Credit").First(&oc, c.Param("id")).Error; err != nil {
+		respondError(c, http.StatusNotFound, "ไม่พบรายวิชาที่เปิดสอน")
+		return
+	}
+
+	var req UpdateSectionInstructorsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	hoursRules, err := config.LoadHoursRules()
+	if err != nil {
+		respondServerError(c, http.StatusInternalServerError, "โหลดกติกาชั่วโมงสอนไม่สำเร็จ", err)
+		return
+	}
+	hours := hoursRules.For(oc.AllCourses)
+	lecHours, labHours := hours.Lecture, hours.Lab
+
+	rows := make([]entity.SectionInstructor, 0)
+	userIDs := map[uint]bool{}
+	for _, sec := range req.Sections {
+		if sec.SectionNumber > oc.Section {
+			respondError(c, http.StatusBadRequest, fmt.Sprintf("กลุ่มที่ %d เกินจำนวนกลุ่มของวิชา (%d)", sec.SectionNumber, oc.Section))
+			return
+		}
+
+		var sumLec, sumLab uint
+		seen := map[uint]bool{}
+		for _, in := range sec.Instructors {
+			if seen[in.UserID] {
+				respondError(c, http.StatusBadRequest, fmt.Sprintf("ผู้สอนซ้ำในกลุ่มที่ %d", sec.SectionNumber))
+				return
+			}
+			seen[in.UserID] = true
+			userIDs[in.UserID] = true
+
+			if in.LectureHours != nil {
+				sumLec += *in.LectureHours
+			}
+			if in.LabHours != nil {
+				sumLab += *in.LabHours
+			}
+			rows = append(rows, entity.SectionInstructor{
+				OfferedCoursesID: oc.ID,
+				SectionNumber:    sec.SectionNumber,
+				UserID:           in.UserID,
+				LectureHours:     in.LectureHours,
+				LabHours:         in.LabHours,
+			})
+		}
+
+		if sumLec > uint(lecHours) || sumLab > uint(labHours) {
+			respondError(c, http.StatusBadRequest, fmt.Sprintf("ชั่วโมงที่แบ่งให้ผู้สอนในกลุ่มที่ %d เกินชั่วโมงของรายวิชา", sec.SectionNumber), gin.H{
+				"lecture_hours": lecHours,
+				"lab_hours":     labHours,
+			})
+			return
+		}
+	}
+
+	if len(userIDs) > 0 {
+		ids := make([]uint, 0, len(userIDs))
+		for id := range userIDs {
+			ids = append(ids, id)
+		}
+		var count int64
+		if err := config.DB().Model(&entity.User{}).Where("id IN ?", ids).Count(&count).Error; err != nil || count != int64(len(ids)) {
+			respondError(c, http.StatusBadRequest, "ไม่พบผู้สอนบางคนที่ระบุ")
+			return
+		}
+	}
+
+	if !validateEntity(c, rows) {
+		return
+	}
+	err = config.DB().Transaction(func(tx *gorm.DB) error {
+		sectionIDs, err := config.EnsureSections(tx, oc)
+		if err != nil {
+			return err
+		}
+		for i := range rows {
+			if id, ok := sectionIDs[rows[i].SectionNumber]; ok {
+				rows[i].SectionID = &id
+			}
+		}
+		if err := tx.Where("offered_courses_id = ?", oc.ID).Delete(&entity.SectionInstructor{}).Error; err != nil {
+			return err
+		}
+		if len(rows) > 0 {
+			return tx.Create(&rows).Error
+		}
+		return nil
+	})
+	if err != nil {
+		respondSectionsError(c, err, "ไม่สามารถบันทึกผู้สอนรายกลุ่มได้")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "บันทึกผู้สอนรายกลุ่มสำเร็จ", "count": len(rows)})
+}
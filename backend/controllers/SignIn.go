@@ -24,19 +24,19 @@ func SignInUser(c *gin.Context) {
 	var user entity.User
 
 	if err := c.ShouldBindJSON(&payload); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBindError(c, err)
 		return
 	}
 
 	if err := config.DB().Preload("Title").Preload("Position").Preload("Major").Preload("Role").
 		Where("username = ?", payload.Username).
 		First(&user).Error; err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "ไม่พบ Username นี้"})
+		respondError(c, http.StatusUnauthorized, "ไม่พบ Username นี้")
 		return
 	}
 
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(payload.Password)); err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "รหัสผ่านไม่ถูกต้อง"})
+		respondError(c, http.StatusUnauthorized, "รหัสผ่านไม่ถูกต้อง")
 		return
 	}
 
@@ -48,7 +48,7 @@ func SignInUser(c *gin.Context) {
 
 	signedToken, err := jwtWrapper.GenerateToken(user.Username)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "ไม่สามารถสร้าง token ได้"})
+		respondError(c, http.StatusInternalServerError, "ไม่สามารถสร้าง token ได้")
 		return
 	}
 
@@ -78,24 +78,24 @@ type Password struct {
 func ChangePassword(c *gin.Context) {
 	var input Password
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "ข้อมูลไม่ถูกต้อง: " + err.Error()})
+		respondBindError(c, err)
 		return
 	}
 
 	if input.NewPassword != input.ConfirmPassword {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "รหัสผ่านใหม่และยืนยันรหัสผ่านไม่ตรงกัน"})
+		respondError(c, http.StatusBadRequest, "รหัสผ่านใหม่และยืนยันรหัสผ่านไม่ตรงกัน")
 		return
 	}
 
 	var user entity.User
 	if err := config.DB().Where("email = ?", input.Email).First(&user).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "ไม่พบผู้ใช้งานด้วยอีเมลนี้"})
+		respondError(c, http.StatusNotFound, "ไม่พบผู้ใช้งานด้วยอีเมลนี้")
 		return
 	}
 
 	hashedPassword, err := config.HashPassword(input.NewPassword)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "การเข้ารหัสผ่านล้มเหลว"})
+		respondError(c, http.StatusInternalServerError, "การเข้ารหัสผ่านล้มเหลว")
 		return
 	}
 
@@ -106,7 +106,7 @@ func ChangePassword(c *gin.Context) {
 	}
 
 	if err := config.DB().Save(&user).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "การอัปเดตรหัสผ่านล้มเหลว"})
+		respondError(c, http.StatusInternalServerError, "การอัปเดตรหัสผ่านล้มเหลว")
 		return
 	}
 
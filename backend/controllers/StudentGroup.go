@@ -0,0 +1,376 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/Nichakorn25/CPE-Teaching-Schedule/config"
+	"github.com/Nichakorn25/CPE-Teaching-Schedule/entity"
+)
+
+type (
+	StudentGroupInput struct {
+		Name         string `json:"name" binding:"required"`
+		EntryYear    uint   `json:"entry_year" binding:"required"`
+		Size         uint   `json:"size" binding:"required,gt=0"`
+		MajorID      uint   `json:"major_id" binding:"required"`
+		CurriculumID *uint  `json:"curriculum_id"`
+	}
+
+	SectionStudentGroupsInput struct {
+		StudentGroupIDs []uint `json:"student_group_ids"`
+	}
+
+	GroupSession struct {
+		ScheduleID    uint
+		SectionID     uint
+		Code          string
+		CourseName    string
+		SectionNumber uint
+		DayOfWeek     string
+		Start         string
+		End           string
+		Kind          string
+		Room          string
+	}
+)
+
+// groupIndex กลุ่มนักศึกษาของแต่ละกลุ่มเรียน ใช้ตรวจกลุ่มนักศึกษาเรียนชนกันในตัวจัดตาราง
+type groupIndex struct {
+	bySection map[uint][]uint
+}
+
+func loadGroupIndex() (*groupIndex, error) {
+	gi := &groupIndex{bySection: make(map[uint][]uint)}
+	var rows []entity.SectionStudentGroup
+	if err := config.DB().Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, r := range rows {
+		gi.bySection[r.SectionID] = append(gi.bySection[r.SectionID], r.StudentGroupID)
+	}
+	return gi, nil
+}
+
+func (gi *groupIndex) has(sectionID *uint) bool {
+	return sectionID != nil && len(gi.bySection[*sectionID]) > 0
+}
+
+// shares สองกลุ่มเรียนมีกลุ่มนักศึกษาร่วมกัน
+func (gi *groupIndex) shares(a, b *uint) bool {
+	if !gi.has(a) || !gi.has(b) {
+		return false
+	}
+	for _, x := range gi.bySection[*a] {
+		for _, y := range gi.bySection[*b] {
+			if x == y {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (in StudentGroupInput) apply(g *entity.StudentGroup) string {
+	if err := config.DB().First(&entity.Major{}, in.MajorID).Error; err != nil {
+		return "ไม่พบสาขาวิชา"
+	}
+	if in.CurriculumID != nil {
+		var cur entity.Curriculum
+		if err := config.DB().First(&cur, *in.CurriculumID).Error; err != nil {
+			return "ไม่พบหลักสูตร"
+		}
+		if cur.MajorID != in.MajorID {
+			return "หลักสูตรไม่ได้อยู่ในสาขาวิชานี้"
+		}
+	}
+	g.Name = in.Name
+	g.EntryYear = in.EntryYear
+	g.Size = in.Size
+	g.MajorID = in.MajorID
+	g.CurriculumID = in.CurriculumID
+	return ""
+}
+
+// GET /student-groups
+func GetStudentGroups(c *gin.Context) {
+	var groups []entity.StudentGroup
+	q := config.DB().Preload("Major").Preload("Curriculum").Order("entry_year DESC, name")
+	if majorID := c.Query("major_id"); majorID != "" {
+		q = q.Where("major_id = ?", majorID)
+	}
+	if err := q.Find(&groups).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, "ไม่สามารถดึงกลุ่มนักศึกษาได้")
+		return
+	}
+	c.JSON(http.StatusOK, groups)
+}
+
+// GET /student-groups/:id
+func GetStudentGroupByID(c *gin.Context) {
+	var group entity.StudentGroup
+	if err := config.DB().Preload("Major").Preload("Curriculum").First(&group, c.Param("id")).Error; err != nil {
+		respondError(c, http.StatusNotFound, "ไม่พบกลุ่มนักศึกษา")
+		return
+	}
+	c.JSON(http.StatusOK, group)
+}
+
+// POST /student-groups
+func CreateStudentGroup(c *gin.Context) {
+	var input StudentGroupInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		respondBindError(c, err)
+		return
+	}
+	var group entity.StudentGroup
+	if msg := input.apply(&group); msg != "" {
+		respondError(c, http.StatusBadRequest, msg)
+		return
+	}
+	if !validateEntity(c, group) {
+		return
+	}
+	if err := config.DB().Create(&group).Error; err != nil {
+		respondError(c, http.StatusConflict, "ชื่อกลุ่มนักศึกษาซ้ำ")
+		return
+	}
+	c.JSON(http.StatusCreated, group)
+}
+
+// PUT /student-groups/:id
+func UpdateStudentGroup(c *gin.Context) {
+	var group entity.StudentGroup
+	if err := config.DB().First(&group, c.Param("id")).Error; err != nil {
+		respondError(c, http.StatusNotFound, "ไม่พบกลุ่มนักศึกษา")
+		return
+	}
+	var input StudentGroupInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		respondBindError(c, err)
+		return
+	}
+	if msg := input.apply(&group); msg != "" {
+		respondError(c, http.StatusBadRequest, msg)
+		return
+	}
+	if !validateEntity(c, group) {
+		return
+	}
+	if err := config.DB().Save(&group).Error; err != nil {
+		respondError(c, http.StatusConflict, "ชื่อกลุ่มนักศึกษาซ้ำ")
+		return
+	}
+	c.JSON(http.StatusOK, group)
+}
+
+// DELETE /student-groups/:id
+func DeleteStudentGroup(c *gin.Context) {
+	var group entity.StudentGroup
+	if err := config.DB().First(&group, c.Param("id")).Error; err != nil {
+		respondError(c, http.StatusNotFound, "ไม่พบกลุ่มนักศึกษา")
+		return
+	}
+	// ลบถาวรเพื่อให้ใช้ชื่อกลุ่มเดิมได้อีก (uniqueIndex)
+	if err := config.DB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("student_group_id = ?", group.ID).Delete(&entity.SectionStudentGroup{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(&group).Error
+	}); err != nil {
+		respondError(c, http.StatusInternalServerError, "ไม่สามารถลบกลุ่มนักศึกษาได้")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "ลบกลุ่มนักศึกษาสำเร็จ"})
+}
+
+// sessionsOfSections คาบของกลุ่มเรียนในตารางทุกตาราง (พร้อมรายวิชาและห้อง)
+func sessionsOfSections(sectionIDs []uint) ([]entity.Schedule, error) {
+	var schedules []entity.Schedule
+	err := config.DB().
+		Preload("OfferedCourses.AllCourses").
+		Preload("OfferedCourses.Aliases.AllCourses").
+		Preload("Room").
+		Where("section_id IN ?", append([]uint{0}, sectionIDs...)).
+		Find(&schedules).Error
+	return schedules, err
+}
+
+// groupClashes คู่คาบที่ทับกันในตารางเดียวกันของกลุ่มนักศึกษา
+func groupClashes(sessions []entity.Schedule) []string {
+	clashes := []string{}
+	for i := range sessions {
+		for j := i + 1; j < len(sessions); j++ {
+			a, b := sessions[i], sessions[j]
+			if a.NameTable != b.NameTable || a.DayOfWeek != b.DayOfWeek ||
+				overlapMinutes(clockMinutes(a.StartTime), clockMinutes(a.EndTime), clockMinutes(b.StartTime), clockMinutes(b.EndTime)) == 0 {
+				continue
+			}
+			clashes = append(clashes, fmt.Sprintf("%s กลุ่ม %d ชนกับ %s กลุ่ม %d วัน%s",
+				offeredCodeLabel(a.OfferedCourses), a.SectionNumber,
+				offeredCodeLabel(b.OfferedCourses), b.SectionNumber, a.DayOfWeek))
+		}
+	}
+	return clashes
+}
+
+// PUT /sections/:id/student-groups แทนที่กลุ่มนักศึกษาที่เรียนในกลุ่มเรียนนี้
+func UpdateSectionStudentGroups(c *gin.Context) {
+	var sec entity.Section
+	if err := config.DB().First(&sec, c.Param("id")).Error; err != nil {
+		respondError(c, http.StatusNotFound, "ไม่พบกลุ่มเรียน")
+		return
+	}
+
+	var input SectionStudentGroupsInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	var groups []entity.StudentGroup
+	if err := config.DB().Where("id IN ?", append([]uint{0}, input.StudentGroupIDs...)).Find(&groups).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, "ไม่สามารถดึงกลุ่มนักศึกษาได้")
+		return
+	}
+	unique := map[uint]bool{}
+	for _, id := range input.StudentGroupIDs {
+		unique[id] = true
+	}
+	if len(groups) != len(unique) {
+		respondError(c, http.StatusBadRequest, "มีกลุ่มนักศึกษาที่ไม่พบในระบบ")
+		return
+	}
+	links := make([]entity.SectionStudentGroup, 0, len(groups))
+	for _, g := range groups {
+		links = append(links, entity.SectionStudentGroup{SectionID: sec.ID, StudentGroupID: g.ID})
+	}
+	if !validateEntity(c, links) {
+		return
+	}
+
+	// กลุ่มนักศึกษาต้องไม่มีคาบอื่นทับกับคาบของกลุ่มเรียนนี้
+	var own []entity.Schedule
+	if err := config.DB().Where("section_id = ?", sec.ID).Find(&own).Error; err != nil {
+		respondServerError(c, http.StatusInternalServerError, "ไม่สามารถดึงตารางของกลุ่มเรียนได้", err)
+		return
+	}
+	for _, g := range groups {
+		var otherSections []uint
+		if err := config.DB().Model(&entity.SectionStudentGroup{}).
+			Where("student_group_id = ? AND section_id <> ?", g.ID, sec.ID).
+			Pluck("section_id", &otherSections).Error; err != nil {
+			respondServerError(c, http.StatusInternalServerError, "ไม่สามารถดึงกลุ่มเรียนของกลุ่มนักศึกษาได้", err)
+			return
+		}
+		others, err := sessionsOfSections(otherSections)
+		if err != nil {
+			respondError(c, http.StatusInternalServerError, "ไม่สามารถตรวจสอบตารางของกลุ่มนักศึกษาได้")
+			return
+		}
+		for _, s := range own {
+			for _, o := range others {
+				if s.NameTable == o.NameTable && s.DayOfWeek == o.DayOfWeek &&
+					overlapMinutes(clockMinutes(s.StartTime), clockMinutes(s.EndTime), clockMinutes(o.StartTime), clockMinutes(o.EndTime)) > 0 {
+					respondError(c, http.StatusConflict, fmt.Sprintf("กลุ่ม %s มีคาบ %s กลุ่ม %d ชนวัน%s %s-%s",
+						g.Name, offeredCodeLabel(o.OfferedCourses), o.SectionNumber, o.DayOfWeek,
+						o.StartTime.Format("15:04"), o.EndTime.Format("15:04")))
+					return
+				}
+			}
+		}
+	}
+
+	if err := config.DB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("section_id = ?", sec.ID).Delete(&entity.SectionStudentGroup{}).Error; err != nil {
+			return err
+		}
+		for i := range links {
+			if err := tx.Create(&links[i]).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		respondError(c, http.StatusInternalServerError, "ไม่สามารถบันทึกกลุ่มนักศึกษาของกลุ่มเรียนได้")
+		return
+	}
+
+	warnings := []string{}
+	total := uint(0)
+	for _, g := range groups {
+		total += g.Size
+	}
+	if sec.Capacity > 0 && total > sec.Capacity {
+		warnings = append(warnings, fmt.Sprintf("จำนวนนักศึกษารวม %d คน เกินที่นั่งของกลุ่มเรียน (%d)", total, sec.Capacity))
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "บันทึกกลุ่มนักศึกษาของกลุ่มเรียนสำเร็จ", "warnings": warnings})
+}
+
+// GET /student-groups/:id/timetable?year=2568&term=1 ตารางเรียนของกลุ่มนักศึกษา
+func GetStudentGroupTimetable(c *gin.Context) {
+	var group entity.StudentGroup
+	if err := config.DB().First(&group, c.Param("id")).Error; err != nil {
+		respondError(c, http.StatusNotFound, "ไม่พบกลุ่มนักศึกษา")
+		return
+	}
+
+	var sectionIDs []uint
+	config.DB().Model(&entity.SectionStudentGroup{}).Where("student_group_id = ?", group.ID).Pluck("section_id", &sectionIDs)
+	sessions, err := sessionsOfSections(sectionIDs)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "ไม่สามารถดึงตารางเรียนได้")
+		return
+	}
+
+	year, term := c.Query("year"), c.Query("term")
+	nameTable := ""
+	if year != "" && term != "" {
+		nameTable = fmt.Sprintf("ปีการศึกษา %s เทอม %s", year, term)
+		filtered := sessions[:0]
+		for _, s := range sessions {
+			if s.NameTable == nameTable {
+				filtered = append(filtered, s)
+			}
+		}
+		sessions = filtered
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		a, b := sessions[i], sessions[j]
+		if a.NameTable != b.NameTable {
+			return a.NameTable < b.NameTable
+		}
+		if da, db := dayIndex(a.DayOfWeek), dayIndex(b.DayOfWeek); da != db {
+			return da < db
+		}
+		return clockMinutes(a.StartTime) < clockMinutes(b.StartTime)
+	})
+
+	items := make([]GroupSession, 0, len(sessions))
+	for _, s := range sessions {
+		items = append(items, GroupSession{
+			ScheduleID:    s.ID,
+			SectionID:     *s.SectionID,
+			Code:          offeredCodeLabel(s.OfferedCourses),
+			CourseName:    s.OfferedCourses.AllCourses.ThaiName,
+			SectionNumber: s.SectionNumber,
+			DayOfWeek:     s.DayOfWeek,
+			Start:         s.StartTime.Format("15:04"),
+			End:           s.EndTime.Format("15:04"),
+			Kind:          s.Kind,
+			Room:          s.Room.Name,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"group":      group,
+		"name_table": nameTable,
+		"sessions":   items,
+		"clashes":    groupClashes(sessions),
+	})
+}
@@ -0,0 +1,370 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/Nichakorn25/CPE-Teaching-Schedule/config"
+	"github.com/Nichakorn25/CPE-Teaching-Schedule/entity"
+)
+
+type (
+	StudyPlanEntryInput struct {
+		AcademicYearID  uint
+		Term            uint
+		AllCoursesID    *uint
+		ElectiveSlot    string
+		TypeOfCoursesID *uint
+		Credits         uint
+	}
+
+	StudyPlanRequest struct {
+		Entries []StudyPlanEntryInput
+	}
+
+	StudyPlanCourse struct {
+		EntryID  uint
+		CourseID uint
+		Code     string
+		ThaiName string
+		Credit   uint
+	}
+
+	StudyPlanSlot struct {
+		EntryID         uint
+		Name            string
+		TypeOfCoursesID *uint
+		TypeName        string
+		Credits         uint
+	}
+
+	StudyPlanTerm struct {
+		AcademicYearID uint
+		Level          string
+		Term           uint
+		Courses        []StudyPlanCourse
+		ElectiveSlots  []StudyPlanSlot
+		TotalCredits   uint
+	}
+)
+
+// cohortKey กลุ่มนักศึกษาที่เรียนพร้อมกัน: หลักสูตร + ชั้นปี ในภาคการศึกษาที่จัด
+type cohortKey struct {
+	CurriculumID   uint
+	AcademicYearID uint
+}
+
+// cohortIndex กลุ่มผู้เรียนที่ห้ามเรียนชนกันของเทอมที่กำลังจัด ได้จากแผนการศึกษา
+// หลักสูตรที่ยังไม่มีแผนใช้กติกาเดิม (ชั้นปีของรายวิชาเดียวกัน = ชนกัน)
+type cohortIndex struct {
+	planned  map[uint]bool          // curriculumID ที่มีแผนการศึกษา
+	required map[uint][]cohortKey   // allCoursesID -> กลุ่มที่ต้องเรียนวิชานี้
+	elective map[uint][]cohortKey   // allCoursesID -> กลุ่มที่เลือกเรียนวิชานี้ได้
+	slots    map[cohortKey][]*uint  // ช่องวิชาเลือกของกลุ่ม (ประเภทรายวิชา, nil = ประเภทใดก็ได้)
+	inPlan   map[uint]map[uint]bool // curriculumID -> วิชาบังคับในแผน (ทุกเทอม)
+}
+
+func loadCohortIndex(term string) (*cohortIndex, error) {
+	ci := &cohortIndex{
+		planned:  make(map[uint]bool),
+		required: make(map[uint][]cohortKey),
+		elective: make(map[uint][]cohortKey),
+		slots:    make(map[cohortKey][]*uint),
+		inPlan:   make(map[uint]map[uint]bool),
+	}
+
+	var entries []entity.StudyPlanEntry
+	if err := config.DB().Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		ci.planned[e.CurriculumID] = true
+		if e.AllCoursesID != nil {
+			if ci.inPlan[e.CurriculumID] == nil {
+				ci.inPlan[e.CurriculumID] = make(map[uint]bool)
+			}
+			ci.inPlan[e.CurriculumID][*e.AllCoursesID] = true
+		}
+		if strconv.Itoa(int(e.Term)) != term {
+			continue
+		}
+		key := cohortKey{e.CurriculumID, e.AcademicYearID}
+		if e.AllCoursesID != nil {
+			ci.required[*e.AllCoursesID] = append(ci.required[*e.AllCoursesID], key)
+		} else {
+			ci.slots[key] = append(ci.slots[key], e.TypeOfCoursesID)
+		}
+	}
+
+	// วิชาเลือก: วิชาของหลักสูตรที่ไม่อยู่ในแผนแบบวิชาบังคับ และตรงประเภทของช่องวิชาเลือก
+	if len(ci.slots) > 0 {
+		ids := make([]uint, 0, len(ci.planned))
+		for id := range ci.planned {
+			ids = append(ids, id)
+		}
+		var courses []entity.AllCourses
+		if err := config.DB().Where("curriculum_id IN ?", ids).Find(&courses).Error; err != nil {
+			return nil, err
+		}
+		for _, ac := range courses {
+			if ci.inPlan[ac.CurriculumID][ac.ID] {
+				continue
+			}
+			for key, types := range ci.slots {
+				if key.CurriculumID != ac.CurriculumID {
+					continue
+				}
+				for _, t := range types {
+					if t == nil || *t == ac.TypeOfCoursesID {
+						ci.elective[ac.ID] = append(ci.elective[ac.ID], key)
+						break
+					}
+				}
+			}
+		}
+	}
+	return ci, nil
+}
+
+func (ci *cohortIndex) usesPlan(ac entity.AllCourses) bool {
+	return ci.planned[ac.CurriculumID] || len(ci.required[ac.ID]) > 0
+}
+
+// conflicts สองวิชาอยู่กลุ่มผู้เรียนเดียวกันและอย่างน้อยหนึ่งวิชาเป็นวิชาบังคับของกลุ่ม
+// (วิชาเลือกด้วยกันเองไม่ถือว่าชน เพราะผู้เรียนเลือกเพียงบางวิชา)
+func (ci *cohortIndex) conflicts(a, b entity.AllCourses) bool {
+	if !ci.usesPlan(a) && !ci.usesPlan(b) {
+		return a.AcademicYearID != nil && b.AcademicYearID != nil && *a.AcademicYearID == *b.AcademicYearID
+	}
+
+	groups := func(ac entity.AllCourses) map[cohortKey]bool {
+		m := make(map[cohortKey]bool)
+		for _, k := range ci.required[ac.ID] {
+			m[k] = true
+		}
+		return m
+	}
+	reqA, reqB := groups(a), groups(b)
+	for k := range reqA {
+		if reqB[k] {
+			return true
+		}
+	}
+	for _, k := range ci.elective[a.ID] {
+		if reqB[k] {
+			return true
+		}
+	}
+	for _, k := range ci.elective[b.ID] {
+		if reqA[k] {
+			return true
+		}
+	}
+	return false
+}
+
+// GET /curriculum/:id/study-plan
+func GetStudyPlan(c *gin.Context) {
+	var cur entity.Curriculum
+	if err := config.DB().First(&cur, c.Param("id")).Error; err != nil {
+		respondError(c, http.StatusNotFound, "ไม่พบหลักสูตรที่ต้องการ")
+		return
+	}
+
+	var entries []entity.StudyPlanEntry
+	if err := config.DB().
+		Preload("AcademicYear").
+		Preload("AllCourses.Credit").
+		Preload("TypeOfCourses").
+		Where("curriculum_id = ?", cur.ID).
+		Order("academic_year_id, term, id").
+		Find(&entries).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, "ไม่สามารถดึงแผนการศึกษาได้")
+		return
+	}
+
+	terms := []StudyPlanTerm{}
+	index := map[[2]uint]int{}
+	for _, e := range entries {
+		k := [2]uint{e.AcademicYearID, e.Term}
+		i, ok := index[k]
+		if !ok {
+			i = len(terms)
+			index[k] = i
+			terms = append(terms, StudyPlanTerm{
+				AcademicYearID: e.AcademicYearID,
+				Level:          e.AcademicYear.Level,
+				Term:           e.Term,
+				Courses:        []StudyPlanCourse{},
+				ElectiveSlots:  []StudyPlanSlot{},
+			})
+		}
+		t := &terms[i]
+		if e.AllCoursesID != nil {
+			t.Courses = append(t.Courses, StudyPlanCourse{
+				EntryID:  e.ID,
+				CourseID: e.AllCourses.ID,
+				Code:     e.AllCourses.Code,
+				ThaiName: e.AllCourses.ThaiName,
+				Credit:   e.AllCourses.Credit.Unit,
+			})
+			t.TotalCredits += e.AllCourses.Credit.Unit
+		} else {
+			t.ElectiveSlots = append(t.ElectiveSlots, StudyPlanSlot{
+				EntryID:         e.ID,
+				Name:            e.ElectiveSlot,
+				TypeOfCoursesID: e.TypeOfCoursesID,
+				TypeName:        e.TypeOfCourses.TypeName,
+				Credits:         e.Credits,
+			})
+			t.TotalCredits += e.Credits
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"curriculumId": cur.ID, "curriculumName": cur.CurriculumName, "terms": terms})
+}
+
+// PUT /curriculum/:id/study-plan แทนที่แผนการศึกษาทั้งหมดของหลักสูตร
+func UpdateStudyPlan(c *gin.Context) {
+	var cur entity.Curriculum
+	if err := config.DB().First(&cur, c.Param("id")).Error; err != nil {
+		respondError(c, http.StatusNotFound, "ไม่พบหลักสูตรที่ต้องการ")
+		return
+	}
+
+	var req StudyPlanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	var years []entity.AcademicYear
+	if err := config.DB().Find(&years).Error; err != nil {
+		respondServerError(c, http.StatusInternalServerError, "ไม่สามารถดึงชั้นปีได้", err)
+		return
+	}
+	validYear := make(map[uint]bool, len(years))
+	levels := make(map[uint]int, len(years)) // ชั้นปีที่เป็นตัวเลข (ไม่รวม "เรียนได้ทุกชั้นปี")
+	for _, y := range years {
+		validYear[y.ID] = true
+		if n, err := strconv.Atoi(strings.TrimSpace(y.Level)); err == nil {
+			levels[y.ID] = n
+		}
+	}
+
+	entries := make([]entity.StudyPlanEntry, 0, len(req.Entries))
+	seen := map[uint]bool{}
+	for i, in := range req.Entries {
+		row := i + 1
+		if !validYear[in.AcademicYearID] {
+			respondError(c, http.StatusBadRequest, fmt.Sprintf("แถวที่ %d: ไม่พบชั้นปี", row))
+			return
+		}
+		if in.Term < 1 || in.Term > 3 {
+			respondError(c, http.StatusBadRequest, fmt.Sprintf("แถวที่ %d: ภาคการศึกษาต้องเป็น 1-3", row))
+			return
+		}
+		if (in.AllCoursesID == nil) == (in.ElectiveSlot == "") {
+			respondError(c, http.StatusBadRequest, fmt.Sprintf("แถวที่ %d: ต้องระบุรายวิชาหรือช่องวิชาเลือกอย่างใดอย่างหนึ่ง", row))
+			return
+		}
+		if in.AllCoursesID != nil {
+			var ac entity.AllCourses
+			if err := config.DB().First(&ac, *in.AllCoursesID).Error; err != nil {
+				respondError(c, http.StatusBadRequest, fmt.Sprintf("แถวที่ %d: ไม่พบรายวิชา", row))
+				return
+			}
+			if ac.CurriculumID != cur.ID {
+				respondError(c, http.StatusBadRequest, fmt.Sprintf("แถวที่ %d: รายวิชา %s ไม่อยู่ในหลักสูตรนี้", row, ac.Code))
+				return
+			}
+			if seen[*in.AllCoursesID] {
+				respondError(c, http.StatusBadRequest, fmt.Sprintf("แถวที่ %d: รายวิชาซ้ำในแผนการศึกษา", row))
+				return
+			}
+			seen[*in.AllCoursesID] = true
+		}
+		if in.TypeOfCoursesID != nil {
+			if err := config.DB().First(&entity.TypeOfCourses{}, *in.TypeOfCoursesID).Error; err != nil {
+				respondError(c, http.StatusBadRequest, fmt.Sprintf("แถวที่ %d: ไม่พบประเภทรายวิชา", row))
+				return
+			}
+		}
+
+		entries = append(entries, entity.StudyPlanEntry{
+			Term:            in.Term,
+			ElectiveSlot:    in.ElectiveSlot,
+			Credits:         in.Credits,
+			CurriculumID:    cur.ID,
+			AcademicYearID:  in.AcademicYearID,
+			AllCoursesID:    in.AllCoursesID,
+			TypeOfCoursesID: in.TypeOfCoursesID,
+		})
+	}
+
+	if !validateEntity(c, entries) {
+		return
+	}
+	if err := config.DB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("curriculum_id = ?", cur.ID).Delete(&entity.StudyPlanEntry{}).Error; err != nil {
+			return err
+		}
+		if len(entries) == 0 {
+			return nil
+		}
+		return tx.Create(&entries).Error
+	}); err != nil {
+		respondError(c, http.StatusInternalServerError, "ไม่สามารถบันทึกแผนการศึกษาได้")
+		return
+	}
+
+	// แจ้งวิชาบังคับก่อนที่อยู่ในแผนหลังวิชาที่ต้องใช้ (ไม่ปฏิเสธ)
+	warnings := studyPlanOrderWarnings(entries, levels)
+	c.JSON(http.StatusOK, gin.H{"message": "บันทึกแผนการศึกษาสำเร็จ", "warnings": warnings})
+}
+
+// studyPlanOrderWarnings วิชาที่วางไว้ก่อนหรือพร้อมกับวิชาบังคับก่อนของตัวเองในแผน
+// levels คือชั้นปีของ AcademicYear แต่ละแถว วิชาที่อยู่ในชั้นปีที่ไม่มีลำดับ (เรียนได้ทุกชั้นปี) ไม่ถูกตรวจ
+func studyPlanOrderWarnings(entries []entity.StudyPlanEntry, levels map[uint]int) []string {
+	warnings := []string{}
+	graph, err := config.LoadRequisiteGraph()
+	if err != nil {
+		return warnings
+	}
+
+	// ลำดับของภาค: ชั้นปี x 10 + ภาคการศึกษา
+	order := map[uint]int{}
+	for _, e := range entries {
+		level, ok := levels[e.AcademicYearID]
+		if e.AllCoursesID != nil && ok {
+			order[*e.AllCoursesID] = level*10 + int(e.Term)
+		}
+	}
+	ids := make([]uint, 0, len(order))
+	for id := range order {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	var courses []entity.AllCourses
+	config.DB().Where("id IN ?", append([]uint{0}, ids...)).Find(&courses)
+	codes := make(map[uint]string, len(courses))
+	for _, ac := range courses {
+		codes[ac.ID] = ac.Code
+	}
+
+	for _, id := range ids {
+		for _, pre := range graph.Prerequisites(id) {
+			if at, ok := order[pre]; ok && at >= order[id] {
+				warnings = append(warnings, fmt.Sprintf("%s ต้องเรียน %s ก่อน แต่แผนวาง %s ไว้ในภาคเดียวกันหรือหลังกว่า", codes[id], codes[pre], codes[pre]))
+			}
+		}
+	}
+	return warnings
+}
@@ -0,0 +1,225 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Nichakorn25/CPE-Teaching-Schedule/entity"
+	"github.com/Nichakorn25/CPE-Teaching-Schedule/services"
+)
+
+const defaultTeachingWeeks = 15
+
+type (
+	TATimesheetSection struct {
+		SectionID     uint
+		Code          string
+		CourseName    string
+		SectionNumber uint
+		Sessions      []string
+		WeeklyHours   float64
+		SemesterHours float64
+	}
+
+	TATimesheet struct {
+		TeachingAssistantID uint
+		Fullname            string
+		Email               string
+		MaxHoursPerWeek     uint
+		WeeklyHours         float64
+		SemesterHours       float64
+		Sections            []TATimesheetSection
+	}
+)
+
+func roundHours(minutes int) float64 {
+	return math.Round(float64(minutes)/60*100) / 100
+}
+
+// teachingWeeks จำนวนสัปดาห์ที่ใช้คิดชั่วโมงรวม: ?weeks= > วันเปิด-ปิดภาคเรียน > ค่าเริ่มต้น
+func teachingWeeks(c *gin.Context, year, term string) (int, error) {
+	if w := c.Query("weeks"); w != "" {
+		n, err := strconv.Atoi(w)
+		if err != nil || n <= 0 || n > 52 {
+			return 0, fmt.Errorf("จำนวนสัปดาห์ไม่ถูกต้อง")
+		}
+		return n, nil
+	}
+	cal, err := loadTermCalendar(year, term)
+	if err != nil {
+		log.Printf("teachingWeeks: load term calendar %s/%s: %v", year, term, err)
+		return 0, fmt.Errorf("ไม่สามารถดึงวันเปิด-ปิดภาคเรียนได้")
+	}
+	if cal != nil {
+		days := dateOnly(cal.EndDate).Sub(dateOnly(cal.StartDate)).Hours()/24 + 1
+		return int(math.Ceil(days / 7)), nil
+	}
+	return defaultTeachingWeeks, nil
+}
+
+func sessionLabel(s entity.Schedule) string {
+	label := fmt.Sprintf("%s %s-%s", s.DayOfWeek, s.StartTime.Format("15:04"), s.EndTime.Format("15:04"))
+	if s.Kind == entity.SessionKindLab {
+		label += " (ปฏิบัติ)"
+	}
+	return label
+}
+
+// buildTATimesheets ชั่วโมงช่วยสอนของผู้ช่วยสอนแต่ละคนจากกลุ่มเรียนที่ได้รับมอบหมายในตาราง
+func buildTATimesheets(book *taBook, weeks int, onlyTA uint) []TATimesheet {
+	sheets := []TATimesheet{}
+	for taID, sections := range book.assigned {
+		if onlyTA != 0 && taID != onlyTA {
+			continue
+		}
+		ta := book.tas[taID]
+		sheet := TATimesheet{
+			TeachingAssistantID: taID,
+			Fullname:            fmt.Sprintf("%s%s %s", ta.Title.Title, ta.Firstname, ta.Lastname),
+			Email:               ta.Email,
+			MaxHoursPerWeek:     ta.MaxHoursPerWeek,
+			Sections:            []TATimesheetSection{},
+		}
+
+		total := 0
+		for sid := range sections {
+			sessions := book.sessions[sid]
+			if len(sessions) == 0 {
+				continue
+			}
+			sort.Slice(sessions, func(i, j int) bool {
+				di, dj := dayIndex(sessions[i].DayOfWeek), dayIndex(sessions[j].DayOfWeek)
+				if di != dj {
+					return di < dj
+				}
+				return clockMinutes(sessions[i].StartTime) < clockMinutes(sessions[j].StartTime)
+			})
+
+			minutes := book.sectionMinutes(sid)
+			total += minutes
+			ac := sessions[0].OfferedCourses.AllCourses
+			row := TATimesheetSection{
+				SectionID:     sid,
+				Code:          offeredCodeLabel(sessions[0].OfferedCourses),
+				CourseName:    ac.ThaiName,
+				SectionNumber: sessions[0].SectionNumber,
+				WeeklyHours:   roundHours(minutes),
+				SemesterHours: roundHours(minutes * weeks),
+			}
+			for _, s := range sessions {
+				row.Sessions = append(row.Sessions, sessionLabel(s))
+			}
+			sheet.Sections = append(sheet.Sections, row)
+		}
+		if len(sheet.Sections) == 0 {
+			continue
+		}
+		sort.Slice(sheet.Sections, func(i, j int) bool {
+			a, b := sheet.Sections[i], sheet.Sections[j]
+			if a.Code != b.Code {
+				return a.Code < b.Code
+			}
+			return a.SectionNumber < b.SectionNumber
+		})
+		sheet.WeeklyHours = roundHours(total)
+		sheet.SemesterHours = roundHours(total * weeks)
+		sheets = append(sheets, sheet)
+	}
+
+	sort.Slice(sheets, func(i, j int) bool { return sheets[i].Fullname < sheets[j].Fullname })
+	return sheets
+}
+
+// timesheetRows ตารางสำหรับ CSV/XLSX หนึ่งแถวต่อกลุ่มเรียน และแถวรวมของผู้ช่วยสอนแต่ละคน
+func timesheetRows(sheets []TATimesheet, weeks int) [][]interface{} {
+	rows := [][]interface{}{{
+		"ผู้ช่วยสอน", "อีเมล", "รหัสวิชา", "ชื่อวิชา", "กลุ่ม", "คาบ",
+		"ชั่วโมง/สัปดาห์", "จำนวนสัปดาห์", "ชั่วโมงรวมทั้งภาค",
+	}}
+	for _, s := range sheets {
+		for _, sec := range s.Sections {
+			rows = append(rows, []interface{}{
+				s.Fullname, s.Email, sec.Code, sec.CourseName, sec.SectionNumber, strings.Join(sec.Sessions, ", "),
+				sec.WeeklyHours, weeks, sec.SemesterHours,
+			})
+		}
+		rows = append(rows, []interface{}{
+			s.Fullname, s.Email, "รวม", "", "", "",
+			s.WeeklyHours, weeks, s.SemesterHours,
+		})
+	}
+	return rows
+}
+
+// GET /ta-timesheet?year=2568&term=1[&weeks=15][&ta_id=3][&format=csv|xlsx]
+func GetTATimesheet(c *gin.Context) {
+	year := c.Query("year")
+	term := c.Query("term")
+	if year == "" || term == "" {
+		respondError(c, http.StatusBadRequest, "ต้องระบุ year และ term")
+		return
+	}
+	nameTable := fmt.Sprintf("ปีการศึกษา %s เทอม %s", year, term)
+
+	weeks, err := teachingWeeks(c, year, term)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var onlyTA uint
+	if v := c.Query("ta_id"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil || id <= 0 {
+			respondError(c, http.StatusBadRequest, "ta_id ไม่ถูกต้อง")
+			return
+		}
+		onlyTA = uint(id)
+	}
+
+	book, err := loadTABook(nameTable)
+	if err != nil {
+		respondServerError(c, http.StatusInternalServerError, "ไม่สามารถดึงข้อมูลผู้ช่วยสอนได้", err)
+		return
+	}
+	sheets := buildTATimesheets(book, weeks, onlyTA)
+	filename := fmt.Sprintf("ta-timesheet-%s-%s", year, term)
+
+	switch c.DefaultQuery("format", "json") {
+	case "csv":
+		var buf bytes.Buffer
+		buf.WriteString("\ufeff") // ให้ Excel อ่านภาษาไทยถูก
+		w := csv.NewWriter(&buf)
+		for _, row := range timesheetRows(sheets, weeks) {
+			record := make([]string, len(row))
+			for i, v := range row {
+				record[i] = fmt.Sprint(v)
+			}
+			_ = w.Write(record)
+		}
+		w.Flush()
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.csv"`, filename))
+		c.Data(http.StatusOK, "text/csv; charset=utf-8", buf.Bytes())
+	case "xlsx":
+		var buf bytes.Buffer
+		if err := services.WriteXLSX(&buf, "TA Timesheet", timesheetRows(sheets, weeks)); err != nil {
+			respondError(c, http.StatusInternalServerError, "ไม่สามารถสร้างไฟล์ Excel ได้")
+			return
+		}
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.xlsx"`, filename))
+		c.Data(http.StatusOK, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", buf.Bytes())
+	case "json":
+		c.JSON(http.StatusOK, gin.H{"name_table": nameTable, "weeks": weeks, "data": sheets})
+	default:
+		respondError(c, http.StatusBadRequest, "format ต้องเป็น json, csv หรือ xlsx")
+	}
+}
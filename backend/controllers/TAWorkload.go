@@ -0,0 +1,445 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/Nichakorn25/CPE-Teaching-Schedule/config"
+	"github.com/Nichakorn25/CPE-Teaching-Schedule/entity"
+	"github.com/Nichakorn25/CPE-Teaching-Schedule/services"
+)
+
+type (
+	TAAvailabilityInput struct {
+		DayOfWeek string
+		StartTime string
+		EndTime   string
+	}
+
+	TAAvailabilityRequest struct {
+		MaxHoursPerWeek uint
+		Windows         []TAAvailabilityInput
+	}
+
+	TAAvailabilityItem struct {
+		ID        uint
+		DayOfWeek string
+		Start     string
+		End       string
+	}
+
+	// TAAssignmentIssue ปัญหาของการมอบหมายผู้ช่วยสอนหนึ่งคนให้กลุ่มเรียนหนึ่งกลุ่ม
+	TAAssignmentIssue struct {
+		TeachingAssistantID uint
+		SectionID           uint
+		Reason              string
+	}
+
+	AutoAssignTARequest struct {
+		NameTable            string `json:"name_table" binding:"required"`
+		TeachingAssistantIDs []uint `json:"teaching_assistant_ids"` // ว่าง = ผู้ช่วยสอนทุกคน
+		TAsPerSection        uint   `json:"tas_per_section"`        // ค่าเริ่มต้น 1
+	}
+
+	TAAssignmentResp struct {
+		TeachingAssistantID uint
+		SectionID           uint
+		Code                string
+		SectionNumber       uint
+	}
+)
+
+// taBook ภาระของผู้ช่วยสอนในตารางหนึ่ง (NameTable) ใช้ตรวจสอนชน ช่วงเวลาว่าง และชั่วโมงต่อสัปดาห์
+type taBook struct {
+	sessions map[uint][]entity.Schedule // sectionID -> คาบในตารางนี้
+	assigned map[uint]map[uint]bool     // taID -> sectionIDs
+	tas      map[uint]entity.TeachingAssistant
+}
+
+func sessionSectionID(s entity.Schedule) *uint {
+	if s.SectionID != nil {
+		return s.SectionID
+	}
+	return config.SectionIDOf(s.OfferedCoursesID, s.SectionNumber)
+}
+
+func loadTABook(nameTable string) (*taBook, error) {
+	b := &taBook{
+		sessions: make(map[uint][]entity.Schedule),
+		assigned: make(map[uint]map[uint]bool),
+		tas:      make(map[uint]entity.TeachingAssistant),
+	}
+
+	var schedules []entity.Schedule
+	if err := config.DB().
+		Preload("OfferedCourses.AllCourses").
+		Preload("OfferedCourses.Aliases.AllCourses").
+		Where("name_table = ?", nameTable).
+		Find(&schedules).Error; err != nil {
+		return nil, err
+	}
+	sectionIDs := []uint{0}
+	for _, s := range schedules {
+		sid := sessionSectionID(s)
+		if sid == nil {
+			continue
+		}
+		if _, ok := b.sessions[*sid]; !ok {
+			sectionIDs = append(sectionIDs, *sid)
+		}
+		b.sessions[*sid] = append(b.sessions[*sid], s)
+	}
+
+	var rows []entity.ScheduleTeachingAssistant
+	if err := config.DB().Where("section_id IN ?", sectionIDs).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, r := range rows {
+		b.add(r.TeachingAssistantID, *r.SectionID)
+	}
+
+	var tas []entity.TeachingAssistant
+	if err := config.DB().Preload("Title").Preload("Availabilities").Find(&tas).Error; err != nil {
+		return nil, err
+	}
+	for _, ta := range tas {
+		b.tas[ta.ID] = ta
+	}
+	return b, nil
+}
+
+// loadTABookForSection ภาระผู้ช่วยสอนของตารางที่กลุ่มเรียนนี้อยู่ (nil ถ้ากลุ่มยังไม่มีคาบ)
+func loadTABookForSection(sectionID uint) (*taBook, error) {
+	var nameTable string
+	if err := config.DB().Model(&entity.Schedule{}).
+		Where("section_id = ?", sectionID).
+		Limit(1).
+		Pluck("name_table", &nameTable).Error; err != nil {
+		return nil, err
+	}
+	if nameTable == "" {
+		return nil, nil
+	}
+	return loadTABook(nameTable)
+}
+
+func (b *taBook) add(taID, sectionID uint) {
+	if b.assigned[taID] == nil {
+		b.assigned[taID] = make(map[uint]bool)
+	}
+	b.assigned[taID][sectionID] = true
+}
+
+func (b *taBook) remove(taID, sectionID uint) {
+	delete(b.assigned[taID], sectionID)
+}
+
+func (b *taBook) sectionMinutes(sectionID uint) int {
+	total := 0
+	for _, s := range b.sessions[sectionID] {
+		total += clockMinutes(s.EndTime) - clockMinutes(s.StartTime)
+	}
+	return total
+}
+
+func (b *taBook) weeklyMinutes(taID uint) int {
+	total := 0
+	for sid := range b.assigned[taID] {
+		total += b.sectionMinutes(sid)
+	}
+	return total
+}
+
+// doubleBooked กลุ่มเรียนอื่นของผู้ช่วยสอนที่มีคาบทับกลุ่มนี้
+func (b *taBook) doubleBooked(taID, sectionID uint) []uint {
+	clash := []uint{}
+	for other := range b.assigned[taID] {
+		if other == sectionID {
+			continue
+		}
+	OTHER:
+		for _, s := range b.sessions[sectionID] {
+			for _, o := range b.sessions[other] {
+				if s.DayOfWeek == o.DayOfWeek &&
+					overlapMinutes(clockMinutes(s.StartTime), clockMinutes(s.EndTime), clockMinutes(o.StartTime), clockMinutes(o.EndTime)) > 0 {
+					clash = append(clash, other)
+					break OTHER
+				}
+			}
+		}
+	}
+	sort.Slice(clash, func(i, j int) bool { return clash[i] < clash[j] })
+	return clash
+}
+
+// available ทุกคาบของกลุ่มอยู่ในช่วงเวลาว่างของผู้ช่วยสอน (ไม่ได้กำหนดช่วงว่าง = ว่างทุกเวลา)
+func (b *taBook) available(taID, sectionID uint) bool {
+	windows := b.tas[taID].Availabilities
+	if len(windows) == 0 {
+		return true
+	}
+	for _, s := range b.sessions[sectionID] {
+		start, end := clockMinutes(s.StartTime), clockMinutes(s.EndTime)
+		inside := false
+		for _, w := range windows {
+			if w.DayOfWeek == s.DayOfWeek && clockMinutes(w.StartTime) <= start && end <= clockMinutes(w.EndTime) {
+				inside = true
+				break
+			}
+		}
+		if !inside {
+			return false
+		}
+	}
+	return true
+}
+
+func (b *taBook) withinHours(taID, sectionID uint) bool {
+	max := b.tas[taID].MaxHoursPerWeek
+	if max == 0 {
+		return true
+	}
+	extra := b.sectionMinutes(sectionID)
+	if b.assigned[taID][sectionID] {
+		extra = 0
+	}
+	return b.weeklyMinutes(taID)+extra <= int(max)*60
+}
+
+func (b *taBook) sectionLabel(sectionID uint) string {
+	if ss := b.sessions[sectionID]; len(ss) > 0 {
+		return fmt.Sprintf("%s กลุ่ม %d", offeredCodeLabel(ss[0].OfferedCourses), ss[0].SectionNumber)
+	}
+	return fmt.Sprintf("กลุ่มเรียน %d", sectionID)
+}
+
+// check คืนข้อขัดข้องที่ต้องปฏิเสธ (สอนชน) และข้อที่แจ้งเตือนได้ (นอกเวลาว่าง เกินชั่วโมง)
+func (b *taBook) check(taID, sectionID uint) (conflicts, warnings []TAAssignmentIssue) {
+	for _, other := range b.doubleBooked(taID, sectionID) {
+		conflicts = append(conflicts, TAAssignmentIssue{
+			TeachingAssistantID: taID,
+			SectionID:           sectionID,
+			Reason:              fmt.Sprintf("คาบทับกับ %s ที่ผู้ช่วยสอนคนนี้ดูแลอยู่", b.sectionLabel(other)),
+		})
+	}
+	if !b.available(taID, sectionID) {
+		warnings = append(warnings, TAAssignmentIssue{
+			TeachingAssistantID: taID,
+			SectionID:           sectionID,
+			Reason:              "คาบอยู่นอกช่วงเวลาว่างของผู้ช่วยสอน",
+		})
+	}
+	if !b.withinHours(taID, sectionID) {
+		warnings = append(warnings, TAAssignmentIssue{
+			TeachingAssistantID: taID,
+			SectionID:           sectionID,
+			Reason:              fmt.Sprintf("เกินชั่วโมงช่วยสอนสูงสุด %d ชม./สัปดาห์", b.tas[taID].MaxHoursPerWeek),
+		})
+	}
+	return conflicts, warnings
+}
+
+// checkAll ตรวจการมอบหมายหลายคู่ตามลำดับ (นับคู่ก่อนหน้าในชุดเดียวกันด้วย)
+func (b *taBook) checkAll(taIDs, sectionIDs []uint) (conflicts, warnings []TAAssignmentIssue) {
+	conflicts, warnings = []TAAssignmentIssue{}, []TAAssignmentIssue{}
+	for _, sid := range sectionIDs {
+		for _, tid := range taIDs {
+			c, w := b.check(tid, sid)
+			conflicts = append(conflicts, c...)
+			warnings = append(warnings, w...)
+			b.add(tid, sid)
+		}
+	}
+	return conflicts, warnings
+}
+
+// GET /teaching-assistants/:id/availability
+func GetTAAvailability(c *gin.Context) {
+	var ta entity.TeachingAssistant
+	if err := config.DB().
+		Preload("Availabilities", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		First(&ta, c.Param("id")).Error; err != nil {
+		respondError(c, http.StatusNotFound, "ไม่พบผู้ช่วยสอน")
+		return
+	}
+
+	items := make([]TAAvailabilityItem, 0, len(ta.Availabilities))
+	for _, w := range ta.Availabilities {
+		items = append(items, TAAvailabilityItem{
+			ID:        w.ID,
+			DayOfWeek: w.DayOfWeek,
+			Start:     w.StartTime.Format("15:04"),
+			End:       w.EndTime.Format("15:04"),
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{"MaxHoursPerWeek": ta.MaxHoursPerWeek, "Windows": items})
+}
+
+// PUT /teaching-assistants/:id/availability แทนที่ช่วงเวลาว่างและชั่วโมงสูงสุดต่อสัปดาห์
+func UpdateTAAvailability(c *gin.Context) {
+	var ta entity.TeachingAssistant
+	if err := config.DB().First(&ta, c.Param("id")).Error; err != nil {
+		respondError(c, http.StatusNotFound, "ไม่พบผู้ช่วยสอน")
+		return
+	}
+
+	var req TAAvailabilityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "ข้อมูลไม่ถูกต้อง")
+		return
+	}
+	if req.MaxHoursPerWeek > 168 {
+		respondError(c, http.StatusBadRequest, "ชั่วโมงสูงสุดต่อสัปดาห์ไม่ถูกต้อง")
+		return
+	}
+
+	loc, _ := time.LoadLocation("Asia/Bangkok")
+	const fixedDate = "2000-01-01"
+	layout := "2006-01-02 15:04"
+
+	windows := make([]entity.TAAvailability, 0, len(req.Windows))
+	for _, input := range req.Windows {
+		start, err1 := time.ParseInLocation(layout, fixedDate+" "+input.StartTime, loc)
+		end, err2 := time.ParseInLocation(layout, fixedDate+" "+input.EndTime, loc)
+		if err1 != nil || err2 != nil || !end.After(start) {
+			respondError(c, http.StatusBadRequest, "รูปแบบเวลาไม่ถูกต้อง")
+			return
+		}
+		if dayIndex(input.DayOfWeek) < 0 {
+			respondError(c, http.StatusBadRequest, fmt.Sprintf("ไม่รู้จักวัน %s", input.DayOfWeek))
+			return
+		}
+		windows = append(windows, entity.TAAvailability{
+			DayOfWeek:           input.DayOfWeek,
+			StartTime:           start,
+			EndTime:             end,
+			TeachingAssistantID: ta.ID,
+		})
+	}
+
+	if !validateEntity(c, windows) {
+		return
+	}
+	if err := config.DB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("teaching_assistant_id = ?", ta.ID).Delete(&entity.TAAvailability{}).Error; err != nil {
+			return err
+		}
+		if len(windows) > 0 {
+			if err := tx.Create(&windows).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Model(&ta).Update("max_hours_per_week", req.MaxHoursPerWeek).Error
+	}); err != nil {
+		respondError(c, http.StatusInternalServerError, "ไม่สามารถบันทึกช่วงเวลาว่างของผู้ช่วยสอนได้")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "บันทึกช่วงเวลาว่างของผู้ช่วยสอนสำเร็จ"})
+}
+
+// POST /auto-assign-ta กระจายผู้ช่วยสอนให้กลุ่มเรียนที่มีคาบปฏิบัติอย่างทั่วถึง
+// กลุ่มที่มีผู้ช่วยสอนที่เหมาะสมน้อยที่สุดได้เลือกก่อน และเลือกผู้ช่วยสอนที่ภาระน้อยที่สุด
+func AutoAssignTA(c *gin.Context) {
+	var req AutoAssignTARequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+	perSection := int(req.TAsPerSection)
+	if perSection == 0 {
+		perSection = 1
+	}
+
+	book, err := loadTABook(req.NameTable)
+	if err != nil {
+		respondServerError(c, http.StatusInternalServerError, "โหลดข้อมูลผู้ช่วยสอนไม่สำเร็จ", err)
+		return
+	}
+
+	pool := req.TeachingAssistantIDs
+	if len(pool) == 0 {
+		for id := range book.tas {
+			pool = append(pool, id)
+		}
+	}
+	for _, id := range pool {
+		if _, ok := book.tas[id]; !ok {
+			respondError(c, http.StatusBadRequest, fmt.Sprintf("ไม่พบผู้ช่วยสอน ID %d", id))
+			return
+		}
+	}
+	sort.Slice(pool, func(i, j int) bool { return pool[i] < pool[j] })
+
+	eligible := func(taID, sectionID uint) bool {
+		if book.assigned[taID][sectionID] {
+			return false
+		}
+		return len(book.doubleBooked(taID, sectionID)) == 0 &&
+			book.available(taID, sectionID) &&
+			book.withinHours(taID, sectionID)
+	}
+
+	// กลุ่มเรียนที่มีคาบปฏิบัติและยังมีผู้ช่วยสอนไม่ครบ
+	need := make(map[uint]int)
+	for sid, sessions := range book.sessions {
+		hasLab := false
+		for _, s := range sessions {
+			hasLab = hasLab || s.Kind == entity.SessionKindLab
+		}
+		if !hasLab {
+			continue
+		}
+		current := 0
+		for _, sections := range book.assigned {
+			if sections[sid] {
+				current++
+			}
+		}
+		if current < perSection {
+			need[sid] = perSection - current
+		}
+	}
+
+	picks, left := services.AssignTAs(need, pool, eligible, book.weeklyMinutes, book.add)
+
+	assignments := []TAAssignmentResp{}
+	toInsert := []entity.ScheduleTeachingAssistant{}
+	for _, p := range picks {
+		sectionID := p.SectionID
+		toInsert = append(toInsert, entity.ScheduleTeachingAssistant{TeachingAssistantID: p.TeachingAssistantID, SectionID: &sectionID})
+		s := book.sessions[p.SectionID][0]
+		assignments = append(assignments, TAAssignmentResp{
+			TeachingAssistantID: p.TeachingAssistantID,
+			SectionID:           p.SectionID,
+			Code:                offeredCodeLabel(s.OfferedCourses),
+			SectionNumber:       s.SectionNumber,
+		})
+	}
+
+	unassigned := []string{}
+	for _, sid := range left {
+		unassigned = append(unassigned, book.sectionLabel(sid))
+	}
+	sort.Strings(unassigned)
+
+	if len(toInsert) > 0 {
+		if err := config.DB().Transaction(func(tx *gorm.DB) error {
+			return tx.Create(&toInsert).Error
+		}); err != nil {
+			respondServerError(c, http.StatusInternalServerError, "ไม่สามารถบันทึกผู้ช่วยสอนได้", err)
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "จัดผู้ช่วยสอนอัตโนมัติสำเร็จ",
+		"assignments": assignments,
+		"unassigned":  unassigned,
+	})
+}
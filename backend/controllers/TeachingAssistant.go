@@ -4,9 +4,11 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 
 	"github.com/Nichakorn25/CPE-Teaching-Schedule/config"
 	"github.com/Nichakorn25/CPE-Teaching-Schedule/entity"
+	"github.com/Nichakorn25/CPE-Teaching-Schedule/services"
 )
 
 type (
@@ -36,24 +38,53 @@ func GetTeachingAssistantByID(c *gin.Context) {
         Preload("ScheduleTeachingAssistant").
         First(&teachingAssistant, id).Error; err != nil {
 
-        c.JSON(http.StatusNotFound, gin.H{"error": "ไม่พบผู้ช่วยสอน"})
+        respondError(c, http.StatusNotFound, "ไม่พบผู้ช่วยสอน")
         return
     }
 
     c.JSON(http.StatusOK, teachingAssistant)
 }
 
+var teachingAssistantListSpec = services.ListSpec{
+	Sorts: map[string]string{
+		"id":        "teaching_assistants.id",
+		"firstname": "teaching_assistants.firstname",
+		"lastname":  "teaching_assistants.lastname",
+		"email":     "teaching_assistants.email",
+	},
+	Filters: map[string]services.ListFilter{
+		"section_id": {Kind: services.FilterUint, Where: `EXISTS (SELECT 1 FROM schedule_teaching_assistants sta
+			WHERE sta.teaching_assistant_id = teaching_assistants.id AND sta.section_id = ? AND sta.deleted_at IS NULL)`},
+		"search": {Kind: services.FilterSearch, Where: "(teaching_assistants.firstname ILIKE ? OR teaching_assistants.lastname ILIKE ? OR teaching_assistants.email ILIKE ?)"},
+	},
+	DefaultSort: "id",
+}
+
+// GET /all-teaching-assistants?page=&size=&sort=&section_id=&search=
 func GetAllTeachingAssistants(c *gin.Context) {
-	var teachingAssistants []entity.TeachingAssistant
+	q, ok := listQuery(c, teachingAssistantListSpec)
+	if !ok {
+		return
+	}
+	base := func() *gorm.DB {
+		return q.Filter(config.DB().Model(&entity.TeachingAssistant{}))
+	}
 
-	if err := config.DB().Preload("Title").
+	var total int64
+	if err := base().Count(&total).Error; err != nil {
+		respondServerError(c, http.StatusInternalServerError, "ไม่สามารถดึงข้อมูลผู้ช่วยสอนได้", err)
+		return
+	}
+
+	teachingAssistants := []entity.TeachingAssistant{}
+	if err := q.Paginate(base()).Preload("Title").
 		Preload("ScheduleTeachingAssistant").
 		Find(&teachingAssistants).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondServerError(c, http.StatusInternalServerError, "ไม่สามารถดึงข้อมูลผู้ช่วยสอนได้", err)
 		return
 	}
 
-	c.JSON(http.StatusOK, teachingAssistants)
+	respondList(c, q, total, teachingAssistants, teachingAssistants)
 }
 
 // //////////////////////////////////////////////////////////////////////////// create ///////
@@ -61,18 +92,13 @@ func CreateTeachingAssistant(c *gin.Context) {
 	var data_ta CreateTeachingAssistantInput
 
 	if err := c.ShouldBindJSON(&data_ta); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":  "ข้อมูลไม่ถูกต้อง",
-			"detail": err.Error(),
-		})
+		respondBindError(c, err)
 		return
 	}
 
 	var title entity.Title
 	if err := config.DB().First(&title, data_ta.TitleID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error": "ไม่พบคำนำหน้า",
-		})
+		respondError(c, http.StatusNotFound, "ไม่พบคำนำหน้า")
 		return
 	}
 
@@ -83,12 +109,12 @@ func CreateTeachingAssistant(c *gin.Context) {
 		PhoneNumber: data_ta.PhoneNumber,
 		TitleID:     data_ta.TitleID,
 	}
+	if !validateEntity(c, ta) {
+		return
+	}
 
 	if err := config.DB().Create(&ta).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":  "ไม่สามารถเพิ่มข้อมูลผู้ช่วยสอนได้",
-			"detail": err.Error(),
-		})
+		respondServerError(c, http.StatusInternalServerError, "ไม่สามารถเพิ่มข้อมูลผู้ช่วยสอนได้", err)
 		return
 	}
 
@@ -103,20 +129,20 @@ func UpdateTeachingAssistant(c *gin.Context) {
 
 	var ta entity.TeachingAssistant
 	if err := config.DB().First(&ta, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "ไม่พบผู้ช่วยสอนที่ต้องการแก้ไข"})
+		respondError(c, http.StatusNotFound, "ไม่พบผู้ช่วยสอนที่ต้องการแก้ไข")
 		return
 	}
 
 	var input UpdateTeachingAssistantInput
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "ข้อมูลไม่ถูกต้อง", "detail": err.Error()})
+		respondBindError(c, err)
 		return
 	}
 
 	if input.TitleID != 0 {
 		var title entity.Title
 		if err := config.DB().First(&title, input.TitleID).Error; err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "ไม่พบคำนำหน้า (Title) ที่ระบุ"})
+			respondError(c, http.StatusNotFound, "ไม่พบคำนำหน้า (Title) ที่ระบุ")
 			return
 		}
 	}
@@ -129,8 +155,29 @@ func UpdateTeachingAssistant(c *gin.Context) {
 		TitleID:     input.TitleID,
 	}
 
+	// Updates ข้ามค่าว่าง จึงตรวจผลลัพธ์หลังรวมกับข้อมูลเดิม
+	merged := ta
+	if updated.Firstname != "" {
+		merged.Firstname = updated.Firstname
+	}
+	if updated.Lastname != "" {
+		merged.Lastname = updated.Lastname
+	}
+	if updated.Email != "" {
+		merged.Email = updated.Email
+	}
+	if updated.PhoneNumber != "" {
+		merged.PhoneNumber = updated.PhoneNumber
+	}
+	if updated.TitleID != 0 {
+		merged.TitleID = updated.TitleID
+	}
+	if !validateEntity(c, merged) {
+		return
+	}
+
 	if err := config.DB().Model(&ta).Updates(updated).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "ไม่สามารถอัปเดตข้อมูลผู้ช่วยสอนได้", "detail": err.Error()})
+		respondServerError(c, http.StatusInternalServerError, "ไม่สามารถอัปเดตข้อมูลผู้ช่วยสอนได้", err)
 		return
 	}
 
@@ -142,14 +189,19 @@ func DeleteTeachingAssistant(c *gin.Context) {
 
 	var ta entity.TeachingAssistant
 	if err := config.DB().First(&ta, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "ไม่พบผู้ช่วยสอนที่ต้องการลบ"})
+		respondError(c, http.StatusNotFound, "ไม่พบผู้ช่วยสอนที่ต้องการลบ")
 		return
 	}
 
-	if err := config.DB().Delete(&ta).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "ไม่สามารถลบข้อมูลผู้ช่วยสอนได้", "detail": err.Error()})
+	plan, ok := deleteWithDependents(c, config.KindTeachingAssistant, ta.ID, "ไม่สามารถลบข้อมูลผู้ช่วยสอนได้")
+	if !ok {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "ลบข้อมูลผู้ช่วยสอนเรียบร้อยแล้ว"})
+	c.JSON(http.StatusOK, gin.H{"message": "ลบข้อมูลผู้ช่วยสอนเรียบร้อยแล้ว", "cascaded": plan.Cascaded, "detached": plan.Detached})
+}
+
+// RestoreTeachingAssistant กู้คืนผู้ช่วยสอนที่ถูกลบ
+func RestoreTeachingAssistant(c *gin.Context) {
+	restoreDeleted(c, config.KindTeachingAssistant)
 }
@@ -0,0 +1,266 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Nichakorn25/CPE-Teaching-Schedule/config"
+	"github.com/Nichakorn25/CPE-Teaching-Schedule/entity"
+	"github.com/Nichakorn25/CPE-Teaching-Schedule/services"
+)
+
+type (
+	TeachingLoadCourse struct {
+		OfferedCoursesID uint
+		Code             string
+		Name             string
+		Credit           string
+		CreditUnit       uint
+		Sections         uint
+		LectureHours     float64
+		LabHours         float64
+		PlannedHours     float64
+		IsFixCourses     bool
+	}
+
+	TeachingLoadResp struct {
+		UserID       uint
+		Fullname     string
+		Major        string
+		Position     string
+		CourseCount  int
+		CreditUnits  uint
+		LectureHours float64
+		LabHours     float64
+		WeeklyHours  float64
+		PlannedHours float64
+		Status       string // "overload", "underload" หรือ "normal"
+		Courses      []TeachingLoadCourse
+	}
+)
+
+const (
+	loadStatusOverload  = "overload"
+	loadStatusUnderload = "underload"
+	loadStatusNormal    = "normal"
+)
+
+// splitScheduledHours แยกชั่วโมงที่จัดลงตารางแล้วของแต่ละกลุ่มเป็นบรรยาย/ปฏิบัติตามชนิดคาบ
+// คาบเก่าที่ยังไม่มีชนิดนับเป็นปฏิบัติได้ไม่เกินที่หน่วยกิตกำหนด ส่วนที่เหลือเป็นบรรยาย
+func splitScheduledHours(schedules []entity.Schedule, labPerSection int) (float64, float64) {
+	var lecture, lab float64
+	untyped := make(map[uint]float64)
+	for _, s := range schedules {
+		hours := s.EndTime.Sub(s.StartTime).Hours()
+		switch s.Kind {
+		case entity.SessionKindLab:
+			lab += hours
+		case entity.SessionKindLecture:
+			lecture += hours
+		default:
+			untyped[s.SectionNumber] += hours
+		}
+	}
+
+	for _, total := range untyped {
+		labPart := float64(labPerSection)
+		if total < labPart {
+			labPart = total
+		}
+		lab += labPart
+		lecture += total - labPart
+	}
+	return lecture, lab
+}
+
+func userIDsOf(uacs []entity.UserAllCourses) []uint {
+	ids := make([]uint, 0, len(uacs))
+	for _, uac := range uacs {
+		ids = append(ids, uac.UserID)
+	}
+	return ids
+}
+
+// GET /teaching-load?year=2568&term=1&major_name=...&max_hours=18&min_hours=6
+func GetTeachingLoad(c *gin.Context) {
+	year := c.Query("year")
+	term := c.Query("term")
+	majorName := c.Query("major_name")
+
+	if year == "" || term == "" {
+		respondError(c, http.StatusBadRequest, "ต้องระบุ year และ term")
+		return
+	}
+
+	var maxHours, minHours float64
+	if v := c.Query("max_hours"); v != "" {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil || f < 0 {
+			respondError(c, http.StatusBadRequest, "max_hours ต้องเป็นตัวเลข")
+			return
+		}
+		maxHours = f
+	}
+	if v := c.Query("min_hours"); v != "" {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil || f < 0 {
+			respondError(c, http.StatusBadRequest, "min_hours ต้องเป็นตัวเลข")
+			return
+		}
+		minHours = f
+	}
+
+	var offered []entity.OfferedCourses
+	if err := config.DB().
+		Preload("AllCourses.Credit").
+		Preload("AllCourses.UserAllCourses").
+		Preload("SectionInstructors").
+		Preload("Schedule").
+		Where("year = ? AND term = ?", year, term).
+		Find(&offered).Error; err != nil {
+		respondServerError(c, http.StatusInternalServerError, "ไม่สามารถดึงข้อมูลรายวิชาที่เปิดสอนได้", err)
+		return
+	}
+
+	hoursRules, err := config.LoadHoursRules()
+	if err != nil {
+		respondServerError(c, http.StatusInternalServerError, "โหลดกติกาชั่วโมงสอนไม่สำเร็จ", err)
+		return
+	}
+
+	// รวบรวมรายวิชาของอาจารย์แต่ละคน กลุ่มที่กำหนดผู้สอนรายกลุ่มแล้วนับให้เฉพาะผู้สอนของกลุ่มนั้น
+	// กลุ่มที่ยังไม่กำหนดแบ่งให้ผู้รับผิดชอบหลัก + ผู้สอนร่วมของรายวิชา
+	loads := make(map[uint]*TeachingLoadResp)
+	for _, oc := range offered {
+		ac := oc.AllCourses
+		hours := hoursRules.For(ac)
+		lecPerSection, labPerSection := hours.Lecture, hours.Lab
+
+		defaultUsers := []uint{}
+		seen := make(map[uint]bool)
+		for _, uid := range append([]uint{oc.UserID}, userIDsOf(ac.UserAllCourses)...) {
+			if uid == 0 || seen[uid] {
+				continue
+			}
+			seen[uid] = true
+			defaultUsers = append(defaultUsers, uid)
+		}
+
+		assigned := make(map[uint][]entity.SectionInstructor)
+		for _, si := range oc.SectionInstructors {
+			assigned[si.SectionNumber] = append(assigned[si.SectionNumber], si)
+		}
+
+		bySection := make(map[uint][]entity.Schedule)
+		for _, s := range oc.Schedule {
+			bySection[s.SectionNumber] = append(bySection[s.SectionNumber], s)
+		}
+
+		courses := make(map[uint]*TeachingLoadCourse)
+		order := []uint{}
+		add := func(uid uint, lecture, lab, planned float64) {
+			course, ok := courses[uid]
+			if !ok {
+				course = &TeachingLoadCourse{
+					OfferedCoursesID: oc.ID,
+					Code:             ac.Code,
+					Name:             ac.EnglishName,
+					Credit:           fmt.Sprintf("%d(%d-%d-%d)", ac.Credit.Unit, ac.Credit.Lecture, ac.Credit.Lab, ac.Credit.Self),
+					CreditUnit:       ac.Credit.Unit,
+					IsFixCourses:     oc.IsFixCourses,
+				}
+				courses[uid] = course
+				order = append(order, uid)
+			}
+			course.Sections++
+			course.LectureHours += lecture
+			course.LabHours += lab
+			course.PlannedHours += planned
+		}
+
+		// ชั่วโมงที่ผู้สอนกำหนดไว้รายคนใช้ตามนั้น ที่เหลือแบ่งเท่ากันให้ผู้สอนคนอื่นของกลุ่ม
+		for sec := uint(1); sec <= oc.Section; sec++ {
+			lecture, lab := splitScheduledHours(bySection[sec], labPerSection)
+
+			sis, ok := assigned[sec]
+			if !ok {
+				sis = make([]entity.SectionInstructor, 0, len(defaultUsers))
+				for _, uid := range defaultUsers {
+					sis = append(sis, entity.SectionInstructor{UserID: uid})
+				}
+			}
+			planned := services.SplitSectionHours(sis, float64(lecPerSection), float64(labPerSection))
+			for i, share := range services.SplitSectionHours(sis, lecture, lab) {
+				add(share.UserID, share.Lecture, share.Lab, planned[i].Lecture+planned[i].Lab)
+			}
+		}
+
+		for _, uid := range order {
+			course := courses[uid]
+			load, ok := loads[uid]
+			if !ok {
+				load = &TeachingLoadResp{UserID: uid, Courses: []TeachingLoadCourse{}}
+				loads[uid] = load
+			}
+			load.Courses = append(load.Courses, *course)
+			load.CreditUnits += course.CreditUnit
+			load.LectureHours += course.LectureHours
+			load.LabHours += course.LabHours
+			load.PlannedHours += course.PlannedHours
+		}
+	}
+
+	// ดึงข้อมูลอาจารย์ (ไม่รวมผู้ดูแลระบบ)
+	var users []entity.User
+	db := config.DB().
+		Preload("Title").
+		Preload("Position").
+		Preload("Major").
+		Where("users.role_id != ?", 1)
+	if majorName != "" {
+		db = db.Joins("JOIN majors ON users.major_id = majors.id").
+			Where("majors.major_name = ?", majorName)
+	}
+	if err := db.Find(&users).Error; err != nil {
+		respondServerError(c, http.StatusInternalServerError, "ไม่สามารถดึงข้อมูลอาจารย์ได้", err)
+		return
+	}
+
+	resp := make([]TeachingLoadResp, 0, len(users))
+	for _, u := range users {
+		load, ok := loads[u.ID]
+		if !ok {
+			load = &TeachingLoadResp{UserID: u.ID, Courses: []TeachingLoadCourse{}}
+		}
+		load.Fullname = fmt.Sprintf("%s%s %s", u.Title.Title, u.Firstname, u.Lastname)
+		load.Major = u.Major.MajorName
+		load.Position = u.Position.Position
+		load.CourseCount = len(load.Courses)
+		load.WeeklyHours = load.LectureHours + load.LabHours
+
+		load.Status = loadStatusNormal
+		if maxHours > 0 && load.WeeklyHours > maxHours {
+			load.Status = loadStatusOverload
+		} else if minHours > 0 && load.WeeklyHours < minHours {
+			load.Status = loadStatusUnderload
+		}
+
+		resp = append(resp, *load)
+	}
+
+	sort.SliceStable(resp, func(i, j int) bool {
+		return resp[i].WeeklyHours > resp[j].WeeklyHours
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"year":      year,
+		"term":      term,
+		"max_hours": maxHours,
+		"min_hours": minHours,
+		"data":      resp,
+	})
+}
@@ -0,0 +1,149 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/Nichakorn25/CPE-Teaching-Schedule/config"
+	"github.com/Nichakorn25/CPE-Teaching-Schedule/entity"
+)
+
+type termCalendarInput struct {
+	StartDate string `json:"start_date" binding:"required"` // "2006-01-02"
+	EndDate   string `json:"end_date" binding:"required"`
+}
+
+// weekdayIndex ลำดับวันของวันที่ (จันทร์ = 0) ให้ตรงกับ thaiDays
+func weekdayIndex(t time.Time) int {
+	return (int(t.Weekday()) + 6) % 7
+}
+
+func dateOnly(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// loadTermCalendar คืน nil ถ้ายังไม่ได้กำหนดวันเปิด-ปิดภาคเรียน
+func loadTermCalendar(year, term string) (*entity.TermCalendar, error) {
+	var cal entity.TermCalendar
+	err := config.DB().Where("year = ? AND term = ?", year, term).First(&cal).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &cal, nil
+}
+
+// conditionWindow ช่วงวันที่ที่เงื่อนไขมีผลภายในภาคเรียน (ตัดกับ cal ถ้ามี)
+// ok = false เมื่อไม่มีวันใดทับภาคเรียน หรือช่วงไม่มีขอบเขตให้นับวันได้
+func conditionWindow(cond entity.Condition, cal *entity.TermCalendar) (from, to time.Time, ok bool) {
+	if cond.Date != nil {
+		from, to = dateOnly(*cond.Date), dateOnly(*cond.Date)
+	} else {
+		if cond.EffectiveFrom != nil {
+			from = dateOnly(*cond.EffectiveFrom)
+		}
+		if cond.EffectiveTo != nil {
+			to = dateOnly(*cond.EffectiveTo)
+		}
+	}
+	if cal != nil {
+		start, end := dateOnly(cal.StartDate), dateOnly(cal.EndDate)
+		if from.IsZero() || from.Before(start) {
+			from = start
+		}
+		if to.IsZero() || to.After(end) {
+			to = end
+		}
+	}
+	if from.IsZero() || to.IsZero() || to.Before(from) {
+		return from, to, false
+	}
+	return from, to, true
+}
+
+// conditionDates วันที่จริงที่ผู้สอนไม่ว่างตามเงื่อนไข (เฉพาะวันที่ตรงกับ DayOfWeek)
+func conditionDates(cond entity.Condition, cal *entity.TermCalendar) []time.Time {
+	from, to, ok := conditionWindow(cond, cal)
+	if !ok {
+		return nil
+	}
+	day := dayIndex(cond.DayOfWeek)
+	dates := []time.Time{}
+	for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+		if weekdayIndex(d) == day {
+			dates = append(dates, d)
+		}
+	}
+	return dates
+}
+
+// GET /term-calendars/:year/:term
+func GetTermCalendar(c *gin.Context) {
+	cal, err := loadTermCalendar(c.Param("year"), c.Param("term"))
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "ไม่สามารถดึงวันเปิด-ปิดภาคเรียนได้")
+		return
+	}
+	if cal == nil {
+		respondError(c, http.StatusNotFound, "ยังไม่ได้กำหนดวันเปิด-ปิดภาคเรียน")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"year":       cal.Year,
+		"term":       cal.Term,
+		"start_date": cal.StartDate.Format(conditionDateLayout),
+		"end_date":   cal.EndDate.Format(conditionDateLayout),
+	})
+}
+
+// PUT /term-calendars/:year/:term
+func UpdateTermCalendar(c *gin.Context) {
+	year, err1 := strconv.Atoi(c.Param("year"))
+	term, err2 := strconv.Atoi(c.Param("term"))
+	if err1 != nil || err2 != nil || year <= 0 || term <= 0 {
+		respondError(c, http.StatusBadRequest, "ปีการศึกษาหรือเทอมไม่ถูกต้อง")
+		return
+	}
+
+	var input termCalendarInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		respondBindError(c, err)
+		return
+	}
+	loc, _ := time.LoadLocation("Asia/Bangkok")
+	start, err1 := time.ParseInLocation(conditionDateLayout, input.StartDate, loc)
+	end, err2 := time.ParseInLocation(conditionDateLayout, input.EndDate, loc)
+	if err1 != nil || err2 != nil {
+		respondError(c, http.StatusBadRequest, "รูปแบบวันที่ไม่ถูกต้อง (YYYY-MM-DD)")
+		return
+	}
+	if !end.After(start) {
+		respondError(c, http.StatusBadRequest, "วันปิดภาคเรียนต้องอยู่หลังวันเปิดภาคเรียน")
+		return
+	}
+
+	var cal entity.TermCalendar
+	if err := config.DB().Where("year = ? AND term = ?", year, term).First(&cal).Error; err != nil && err != gorm.ErrRecordNotFound {
+		respondError(c, http.StatusInternalServerError, "ไม่สามารถดึงวันเปิด-ปิดภาคเรียนได้")
+		return
+	}
+	cal.Year = uint(year)
+	cal.Term = uint(term)
+	cal.StartDate = start
+	cal.EndDate = end
+	if !validateEntity(c, cal) {
+		return
+	}
+	if err := config.DB().Save(&cal).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, "ไม่สามารถบันทึกวันเปิด-ปิดภาคเรียนได้")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "บันทึกวันเปิด-ปิดภาคเรียนสำเร็จ"})
+}
@@ -34,7 +34,7 @@ type CreateFixedCourseRequest struct {
 func CreateFixedCourse(c *gin.Context) {
 	var req CreateFixedCourseRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "ข้อมูลที่ส่งมาไม่ถูกต้อง: " + err.Error()})
+		respondBindError(c, err)
 		return
 	}
 
@@ -47,13 +47,17 @@ func CreateFixedCourse(c *gin.Context) {
 
 	startTime, err := time.ParseInLocation("2006-01-02 15:04", startDateTimeStr, location)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "รูปแบบเวลาเริ่มต้นไม่ถูกต้อง"})
+		respondError(c, http.StatusBadRequest, "รูปแบบเวลาเริ่มต้นไม่ถูกต้อง")
 		return
 	}
 
 	endTime, err := time.ParseInLocation("2006-01-02 15:04", endDateTimeStr, location)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "รูปแบบเวลาสิ้นสุดไม่ถูกต้อง"})
+		respondError(c, http.StatusBadRequest, "รูปแบบเวลาสิ้นสุดไม่ถูกต้อง")
+		return
+	}
+
+	if rejectLabCapacity(c, req.LaboratoryID, req.Capacity) {
 		return
 	}
 
@@ -67,40 +71,67 @@ func CreateFixedCourse(c *gin.Context) {
 		AllCoursesID: req.AllCoursesID,
 		LaboratoryID: req.LaboratoryID,
 	}
-	if err := config.DB().Create(&offeredCourse).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "ไม่สามารถสร้างข้อมูลรายวิชาที่เปิดสอนได้"})
+	if !validateEntity(c, offeredCourse) {
 		return
 	}
 
-	schedule := entity.Schedule{
-		NameTable:        fmt.Sprintf("ปีการศึกษา %d เทอม %d", req.Year, req.Term),
-		SectionNumber:    req.Section,
-		DayOfWeek:        req.DayOfWeek,
-		StartTime:        startTime,
-		EndTime:          endTime,
-		OfferedCoursesID: offeredCourse.ID,
-	}
-	if err := config.DB().Create(&schedule).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "ไม่สามารถสร้างตารางเรียนได้",
-		})
-		return
+	// จับคู่ RoomFix กับแคตตาล็อกห้อง (ถ้าไม่พบจะเก็บเป็นข้อความอย่างเดียว)
+	var roomID *uint
+	if room := config.FindRoomByName(req.RoomFix); room != nil {
+		roomID = &room.ID
 	}
 
-	timeFixed := entity.TimeFixedCourses{
-		Year:         req.Year,
-		Term:         req.Term,
-		DayOfWeek:    req.DayOfWeek,
-		StartTime:    startTime,
-		EndTime:      endTime,
-		RoomFix:      req.RoomFix,
-		Section:      req.SectionInFixed,
-		Capacity:     req.Capacity,
-		AllCoursesID: req.AllCoursesID,
-		ScheduleID:   schedule.ID,
-	}
-	if err := config.DB().Create(&timeFixed).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "ไม่สามารถสร้างข้อมูล TimeFix ได้"})
+	// รายวิชา กลุ่มเรียน ตารางสอน และ TimeFix ต้องสร้างครบทั้งชุดหรือไม่สร้างเลย
+	var schedule entity.Schedule
+	var timeFixed entity.TimeFixedCourses
+	err = config.DB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&offeredCourse).Error; err != nil {
+			return fmt.Errorf("สร้างข้อมูลรายวิชาที่เปิดสอน: %w", err)
+		}
+		sectionIDs, err := config.EnsureSections(tx, offeredCourse)
+		if err != nil {
+			return err
+		}
+		var sectionID *uint
+		if id, ok := sectionIDs[req.Section]; ok {
+			sectionID = &id
+		}
+
+		schedule = entity.Schedule{
+			NameTable:        fmt.Sprintf("ปีการศึกษา %d เทอม %d", req.Year, req.Term),
+			SectionNumber:    req.Section,
+			DayOfWeek:        req.DayOfWeek,
+			StartTime:        startTime,
+			EndTime:          endTime,
+			Kind:             entity.SessionKindLecture,
+			OfferedCoursesID: offeredCourse.ID,
+			SectionID:        sectionID,
+			RoomID:           roomID,
+		}
+		if err := tx.Create(&schedule).Error; err != nil {
+			return fmt.Errorf("สร้างตารางเรียน: %w", err)
+		}
+
+		timeFixed = entity.TimeFixedCourses{
+			Year:         req.Year,
+			Term:         req.Term,
+			DayOfWeek:    req.DayOfWeek,
+			StartTime:    startTime,
+			EndTime:      endTime,
+			RoomFix:      req.RoomFix,
+			Section:      req.SectionInFixed,
+			Capacity:     req.Capacity,
+			AllCoursesID: req.AllCoursesID,
+			ScheduleID:   schedule.ID,
+			RoomID:       roomID,
+		}
+		if err := tx.Create(&timeFixed).Error; err != nil {
+			return fmt.Errorf("สร้างข้อมูล TimeFix: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		respondSectionsError(c, err, "ไม่สามารถสร้างวิชาที่มาจากศูนย์บริการได้")
 		return
 	}
 
@@ -134,7 +165,7 @@ func UpdateFixedCourse(c *gin.Context) {
 	var req UpdateFixedCourseRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "ข้อมูลไม่ถูกต้อง: " + err.Error()})
+		respondBindError(c, err)
 		return
 	}
 
@@ -143,7 +174,11 @@ func UpdateFixedCourse(c *gin.Context) {
 
 	var offered entity.OfferedCourses
 	if err := config.DB().Preload("Schedule").First(&offered, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "ไม่พบข้อมูลวิชาที่เปิดสอน"})
+		respondError(c, http.StatusNotFound, "ไม่พบข้อมูลวิชาที่เปิดสอน")
+		return
+	}
+
+	if rejectLabCapacity(c, req.LaboratoryID, req.Capacity) {
 		return
 	}
 
@@ -154,11 +189,27 @@ func UpdateFixedCourse(c *gin.Context) {
 	// ใช้จำนวน req.Groups เป็น TotalSections อัตโนมัติ
 	offered.Section = uint(len(req.Groups))
 
-	if err := config.DB().Save(&offered).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "ไม่สามารถอัปเดต OfferedCourses ได้"})
+	if !validateEntity(c, offered) {
 		return
 	}
 
+	// ตรวจรูปแบบเวลาของทุกกลุ่มก่อนแก้ไขข้อมูล
+	type groupTimes struct{ start, end time.Time }
+	times := make([]groupTimes, len(req.Groups))
+	for i, g := range req.Groups {
+		startTime, err := time.ParseInLocation("2006-01-02 15:04", fmt.Sprintf("%s %s", today, g.StartTime), location)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, fmt.Sprintf("รูปแบบเวลาเริ่มต้นไม่ถูกต้องสำหรับ Section %d", g.Section))
+			return
+		}
+		endTime, err := time.ParseInLocation("2006-01-02 15:04", fmt.Sprintf("%s %s", today, g.EndTime), location)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, fmt.Sprintf("รูปแบบเวลาสิ้นสุดไม่ถูกต้องสำหรับ Section %d", g.Section))
+			return
+		}
+		times[i] = groupTimes{startTime, endTime}
+	}
+
 	var updatedSchedules []uint
 	var updatedTimeFixed []uint
 
@@ -168,99 +219,129 @@ func UpdateFixedCourse(c *gin.Context) {
 		groupSections[uint(g.Section)] = true
 	}
 
-	// ตรวจสอบ schedule เดิม ถ้าไม่มีใน groupSections ให้ลบ
-	var existingSchedules []entity.Schedule
-	if err := config.DB().Where("offered_courses_id = ?", offered.ID).Find(&existingSchedules).Error; err == nil {
+	err := config.DB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&offered).Error; err != nil {
+			return fmt.Errorf("อัปเดต OfferedCourses: %w", err)
+		}
+
+		// ตรวจสอบ schedule เดิม ถ้าไม่มีใน groupSections ให้ลบ (ก่อนปรับจำนวนกลุ่ม)
+		var existingSchedules []entity.Schedule
+		if err := tx.Where("offered_courses_id = ?", offered.ID).Find(&existingSchedules).Error; err != nil {
+			return err
+		}
 		for _, s := range existingSchedules {
-			if !groupSections[s.SectionNumber] {
-				// ลบ TimeFixedCourses ก่อน
-				config.DB().Where("schedule_id = ?", s.ID).Delete(&entity.TimeFixedCourses{})
-				// ลบ Schedule
-				config.DB().Delete(&s)
+			if groupSections[s.SectionNumber] {
+				continue
+			}
+			// ลบ TimeFixedCourses ก่อน
+			if err := tx.Where("schedule_id = ?", s.ID).Delete(&entity.TimeFixedCourses{}).Error; err != nil {
+				return err
+			}
+			// ลบ Schedule
+			if err := tx.Delete(&s).Error; err != nil {
+				return err
 			}
 		}
-	}
 
-	// อัปเดตหรือสร้าง schedule + time fixed ใหม่
-	for _, g := range req.Groups {
-		startDateTimeStr := fmt.Sprintf("%s %s", today, g.StartTime)
-		endDateTimeStr := fmt.Sprintf("%s %s", today, g.EndTime)
-
-		startTime, err := time.ParseInLocation("2006-01-02 15:04", startDateTimeStr, location)
+		sectionIDs, err := config.EnsureSections(tx, offered)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("รูปแบบเวลาเริ่มต้นไม่ถูกต้องสำหรับ Section %d", g.Section)})
-			return
+			return err
 		}
 
-		endTime, err := time.ParseInLocation("2006-01-02 15:04", endDateTimeStr, location)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("รูปแบบเวลาสิ้นสุดไม่ถูกต้องสำหรับ Section %d", g.Section)})
-			return
-		}
+		// อัปเดตหรือสร้าง schedule + time fixed ใหม่
+		for i, g := range req.Groups {
+			startTime, endTime := times[i].start, times[i].end
 
-		var schedule entity.Schedule
-		err = config.DB().Where("offered_courses_id = ? AND section_number = ?", offered.ID, g.Section).First(&schedule).Error
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			// สร้างใหม่
-			schedule = entity.Schedule{
-				NameTable:        fmt.Sprintf("Section %d", g.Section),
-				SectionNumber:    g.Section,
-				DayOfWeek:        g.DayOfWeek,
-				StartTime:        startTime,
-				EndTime:          endTime,
-				OfferedCoursesID: offered.ID,
-			}
-			if err := config.DB().Create(&schedule).Error; err != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "ไม่สามารถสร้าง Schedule ใหม่ได้"})
-				return
+			var roomID *uint
+			if room := config.FindRoomByName(g.RoomFix); room != nil {
+				roomID = &room.ID
 			}
-		} else {
-			// อัปเดต Schedule เดิม
-			schedule.DayOfWeek = g.DayOfWeek
-			schedule.StartTime = startTime
-			schedule.EndTime = endTime
-			if err := config.DB().Save(&schedule).Error; err != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "ไม่สามารถอัปเดต Schedule ได้"})
-				return
-			}
-		}
 
-		updatedSchedules = append(updatedSchedules, schedule.ID)
-
-		// อัปเดตหรือสร้าง TimeFixedCourses
-		var timeFixed entity.TimeFixedCourses
-		err = config.DB().Where("schedule_id = ?", schedule.ID).First(&timeFixed).Error
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			timeFixed = entity.TimeFixedCourses{
-				Year:         offered.Year,
-				Term:         offered.Term,
-				DayOfWeek:    g.DayOfWeek,
-				StartTime:    startTime,
-				EndTime:      endTime,
-				RoomFix:      g.RoomFix,
-				Section:      g.Section,
-				Capacity:     g.Capacity,
-				AllCoursesID: offered.AllCoursesID,
-				ScheduleID:   schedule.ID,
+			// ที่นั่งของกลุ่มเรียนตามที่ศูนย์บริการกำหนด
+			var sectionID *uint
+			if id, ok := sectionIDs[g.Section]; ok {
+				sectionID = &id
+				if err := tx.Model(&entity.Section{}).Where("id = ?", id).Update("capacity", g.Capacity).Error; err != nil {
+					return fmt.Errorf("อัปเดตที่นั่งของ Section %d: %w", g.Section, err)
+				}
 			}
-			if err := config.DB().Create(&timeFixed).Error; err != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "ไม่สามารถสร้าง TimeFixedCourses ใหม่ได้"})
-				return
+
+			var schedule entity.Schedule
+			err = tx.Where("offered_courses_id = ? AND section_number = ?", offered.ID, g.Section).First(&schedule).Error
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				// สร้างใหม่
+				schedule = entity.Schedule{
+					NameTable:        fmt.Sprintf("Section %d", g.Section),
+					SectionNumber:    g.Section,
+					DayOfWeek:        g.DayOfWeek,
+					StartTime:        startTime,
+					EndTime:          endTime,
+					Kind:             entity.SessionKindLecture,
+					OfferedCoursesID: offered.ID,
+					SectionID:        sectionID,
+					RoomID:           roomID,
+				}
+				if err := tx.Create(&schedule).Error; err != nil {
+					return fmt.Errorf("สร้าง Schedule ใหม่: %w", err)
+				}
+			} else if err != nil {
+				return err
+			} else {
+				// อัปเดต Schedule เดิม
+				schedule.DayOfWeek = g.DayOfWeek
+				schedule.StartTime = startTime
+				schedule.EndTime = endTime
+				schedule.SectionID = sectionID
+				schedule.RoomID = roomID
+				if err := tx.Save(&schedule).Error; err != nil {
+					return fmt.Errorf("อัปเดต Schedule: %w", err)
+				}
 			}
-		} else {
-			timeFixed.DayOfWeek = g.DayOfWeek
-			timeFixed.StartTime = startTime
-			timeFixed.EndTime = endTime
-			timeFixed.RoomFix = g.RoomFix
-			timeFixed.Section = g.Section
-			timeFixed.Capacity = g.Capacity
-			if err := config.DB().Save(&timeFixed).Error; err != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "ไม่สามารถอัปเดต TimeFixedCourses ได้"})
-				return
+
+			updatedSchedules = append(updatedSchedules, schedule.ID)
+
+			// อัปเดตหรือสร้าง TimeFixedCourses
+			var timeFixed entity.TimeFixedCourses
+			err = tx.Where("schedule_id = ?", schedule.ID).First(&timeFixed).Error
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				timeFixed = entity.TimeFixedCourses{
+					Year:         offered.Year,
+					Term:         offered.Term,
+					DayOfWeek:    g.DayOfWeek,
+					StartTime:    startTime,
+					EndTime:      endTime,
+					RoomFix:      g.RoomFix,
+					Section:      g.Section,
+					Capacity:     g.Capacity,
+					AllCoursesID: offered.AllCoursesID,
+					ScheduleID:   schedule.ID,
+					RoomID:       roomID,
+				}
+				if err := tx.Create(&timeFixed).Error; err != nil {
+					return fmt.Errorf("สร้าง TimeFixedCourses ใหม่: %w", err)
+				}
+			} else if err != nil {
+				return err
+			} else {
+				timeFixed.DayOfWeek = g.DayOfWeek
+				timeFixed.StartTime = startTime
+				timeFixed.EndTime = endTime
+				timeFixed.RoomFix = g.RoomFix
+				timeFixed.Section = g.Section
+				timeFixed.Capacity = g.Capacity
+				timeFixed.RoomID = roomID
+				if err := tx.Save(&timeFixed).Error; err != nil {
+					return fmt.Errorf("อัปเดต TimeFixedCourses: %w", err)
+				}
 			}
-		}
 
-		updatedTimeFixed = append(updatedTimeFixed, timeFixed.ID)
+			updatedTimeFixed = append(updatedTimeFixed, timeFixed.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		respondSectionsError(c, err, "ไม่สามารถอัปเดตรายวิชาจากศูนย์บริการได้")
+		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
@@ -0,0 +1,356 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/Nichakorn25/CPE-Teaching-Schedule/config"
+	"github.com/Nichakorn25/CPE-Teaching-Schedule/entity"
+)
+
+type (
+	TimeGridBlockInput struct {
+		DayOfWeek string `json:"day_of_week"`
+		StartTime string `json:"start_time" binding:"required"`
+		EndTime   string `json:"end_time" binding:"required"`
+		Label     string `json:"label"`
+	}
+
+	TimeGridInput struct {
+		WorkingDays    []string             `json:"working_days" binding:"required,min=1"`
+		DayStart       string               `json:"day_start" binding:"required"`
+		DayEnd         string               `json:"day_end" binding:"required"`
+		SlotMinutes    uint                 `json:"slot_minutes" binding:"required"`
+		PreferredStart string               `json:"preferred_start" binding:"required"`
+		PreferredEnd   string               `json:"preferred_end" binding:"required"`
+		FallbackStart  string               `json:"fallback_start"`
+		FallbackEnd    string               `json:"fallback_end"`
+		BlockedPeriods []TimeGridBlockInput `json:"blocked_periods"`
+	}
+)
+
+var thaiDays = []string{"จันทร์", "อังคาร", "พุธ", "พฤหัสบดี", "ศุกร์", "เสาร์", "อาทิตย์"}
+
+// dayIndex ลำดับวันในสัปดาห์ (จันทร์ = 0) คืน -1 ถ้าไม่ใช่ชื่อวัน
+func dayIndex(name string) int {
+	for i, d := range thaiDays {
+		if d == name {
+			return i
+		}
+	}
+	return -1
+}
+
+type gridBlock struct {
+	day        string // ว่าง = ทุกวัน
+	start, end int
+}
+
+// timeGrid ตารางเวลาที่แปลงเป็นนาทีนับจากเที่ยงคืนแล้ว
+type timeGrid struct {
+	days               []int
+	dayStart, dayEnd   int
+	slot               int
+	prefStart, prefEnd int
+	fallStart, fallEnd int
+	blocked            []gridBlock
+}
+
+// defaultTimeGrid ค่าเดิมที่ตัวจัดตารางเคยใช้: จันทร์-ศุกร์ 08:00-21:00 คาบละชั่วโมง พักเที่ยง 12:00-13:00
+func defaultTimeGrid(deptID uint) entity.TimeGrid {
+	return entity.TimeGrid{
+		WorkingDays:    strings.Join(thaiDays[:5], ","),
+		DayStart:       "08:00",
+		DayEnd:         "21:00",
+		SlotMinutes:    60,
+		PreferredStart: "08:00",
+		PreferredEnd:   "16:00",
+		FallbackStart:  "16:00",
+		FallbackEnd:    "21:00",
+		DepartmentID:   deptID,
+		BlockedPeriods: []entity.TimeGridBlock{
+			{StartTime: "12:00", EndTime: "13:00", Label: "พักกลางวัน"},
+		},
+	}
+}
+
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		if s == "24:00" {
+			return 24 * 60, nil
+		}
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// compileTimeGrid ตรวจและแปลง entity.TimeGrid เป็นนาที
+func compileTimeGrid(g entity.TimeGrid) (*timeGrid, error) {
+	tg := &timeGrid{slot: int(g.SlotMinutes)}
+	if tg.slot != 15 && tg.slot != 30 && tg.slot != 60 {
+		return nil, fmt.Errorf("ช่องเวลาต้องเป็น 15, 30 หรือ 60 นาที")
+	}
+
+	for _, d := range strings.Split(g.WorkingDays, ",") {
+		d = strings.TrimSpace(d)
+		i := dayIndex(d)
+		if i < 0 {
+			return nil, fmt.Errorf("ไม่รู้จักวัน %q", d)
+		}
+		tg.days = append(tg.days, i)
+	}
+
+	pairs := []struct {
+		from, to   string
+		start, end *int
+		name       string
+	}{
+		{g.DayStart, g.DayEnd, &tg.dayStart, &tg.dayEnd, "ช่วงเวลาทำการ"},
+		{g.PreferredStart, g.PreferredEnd, &tg.prefStart, &tg.prefEnd, "ช่วงเวลาที่ต้องการ"},
+		{g.FallbackStart, g.FallbackEnd, &tg.fallStart, &tg.fallEnd, "ช่วงเวลาสำรอง"},
+	}
+	for _, p := range pairs {
+		if p.from == "" && p.to == "" {
+			continue
+		}
+		start, err1 := parseClock(p.from)
+		end, err2 := parseClock(p.to)
+		if err1 != nil || err2 != nil || start >= end {
+			return nil, fmt.Errorf("%s ไม่ถูกต้อง", p.name)
+		}
+		*p.start, *p.end = start, end
+	}
+	if len(tg.days) == 0 || tg.dayEnd == 0 || tg.prefEnd == 0 {
+		return nil, fmt.Errorf("ต้องระบุวันทำการ ช่วงเวลาทำการ และช่วงเวลาที่ต้องการ")
+	}
+	if tg.prefStart < tg.dayStart || tg.prefEnd > tg.dayEnd ||
+		(tg.fallEnd > 0 && (tg.fallStart < tg.dayStart || tg.fallEnd > tg.dayEnd)) {
+		return nil, fmt.Errorf("ช่วงเวลาที่ต้องการ/สำรองต้องอยู่ในช่วงเวลาทำการ")
+	}
+
+	for _, b := range g.BlockedPeriods {
+		start, err1 := parseClock(b.StartTime)
+		end, err2 := parseClock(b.EndTime)
+		if err1 != nil || err2 != nil || start >= end {
+			return nil, fmt.Errorf("ช่วงเวลาห้ามจัด %s-%s ไม่ถูกต้อง", b.StartTime, b.EndTime)
+		}
+		if b.DayOfWeek != "" && dayIndex(b.DayOfWeek) < 0 {
+			return nil, fmt.Errorf("ไม่รู้จักวัน %q", b.DayOfWeek)
+		}
+		tg.blocked = append(tg.blocked, gridBlock{day: b.DayOfWeek, start: start, end: end})
+	}
+	return tg, nil
+}
+
+// loadTimeGrid ตารางเวลาของภาควิชา ถ้ายังไม่ได้ตั้งค่าใช้ค่าเริ่มต้น
+func loadTimeGrid(deptID uint) (*timeGrid, error) {
+	var g entity.TimeGrid
+	err := config.DB().Preload("BlockedPeriods").Where("department_id = ?", deptID).First(&g).Error
+	if err == gorm.ErrRecordNotFound {
+		g = defaultTimeGrid(deptID)
+	} else if err != nil {
+		return nil, err
+	}
+	return compileTimeGrid(g)
+}
+
+// departmentOfOfferedCourse ภาควิชาเจ้าของหลักสูตรของรายวิชาที่เปิดสอน
+func departmentOfOfferedCourse(offeredCoursesID uint) uint {
+	var deptID uint
+	config.DB().
+		Table("offered_courses").
+		Select("majors.department_id").
+		Joins("JOIN all_courses ON offered_courses.all_courses_id = all_courses.id").
+		Joins("JOIN curriculums ON all_courses.curriculum_id = curriculums.id").
+		Joins("JOIN majors ON curriculums.major_id = majors.id").
+		Where("offered_courses.id = ?", offeredCoursesID).
+		Scan(&deptID)
+	return deptID
+}
+
+func (g *timeGrid) isWorkingDay(day string) bool {
+	i := dayIndex(day)
+	for _, d := range g.days {
+		if d == i {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *timeGrid) isBlocked(day string, start, end int) bool {
+	for _, b := range g.blocked {
+		if (b.day == "" || b.day == day) && start < b.end && end > b.start {
+			return true
+		}
+	}
+	return false
+}
+
+// openMinutes จำนวนนาทีที่จัดคาบได้ในวันนั้น (ช่วงเวลาทำการหักช่วงห้ามจัด) วันที่ไม่ใช่วันทำการคืน 0
+func (g *timeGrid) openMinutes(day string) int {
+	if !g.isWorkingDay(day) {
+		return 0
+	}
+	blocked := []clockInterval{}
+	for _, b := range g.blocked {
+		start, end := max(b.start, g.dayStart), min(b.end, g.dayEnd)
+		if (b.day == "" || b.day == day) && start < end {
+			blocked = append(blocked, clockInterval{start: start, end: end})
+		}
+	}
+	return g.dayEnd - g.dayStart - mergedMinutes(blocked)
+}
+
+// violation เหตุผลที่ช่วงเวลานี้ใช้ไม่ได้ตามตารางเวลา คืน "" ถ้าใช้ได้
+func (g *timeGrid) violation(day string, start, end time.Time) string {
+	s, e := clockMinutes(start), clockMinutes(end)
+	switch {
+	case !g.isWorkingDay(day):
+		return fmt.Sprintf("วัน%s ไม่ใช่วันทำการของภาควิชา", day)
+	case s < g.dayStart || e > g.dayEnd:
+		return "เวลาอยู่นอกช่วงเวลาทำการของภาควิชา"
+	case (s-g.dayStart)%g.slot != 0 || (e-s)%g.slot != 0:
+		return fmt.Sprintf("เวลาต้องลงตามช่องเวลา %d นาที", g.slot)
+	case g.isBlocked(day, s, e):
+		return "ช่วงเวลานี้เป็นช่วงที่ห้ามจัดคาบ"
+	}
+	return ""
+}
+
+// starts เวลาเริ่มที่เป็นไปได้ของคาบยาว length นาทีภายในช่วง [from, to)
+func (g *timeGrid) starts(day string, from, to, length int) []int {
+	out := []int{}
+	for s := g.dayStart; s+length <= g.dayEnd; s += g.slot {
+		if s < from || s+length > to || g.isBlocked(day, s, s+length) {
+			continue
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+func gridTime(minutes int) time.Time {
+	return time.Date(2006, 1, 2, 0, minutes, 0, 0, time.FixedZone("Asia/Bangkok", 7*60*60))
+}
+
+func timeGridResponse(g entity.TimeGrid) gin.H {
+	blocks := make([]TimeGridBlockInput, 0, len(g.BlockedPeriods))
+	for _, b := range g.BlockedPeriods {
+		blocks = append(blocks, TimeGridBlockInput{DayOfWeek: b.DayOfWeek, StartTime: b.StartTime, EndTime: b.EndTime, Label: b.Label})
+	}
+	return gin.H{
+		"department_id":   g.DepartmentID,
+		"is_default":      g.ID == 0,
+		"working_days":    strings.Split(g.WorkingDays, ","),
+		"day_start":       g.DayStart,
+		"day_end":         g.DayEnd,
+		"slot_minutes":    g.SlotMinutes,
+		"preferred_start": g.PreferredStart,
+		"preferred_end":   g.PreferredEnd,
+		"fallback_start":  g.FallbackStart,
+		"fallback_end":    g.FallbackEnd,
+		"blocked_periods": blocks,
+	}
+}
+
+// GET /departments/:id/time-grid
+func GetTimeGrid(c *gin.Context) {
+	var dept entity.Department
+	if err := config.DB().First(&dept, c.Param("id")).Error; err != nil {
+		respondError(c, http.StatusNotFound, "ไม่พบภาควิชา")
+		return
+	}
+
+	var g entity.TimeGrid
+	err := config.DB().Preload("BlockedPeriods").Where("department_id = ?", dept.ID).First(&g).Error
+	if err == gorm.ErrRecordNotFound {
+		g = defaultTimeGrid(dept.ID)
+	} else if err != nil {
+		respondServerError(c, http.StatusInternalServerError, "ไม่สามารถดึงตารางเวลาได้", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, timeGridResponse(g))
+}
+
+// PUT /departments/:id/time-grid บันทึกตารางเวลาของภาควิชา (แทนที่ช่วงห้ามจัดทั้งหมด)
+func UpdateTimeGrid(c *gin.Context) {
+	var dept entity.Department
+	if err := config.DB().First(&dept, c.Param("id")).Error; err != nil {
+		respondError(c, http.StatusNotFound, "ไม่พบภาควิชา")
+		return
+	}
+
+	var input TimeGridInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	var g entity.TimeGrid
+	if err := config.DB().Where("department_id = ?", dept.ID).First(&g).Error; err != nil && err != gorm.ErrRecordNotFound {
+		respondServerError(c, http.StatusInternalServerError, "ไม่สามารถดึงตารางเวลาได้", err)
+		return
+	}
+
+	g.DepartmentID = dept.ID
+	g.WorkingDays = strings.Join(input.WorkingDays, ",")
+	g.DayStart = input.DayStart
+	g.DayEnd = input.DayEnd
+	g.SlotMinutes = input.SlotMinutes
+	g.PreferredStart = input.PreferredStart
+	g.PreferredEnd = input.PreferredEnd
+	g.FallbackStart = input.FallbackStart
+	g.FallbackEnd = input.FallbackEnd
+	g.BlockedPeriods = make([]entity.TimeGridBlock, 0, len(input.BlockedPeriods))
+	for _, b := range input.BlockedPeriods {
+		g.BlockedPeriods = append(g.BlockedPeriods, entity.TimeGridBlock{
+			DayOfWeek: b.DayOfWeek,
+			StartTime: b.StartTime,
+			EndTime:   b.EndTime,
+			Label:     b.Label,
+		})
+	}
+
+	if !validateEntity(c, g, g.BlockedPeriods) {
+		return
+	}
+	if _, err := compileTimeGrid(g); err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err := config.DB().Transaction(func(tx *gorm.DB) error {
+		blocks := g.BlockedPeriods
+		g.BlockedPeriods = nil
+		if err := tx.Save(&g).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("time_grid_id = ?", g.ID).Delete(&entity.TimeGridBlock{}).Error; err != nil {
+			return err
+		}
+		for i := range blocks {
+			blocks[i].TimeGridID = g.ID
+		}
+		if len(blocks) > 0 {
+			if err := tx.Create(&blocks).Error; err != nil {
+				return err
+			}
+		}
+		g.BlockedPeriods = blocks
+		return nil
+	})
+	if err != nil {
+		respondServerError(c, http.StatusInternalServerError, "ไม่สามารถบันทึกตารางเวลาได้", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "บันทึกตารางเวลาสำเร็จ", "data": timeGridResponse(g)})
+}
@@ -12,7 +12,7 @@ import (
 func GetTypeOfCourses(c *gin.Context) {
 	var types []entity.TypeOfCourses
 	if err := config.DB().Find(&types).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "ไม่สามารถดึงข้อมูลหมวดวิชาได้"})
+		respondError(c, http.StatusInternalServerError, "ไม่สามารถดึงข้อมูลหมวดวิชาได้")
 		return
 	}
 	c.JSON(http.StatusOK, types)
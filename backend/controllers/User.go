@@ -4,9 +4,11 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 
 	"github.com/Nichakorn25/CPE-Teaching-Schedule/config"
 	"github.com/Nichakorn25/CPE-Teaching-Schedule/entity"
+	"github.com/Nichakorn25/CPE-Teaching-Schedule/services"
 )
 
 func GetTeachers(c *gin.Context) {  // ดึงไปเพื่อใช้เลือก
@@ -15,18 +17,53 @@ func GetTeachers(c *gin.Context) {  // ดึงไปเพื่อใช้
     c.JSON(http.StatusOK, instructer)
 }
 
+var teachersListSpec = services.ListSpec{
+	Sorts: map[string]string{
+		"id":        "users.id",
+		"firstname": "users.firstname",
+		"lastname":  "users.lastname",
+		"email":     "users.email",
+		"username":  "users.username",
+		"major":     "majors.major_name",
+		"position":  "users.position_id",
+	},
+	Filters: map[string]services.ListFilter{
+		"major_id":      {Kind: services.FilterUint, Where: "users.major_id = ?"},
+		"department_id": {Kind: services.FilterUint, Where: "majors.department_id = ?"},
+		"position_id":   {Kind: services.FilterUint, Where: "users.position_id = ?"},
+		"role_id":       {Kind: services.FilterUint, Where: "users.role_id = ?"},
+		"search":        {Kind: services.FilterSearch, Where: "(users.firstname ILIKE ? OR users.lastname ILIKE ? OR users.email ILIKE ? OR users.username ILIKE ?)"},
+	},
+	DefaultSort: "id",
+}
+
 func GetAllTeachers(c *gin.Context) {  //นำไปแสดงเป็น List 
-	var users []entity.User
+	q, ok := listQuery(c, teachersListSpec)
+	if !ok {
+		return
+	}
+	base := func() *gorm.DB {
+		return q.Filter(config.DB().Model(&entity.User{}).
+			Joins("LEFT JOIN majors ON majors.id = users.major_id").
+			Where("users.role_id != ?", 1))
+	}
+
+	var total int64
+	if err := base().Count(&total).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, "ไม่สามารถดึงข้อมูลผู้ใช้ได้")
+		return
+	}
 
-	err := config.DB().Preload("Title").
+	var users []entity.User
+	err := q.Paginate(base()).
+		Preload("Title").
 		Preload("Position").
 		Preload("Major").
 		Preload("Major.Department").
 		Preload("Role").
-		Where(`Role_id != ?`, 1).
 		Find(&users).Error
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "ไม่สามารถดึงข้อมูลผู้ใช้ได้"})
+		respondError(c, http.StatusInternalServerError, "ไม่สามารถดึงข้อมูลผู้ใช้ได้")
 		return
 	}
 
@@ -61,7 +98,7 @@ func GetAllTeachers(c *gin.Context) {  //นำไปแสดงเป็น Li
 		})
 	}
 
-	c.JSON(http.StatusOK, resp)
+	respondList(c, q, total, resp, resp)
 }
 
 func GetUserByID(c *gin.Context) {
@@ -76,7 +113,7 @@ func GetUserByID(c *gin.Context) {
 		First(&user, id).Error
 
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "ไม่พบผู้ใช้"})
+		respondError(c, http.StatusNotFound, "ไม่พบผู้ใช้")
 		return
 	}
 
@@ -119,19 +156,13 @@ func CreateUser(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "ข้อมูลไม่ถูกต้อง"})
-		return
-	}
-
-	hashedPassword, err := config.HashPassword(input.Password)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "เข้ารหัสรหัสผ่านล้มเหลว"})
+		respondError(c, http.StatusBadRequest, "ข้อมูลไม่ถูกต้อง")
 		return
 	}
 
 	user := entity.User{
 		Username:      input.Username,
-		Password:      hashedPassword,
+		Password:      input.Password,
 		Firstname:     input.Firstname,
 		Lastname:      input.Lastname,
 		Image:         input.Image,
@@ -144,9 +175,19 @@ func CreateUser(c *gin.Context) {
 		MajorID:       input.MajorID,
 		RoleID:        input.RoleID,
 	}
+	if !validateEntity(c, user) {
+		return
+	}
+
+	hashedPassword, err := config.HashPassword(input.Password)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "เข้ารหัสรหัสผ่านล้มเหลว")
+		return
+	}
+	user.Password = hashedPassword
 
 	if err := config.DB().Create(&user).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "ไม่สามารถเพิ่มผู้ใช้ได้"})
+		respondError(c, http.StatusInternalServerError, "ไม่สามารถเพิ่มผู้ใช้ได้")
 		return
 	}
 
@@ -158,7 +199,7 @@ func UpdateUser(c *gin.Context) {
 
 	var user entity.User
 	if err := config.DB().First(&user, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "ไม่พบผู้ใช้ที่ต้องการแก้ไข"})
+		respondError(c, http.StatusNotFound, "ไม่พบผู้ใช้ที่ต้องการแก้ไข")
 		return
 	}
 
@@ -177,10 +218,11 @@ func UpdateUser(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "ข้อมูลไม่ถูกต้อง"})
+		respondError(c, http.StatusBadRequest, "ข้อมูลไม่ถูกต้อง")
 		return
 	}
 
+	before := user
 	user.Username = input.Username
 	user.Firstname = input.Firstname
 	user.Lastname = input.Lastname
@@ -192,9 +234,13 @@ func UpdateUser(c *gin.Context) {
 	user.PositionID = input.PositionID
 	user.MajorID = input.MajorID
 	user.RoleID = input.RoleID
+	// ตรวจเฉพาะฟิลด์ที่เปลี่ยน บัญชีเดิม (เช่น admin) ที่ไม่ตรงกฎชื่อผู้ใช้ยังแก้ข้อมูลอื่นได้
+	if !validateChanges(c, before, user) {
+		return
+	}
 
 	if err := config.DB().Save(&user).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "ไม่สามารถอัปเดตข้อมูลผู้ใช้ได้"})
+		respondError(c, http.StatusInternalServerError, "ไม่สามารถอัปเดตข้อมูลผู้ใช้ได้")
 		return
 	}
 
@@ -206,14 +252,20 @@ func DeleteUser(c *gin.Context) {
 
 	var user entity.User
 	if err := config.DB().First(&user, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "ไม่พบผู้ใช้ที่ต้องการลบ"})
+		respondError(c, http.StatusNotFound, "ไม่พบผู้ใช้ที่ต้องการลบ")
 		return
 	}
 
-	if err := config.DB().Delete(&user).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "ไม่สามารถลบผู้ใช้ได้"})
+	// เงื่อนไขเวลาของผู้สอนถูกลบตามไปเสมอ ส่วนรายวิชาที่เปิดสอนและกลุ่มเรียนที่สอนต้องขอ cascade
+	plan, ok := deleteWithDependents(c, config.KindUser, user.ID, "ไม่สามารถลบผู้ใช้ได้")
+	if !ok {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "ลบผู้ใช้เรียบร้อยแล้ว"})
+	c.JSON(http.StatusOK, gin.H{"message": "ลบผู้ใช้เรียบร้อยแล้ว", "cascaded": plan.Cascaded, "detached": plan.Detached})
+}
+
+// RestoreUser กู้คืนผู้ใช้ที่ถูกลบ
+func RestoreUser(c *gin.Context) {
+	restoreDeleted(c, config.KindUser)
 }
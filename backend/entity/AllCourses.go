@@ -4,12 +4,13 @@ import "gorm.io/gorm"
 
 type AllCourses struct {
 	gorm.Model
-	Code string `gorm:"unique" valid:"required~Code is required."`
-	EnglishName string `valid:"required~English name is required.,alpha~English name must contain only letters."`
+	// รหัสวิชาไม่ซ้ำภายในหลักสูตรเดียวกัน (หลักสูตรใหม่ใช้รหัสเดิมได้) ไม่นับรายวิชาที่ถูกลบ
+	Code string `gorm:"uniqueIndex:idx_course_code_curriculum,where:deleted_at IS NULL" valid:"required~Code is required."`
+	EnglishName string `valid:"required~English name is required.,name~English name must contain only letters."`
 	ThaiName    string	`valid:"required~ThaiName is required."`
-	Ismain	 	bool   `valid:"required~ismain is required."`
+	Ismain	 	bool
 
-	CurriculumID    uint
+	CurriculumID    uint `gorm:"uniqueIndex:idx_course_code_curriculum,where:deleted_at IS NULL"`
 	Curriculum      Curriculum `gorm:"foreignKey:CurriculumID"`
 	AcademicYearID  *uint
 	AcademicYear    AcademicYear `gorm:"foreignKey:AcademicYearID"`
@@ -21,4 +22,5 @@ type AllCourses struct {
 	UserAllCourses   []UserAllCourses   `gorm:"foreignKey:AllCoursesID"`
 	OfferedCourses   []OfferedCourses   `gorm:"foreignKey:AllCoursesID"`
 	TimeFixedCourses []TimeFixedCourses `gorm:"foreignKey:AllCoursesID"`
+	Requisites       []CoursePrerequisite `gorm:"foreignKey:AllCoursesID"`
 }
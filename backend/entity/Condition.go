@@ -5,6 +5,8 @@ import (
 	"gorm.io/gorm"
 )
 
+// ช่วงวันที่ของเงื่อนไข: ไม่ระบุ = ทุกสัปดาห์ตลอดภาคเรียน, EffectiveFrom/EffectiveTo = เฉพาะช่วงวันที่ (เช่น ลา),
+// Date = ครั้งเดียวในวันนั้น (DayOfWeek ต้องตรงกับวันของ Date)
 type Condition struct {
 	gorm.Model
 
@@ -12,6 +14,16 @@ type Condition struct {
 	StartTime time.Time `valid:"required~StartTime is required."`
 	EndTime   time.Time `valid:"required~EndTime is required."`
 
+	EffectiveFrom *time.Time `gorm:"type:date"`
+	EffectiveTo   *time.Time `gorm:"type:date"`
+	Date          *time.Time `gorm:"type:date"`
+	Note          string
+
 	UserID uint `valid:"required~UserID is required."`
 	User User `gorm:"foreignKey:UserID" valid:"-"`
 }
+
+// IsWeekly เงื่อนไขรายสัปดาห์ที่ใช้ตลอดภาคเรียน (ตัวจัดตารางถือเป็นเวลาไม่ว่างแบบตายตัว)
+func (c Condition) IsWeekly() bool {
+	return c.Date == nil && c.EffectiveFrom == nil && c.EffectiveTo == nil
+}
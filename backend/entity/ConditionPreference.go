@@ -0,0 +1,33 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ConditionPreference ความต้องการด้านเวลาแบบยืดหยุ่นของผู้สอน (ต่างจาก Condition ที่เป็นเวลาไม่ว่าง)
+// Weight บวก = อยากสอนช่วงนี้ (เช่น ชอบสอนช่วงเช้า) ลบ = เลี่ยงช่วงนี้ (เช่น ไม่อยากสอนบ่ายวันศุกร์)
+// DayOfWeek ว่าง = ทุกวัน
+type ConditionPreference struct {
+	gorm.Model
+
+	DayOfWeek string
+	StartTime time.Time `valid:"required~StartTime is required."`
+	EndTime   time.Time `valid:"required~EndTime is required."`
+	Weight    int       `valid:"required~Weight is required."`
+
+	UserID uint `valid:"required~UserID is required."`
+	User   User `gorm:"foreignKey:UserID" valid:"-"`
+}
+
+// ConditionLimit ขีดจำกัดภาระสอนที่ผู้สอนต้องการ (0 = ไม่จำกัด)
+type ConditionLimit struct {
+	gorm.Model
+
+	MaxTeachingDays uint
+	MaxHoursPerDay  uint
+
+	UserID uint `valid:"required~UserID is required." gorm:"uniqueIndex"`
+	User   User `gorm:"foreignKey:UserID" valid:"-"`
+}
@@ -0,0 +1,22 @@
+package entity
+
+import "gorm.io/gorm"
+
+// CourseEquivalence รายวิชาสองวิชาต่างหลักสูตรที่เทียบเท่ากัน (ความสัมพันธ์สองทาง บันทึกแถวเดียว)
+type CourseEquivalence struct {
+	gorm.Model
+
+	AllCoursesID uint       `valid:"required~AllCoursesID is required." gorm:"uniqueIndex:idx_course_equivalence"`
+	AllCourses   AllCourses `gorm:"foreignKey:AllCoursesID" valid:"-"`
+	EquivalentID uint       `valid:"required~EquivalentID is required." gorm:"uniqueIndex:idx_course_equivalence"`
+	Equivalent   AllCourses `gorm:"foreignKey:EquivalentID" valid:"-"`
+}
+
+// OfferedCourseAlias รายวิชาเทียบเท่าที่เรียนรวมในวิชาที่เปิดสอน (เช่น ช่วงเปลี่ยนหลักสูตร)
+type OfferedCourseAlias struct {
+	gorm.Model
+
+	OfferedCoursesID uint       `valid:"required~OfferedCoursesID is required." gorm:"uniqueIndex:idx_offered_course_alias"`
+	AllCoursesID     uint       `valid:"required~AllCoursesID is required." gorm:"uniqueIndex:idx_offered_course_alias"`
+	AllCourses       AllCourses `gorm:"foreignKey:AllCoursesID" valid:"-"`
+}
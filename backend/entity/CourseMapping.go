@@ -0,0 +1,16 @@
+package entity
+
+import "gorm.io/gorm"
+
+// CourseMapping จับคู่รายวิชาระหว่างหลักสูตรฉบับเก่าและฉบับปรับปรุง กรณีเปลี่ยนรหัสวิชา
+// (วิชาที่รหัสเหมือนกันหลัง normalize จับคู่ให้เองโดยไม่ต้องบันทึก)
+type CourseMapping struct {
+	gorm.Model
+
+	OldCourseID uint       `valid:"required~OldCourseID is required." gorm:"uniqueIndex:idx_course_mapping"`
+	OldCourse   AllCourses `gorm:"foreignKey:OldCourseID" valid:"-"`
+	NewCourseID uint       `valid:"required~NewCourseID is required." gorm:"uniqueIndex:idx_course_mapping"`
+	NewCourse   AllCourses `gorm:"foreignKey:NewCourseID" valid:"-"`
+
+	Note string
+}
@@ -0,0 +1,20 @@
+package entity
+
+import "gorm.io/gorm"
+
+// ชนิดความสัมพันธ์ระหว่างรายวิชา
+const (
+	RequisitePre = "prerequisite" // ต้องผ่าน RequiresID ก่อนเรียน AllCoursesID
+	RequisiteCo  = "corequisite"  // ต้องเรียน RequiresID ก่อนหรือพร้อมกับ AllCoursesID
+)
+
+type CoursePrerequisite struct {
+	gorm.Model
+
+	Kind string `valid:"required~Kind is required.,in(prerequisite|corequisite)~Kind is invalid."`
+
+	AllCoursesID uint       `valid:"required~AllCoursesID is required." gorm:"uniqueIndex:idx_course_requisite"`
+	AllCourses   AllCourses `gorm:"foreignKey:AllCoursesID" valid:"-"`
+	RequiresID   uint       `valid:"required~RequiresID is required." gorm:"uniqueIndex:idx_course_requisite"`
+	Requires     AllCourses `gorm:"foreignKey:RequiresID" valid:"-"`
+}
@@ -0,0 +1,25 @@
+package entity
+
+import (
+	"gorm.io/gorm"
+)
+
+// CreditHourRule กติกาแปลงหน่วยกิตเป็นชั่วโมงสอนต่อสัปดาห์
+// ผูกกับประเภทรายวิชา (TypeOfCoursesID) หรือรายวิชาเดียว (AllCoursesID) อย่างใดอย่างหนึ่ง
+// ฟิลด์ที่เป็น nil จะใช้ค่าจากกติกาที่กว้างกว่า (รายวิชา -> ประเภทรายวิชา -> ค่าเริ่มต้น)
+type CreditHourRule struct {
+	gorm.Model
+
+	LecturePerCredit *uint // ชั่วโมงบรรยายต่อ 1 หน่วยกิตบรรยาย (ค่าเริ่มต้น 1)
+	LabPerCredit     *uint // ชั่วโมงปฏิบัติต่อ 1 หน่วยกิตปฏิบัติ (ค่าเริ่มต้น 3)
+	LectureHours     *uint // กำหนดชั่วโมงบรรยายต่อสัปดาห์โดยตรง (แทนสูตรต่อหน่วยกิต)
+	LabHours         *uint // กำหนดชั่วโมงปฏิบัติต่อสัปดาห์โดยตรง
+	MaxLectureBlock  *uint // ชั่วโมงบรรยายต่อเนื่องสูงสุดต่อครั้ง (ค่าเริ่มต้น 3)
+	MaxLabBlock      *uint // ชั่วโมงปฏิบัติต่อเนื่องสูงสุดต่อครั้ง (0 = ทั้งหมดในครั้งเดียว)
+
+	TypeOfCoursesID *uint         `gorm:"uniqueIndex"`
+	TypeOfCourses   TypeOfCourses `gorm:"foreignKey:TypeOfCoursesID" valid:"-"`
+
+	AllCoursesID *uint      `gorm:"uniqueIndex"`
+	AllCourses   AllCourses `gorm:"foreignKey:AllCoursesID" valid:"-"`
+}
@@ -12,4 +12,5 @@ type Curriculum struct {
 	Major   Major `gorm:"foreignKey:MajorID"`
 
 	AllCourses []AllCourses `gorm:"foreignKey:CurriculumID"`
+	StudyPlan  []StudyPlanEntry `gorm:"foreignKey:CurriculumID"`
 }
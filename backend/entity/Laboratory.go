@@ -6,9 +6,10 @@ import (
 
 type Laboratory struct {
 	gorm.Model
-	Room     string
-	Building string
-	Capacity string
+	Room     string `valid:"required~Room is required."`
+	Building string `valid:"required~Building is required."`
+	Capacity uint   `valid:"required~Capacity is required."`
 
 	OfferedCourses []OfferedCourses `gorm:"foreignKey:LaboratoryID"`
+	Rooms          []Room           `gorm:"foreignKey:LaboratoryID"`
 }
@@ -22,5 +22,8 @@ type OfferedCourses struct {
 	LaboratoryID *uint
 	Laboratory   Laboratory `gorm:"foreignKey:LaboratoryID" valid:"-"`
 
-	Schedule []Schedule `gorm:"foreignKey:OfferedCoursesID"`
+	Schedule           []Schedule          `gorm:"foreignKey:OfferedCoursesID"`
+	Sections           []Section           `gorm:"foreignKey:OfferedCoursesID"`
+	SectionInstructors []SectionInstructor `gorm:"foreignKey:OfferedCoursesID"`
+	Aliases            []OfferedCourseAlias `gorm:"foreignKey:OfferedCoursesID"`
 }
@@ -0,0 +1,28 @@
+package entity
+
+import (
+	"gorm.io/gorm"
+)
+
+// ประเภทห้องเรียน
+const (
+	RoomTypeLectureHall  = "lecture_hall"
+	RoomTypeLab          = "lab"
+	RoomTypeComputerRoom = "computer_room"
+)
+
+type Room struct {
+	gorm.Model
+	// unique เฉพาะห้องที่ยังไม่ถูกลบ เพื่อให้สร้างห้องชื่อเดิมใหม่ได้หลังลบ
+	Name      string `gorm:"uniqueIndex:idx_room_name_building,where:deleted_at IS NULL" valid:"required~Name is required."`
+	Building  string `gorm:"uniqueIndex:idx_room_name_building,where:deleted_at IS NULL" valid:"required~Building is required."`
+	RoomType  string `valid:"required~RoomType is required.,in(lecture_hall|lab|computer_room)~RoomType is invalid."`
+	Capacity  uint   `valid:"required~Capacity is required."`
+	Equipment string `valid:"optional"` // แท็กอุปกรณ์คั่นด้วย comma เช่น "projector,computer"
+
+	LaboratoryID *uint
+	Laboratory   Laboratory `gorm:"foreignKey:LaboratoryID" valid:"-"`
+
+	Schedules        []Schedule         `gorm:"foreignKey:RoomID"`
+	TimeFixedCourses []TimeFixedCourses `gorm:"foreignKey:RoomID"`
+}
@@ -5,6 +5,12 @@ import (
 	"gorm.io/gorm"
 )
 
+// ชนิดของคาบเรียน (หนึ่งแถวของ Schedule คือหนึ่งช่วงคาบต่อเนื่อง ไม่ใช่รายชั่วโมง)
+const (
+	SessionKindLecture = "lecture"
+	SessionKindLab     = "lab"
+)
+
 type Schedule struct {
 	gorm.Model
 
@@ -13,10 +19,17 @@ type Schedule struct {
 	DayOfWeek     string    `valid:"required~DayOfWeek is required."`
 	StartTime     time.Time `valid:"required~StartTime is required."`
 	EndTime       time.Time `valid:"required~EndTime is required."`
+	Kind          string    `valid:"in(lecture|lab)~Kind is invalid."`
 
 	OfferedCoursesID uint           `valid:"required~OfferedCoursesID is required."`
 	OfferedCourses OfferedCourses `gorm:"foreignKey:OfferedCoursesID" valid:"-"`
 
+	SectionID *uint
+	Section   Section `gorm:"foreignKey:SectionID" valid:"-"`
+
+	RoomID *uint
+	Room   Room `gorm:"foreignKey:RoomID" valid:"-"`
+
 	TimeFixedCourses          []TimeFixedCourses          `gorm:"foreignKey:ScheduleID"`
 	ScheduleTeachingAssistant []ScheduleTeachingAssistant `gorm:"foreignKey:ScheduleID"`
 }
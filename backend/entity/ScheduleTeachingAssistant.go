@@ -7,9 +7,13 @@ import (
 type ScheduleTeachingAssistant struct {
 	gorm.Model
 
-	TeachingAssistantID uint
-	TeachingAssistant   TeachingAssistant `gorm:"foreignKey:TeachingAssistantID"`
+	TeachingAssistantID uint              `valid:"required~TeachingAssistantID is required." gorm:"uniqueIndex:idx_section_ta,where:deleted_at IS NULL"`
+	TeachingAssistant   TeachingAssistant `gorm:"foreignKey:TeachingAssistantID" valid:"-"`
 
-	ScheduleID uint
-	Schedule   Schedule `gorm:"foreignKey:ScheduleID"`
+	// ผู้ช่วยสอนผูกกับกลุ่มเรียน ScheduleID เป็นข้อมูลเดิมที่ผูกรายคาบ (ย้ายไป SectionID ตอน migrate)
+	ScheduleID *uint
+	Schedule   Schedule `gorm:"foreignKey:ScheduleID" valid:"-"`
+
+	SectionID *uint   `valid:"required~SectionID is required." gorm:"uniqueIndex:idx_section_ta,where:deleted_at IS NULL"`
+	Section   Section `gorm:"foreignKey:SectionID" valid:"-"`
 }
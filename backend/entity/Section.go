@@ -0,0 +1,25 @@
+package entity
+
+import (
+	"gorm.io/gorm"
+)
+
+// Section กลุ่มเรียนของรายวิชาที่เปิดสอน มีที่นั่ง ห้อง ผู้สอน และผู้ช่วยสอนของตัวเอง
+type Section struct {
+	gorm.Model
+
+	SectionNumber uint `valid:"required~SectionNumber is required." gorm:"uniqueIndex:idx_section_offered_number"`
+	Capacity      uint `valid:"required~Capacity is required."`
+	Enrolled      uint
+
+	OfferedCoursesID uint           `valid:"required~OfferedCoursesID is required." gorm:"uniqueIndex:idx_section_offered_number"`
+	OfferedCourses   OfferedCourses `gorm:"foreignKey:OfferedCoursesID" valid:"-"`
+
+	RoomID *uint
+	Room   Room `gorm:"foreignKey:RoomID" valid:"-"`
+
+	Schedules          []Schedule                  `gorm:"foreignKey:SectionID"`
+	Instructors        []SectionInstructor         `gorm:"foreignKey:SectionID"`
+	TeachingAssistants []ScheduleTeachingAssistant `gorm:"foreignKey:SectionID"`
+	StudentGroups      []SectionStudentGroup       `gorm:"foreignKey:SectionID"`
+}
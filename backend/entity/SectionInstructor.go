@@ -0,0 +1,23 @@
+package entity
+
+import (
+	"gorm.io/gorm"
+)
+
+// SectionInstructor ผู้สอนของแต่ละกลุ่มเรียน (รองรับหลายกลุ่ม/สอนร่วม) พร้อมการแบ่งชั่วโมงสอนต่อสัปดาห์
+type SectionInstructor struct {
+	gorm.Model
+
+	SectionNumber uint  `valid:"required~SectionNumber is required."`
+	LectureHours  *uint // ชั่วโมงบรรยายที่รับผิดชอบ (nil = ทั้งหมดของกลุ่ม)
+	LabHours      *uint // ชั่วโมงปฏิบัติที่รับผิดชอบ (nil = ทั้งหมดของกลุ่ม)
+
+	OfferedCoursesID uint           `valid:"required~OfferedCoursesID is required."`
+	OfferedCourses   OfferedCourses `gorm:"foreignKey:OfferedCoursesID" valid:"-"`
+
+	SectionID *uint
+	Section   Section `gorm:"foreignKey:SectionID" valid:"-"`
+
+	UserID uint `valid:"required~UserID is required."`
+	User   User `gorm:"foreignKey:UserID" valid:"-"`
+}
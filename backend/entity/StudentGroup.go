@@ -0,0 +1,30 @@
+package entity
+
+import "gorm.io/gorm"
+
+// StudentGroup กลุ่มนักศึกษาที่เรียนด้วยกัน (เช่น CPE รหัส 67 กลุ่ม 1 หรือกลุ่มเรียนซ้ำ)
+// กลุ่มเดียวห้ามมีคาบเรียนทับกัน
+type StudentGroup struct {
+	gorm.Model
+
+	Name      string `valid:"required~Name is required." gorm:"uniqueIndex"`
+	EntryYear uint   `valid:"required~EntryYear is required."` // ปีที่เข้าศึกษา (พ.ศ.)
+	Size      uint   `valid:"required~Size is required."`
+
+	MajorID      uint  `valid:"required~MajorID is required."`
+	Major        Major `gorm:"foreignKey:MajorID" valid:"-"`
+	CurriculumID *uint
+	Curriculum   Curriculum `gorm:"foreignKey:CurriculumID" valid:"-"`
+
+	Sections []SectionStudentGroup `gorm:"foreignKey:StudentGroupID"`
+}
+
+// SectionStudentGroup กลุ่มนักศึกษาที่เข้าเรียนในกลุ่มเรียน (หลายกลุ่มนักศึกษาเรียนกลุ่มเรียนเดียวกันได้)
+type SectionStudentGroup struct {
+	gorm.Model
+
+	SectionID      uint         `valid:"required~SectionID is required." gorm:"uniqueIndex:idx_section_student_group"`
+	Section        Section      `gorm:"foreignKey:SectionID" valid:"-"`
+	StudentGroupID uint         `valid:"required~StudentGroupID is required." gorm:"uniqueIndex:idx_section_student_group"`
+	StudentGroup   StudentGroup `gorm:"foreignKey:StudentGroupID" valid:"-"`
+}
@@ -0,0 +1,22 @@
+package entity
+
+import "gorm.io/gorm"
+
+// StudyPlanEntry หนึ่งช่องของแผนการศึกษาในหลักสูตร (ชั้นปี x ภาคการศึกษา)
+// เป็นวิชาบังคับ (AllCoursesID) หรือช่องวิชาเลือก (ElectiveSlot ถ้าระบุ TypeOfCoursesID เลือกได้เฉพาะประเภทนั้น)
+type StudyPlanEntry struct {
+	gorm.Model
+
+	Term         uint   `valid:"required~Term is required.,range(1|3)~Term must be between 1 and 3."`
+	ElectiveSlot string // ชื่อช่องวิชาเลือก เช่น "วิชาเลือกเฉพาะสาขา 1"
+	Credits      uint   // หน่วยกิตของช่องวิชาเลือก
+
+	CurriculumID    uint         `valid:"required~CurriculumID is required."`
+	Curriculum      Curriculum   `gorm:"foreignKey:CurriculumID" valid:"-"`
+	AcademicYearID  uint         `valid:"required~AcademicYearID is required."`
+	AcademicYear    AcademicYear `gorm:"foreignKey:AcademicYearID" valid:"-"`
+	AllCoursesID    *uint
+	AllCourses      AllCourses `gorm:"foreignKey:AllCoursesID" valid:"-"`
+	TypeOfCoursesID *uint
+	TypeOfCourses   TypeOfCourses `gorm:"foreignKey:TypeOfCoursesID" valid:"-"`
+}
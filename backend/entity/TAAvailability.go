@@ -0,0 +1,20 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TAAvailability ช่วงเวลาที่ผู้ช่วยสอนว่าง ถ้าไม่มีสักช่วงถือว่าว่างทุกเวลา
+// เวลาเก็บบนวันที่ 2000-01-01 เหมือน Condition
+type TAAvailability struct {
+	gorm.Model
+
+	DayOfWeek string    `valid:"required~DayOfWeek is required."`
+	StartTime time.Time `valid:"required~StartTime is required."`
+	EndTime   time.Time `valid:"required~EndTime is required."`
+
+	TeachingAssistantID uint              `valid:"required~TeachingAssistantID is required."`
+	TeachingAssistant   TeachingAssistant `gorm:"foreignKey:TeachingAssistantID" valid:"-"`
+}
@@ -0,0 +1,17 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TermCalendar วันเปิด-ปิดภาคเรียน ใช้แปลงตารางรายสัปดาห์เป็นวันที่จริง (ICS, รายงานวันที่ผู้สอนไม่ว่าง)
+type TermCalendar struct {
+	gorm.Model
+
+	Year      uint      `valid:"required~Year is required." gorm:"uniqueIndex:idx_term_calendar"`
+	Term      uint      `valid:"required~Term is required." gorm:"uniqueIndex:idx_term_calendar"`
+	StartDate time.Time `valid:"required~StartDate is required." gorm:"type:date"`
+	EndDate   time.Time `valid:"required~EndDate is required." gorm:"type:date"`
+}
@@ -22,4 +22,7 @@ type TimeFixedCourses struct {
 	AllCourses AllCourses `gorm:"foreignKey:AllCoursesID" valid:"-"`
 	ScheduleID uint     `valid:"required~ScheduleID is required."`
 	Schedule   Schedule   `gorm:"foreignKey:ScheduleID" valid:"-"`
+
+	RoomID *uint
+	Room   Room `gorm:"foreignKey:RoomID" valid:"-"` // ห้องในระบบที่ตรงกับ RoomFix (ถ้าจับคู่ได้)
 }
@@ -0,0 +1,36 @@
+package entity
+
+import (
+	"gorm.io/gorm"
+)
+
+// TimeGrid ตารางเวลาที่ภาควิชาใช้จัดตารางสอน เวลาเก็บเป็นข้อความ "HH:MM"
+type TimeGrid struct {
+	gorm.Model
+
+	WorkingDays    string `valid:"required~WorkingDays is required."` // ชื่อวันคั่นด้วยจุลภาค เช่น "จันทร์,อังคาร,พุธ,พฤหัสบดี,ศุกร์"
+	DayStart       string `valid:"required~DayStart is required.,matches(^([01][0-9]|2[0-3]):[0-5][0-9]$)~DayStart is invalid."`
+	DayEnd         string `valid:"required~DayEnd is required.,matches(^([01][0-9]|2[0-4]):[0-5][0-9]$)~DayEnd is invalid."`
+	SlotMinutes    uint   `valid:"required~SlotMinutes is required.,in(15|30|60)~SlotMinutes is invalid."`
+	PreferredStart string `valid:"required~PreferredStart is required.,matches(^([01][0-9]|2[0-3]):[0-5][0-9]$)~PreferredStart is invalid."`
+	PreferredEnd   string `valid:"required~PreferredEnd is required.,matches(^([01][0-9]|2[0-4]):[0-5][0-9]$)~PreferredEnd is invalid."`
+	FallbackStart  string `valid:"matches(^([01][0-9]|2[0-3]):[0-5][0-9]$)~FallbackStart is invalid."`
+	FallbackEnd    string `valid:"matches(^([01][0-9]|2[0-4]):[0-5][0-9]$)~FallbackEnd is invalid."`
+
+	DepartmentID uint       `valid:"required~DepartmentID is required." gorm:"uniqueIndex"`
+	Department   Department `gorm:"foreignKey:DepartmentID" valid:"-"`
+
+	BlockedPeriods []TimeGridBlock `gorm:"foreignKey:TimeGridID"`
+}
+
+// TimeGridBlock ช่วงเวลาที่ห้ามจัดคาบ เช่น พักกลางวัน ถ้าไม่ระบุวันจะใช้กับทุกวัน
+type TimeGridBlock struct {
+	gorm.Model
+
+	DayOfWeek string
+	StartTime string `valid:"required~StartTime is required.,matches(^([01][0-9]|2[0-3]):[0-5][0-9]$)~StartTime is invalid."`
+	EndTime   string `valid:"required~EndTime is required.,matches(^([01][0-9]|2[0-4]):[0-5][0-9]$)~EndTime is invalid."`
+	Label     string
+
+	TimeGridID uint
+}
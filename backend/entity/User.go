@@ -6,11 +6,11 @@ type User struct {
 	gorm.Model
 	Username      string `gorm:"unique" valid:"required~Username is required.,matches(^[A-Za-z]+\\.[A-Za-z]$)~Username must start with letters and dot and end with one letter."`
 	Password      string `valid:"required~Password is required.,minstringlength(8)~Password must be at least 8 characters."`
-	Firstname     string `valid:"required~Firstname is required.,alpha~Firstname must contain only letters."`
-	Lastname      string `valid:"required~Lastname is required.,alpha~Lastname must contain only letters."`
+	Firstname     string `valid:"required~Firstname is required.,name~Firstname must contain only letters."`
+	Lastname      string `valid:"required~Lastname is required.,name~Lastname must contain only letters."`
 	Image         string `valid:"optional"`
 	Email         string `gorm:"unique" valid:"required~Email is required.,email~Invalid email format."`
-	PhoneNumber   string `valid:"required~Phone number is required.,matches(^[0-9]{10}$)~Phone number must be 10 digits."`
+	PhoneNumber   string `valid:"required~Phone number is required.,matches(^0[0-9]{8}[0-9]?$)~Phone number must be 9-10 digits starting with 0."`
 	Address       string `valid:"required~Address is required."`
 	FirstPassword bool
 
@@ -29,4 +29,6 @@ type User struct {
 	Conditions     []Condition      `gorm:"foreignKey:UserID"`
 	OfferedCourses []OfferedCourses `gorm:"foreignKey:UserID"`
 	UserAllCourses []UserAllCourses `gorm:"foreignKey:UserID"`
+
+	SectionInstructors []SectionInstructor `gorm:"foreignKey:UserID"`
 }
@@ -0,0 +1,29 @@
+package entity
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/asaskevich/govalidator"
+)
+
+// แท็ก alpha ของ govalidator รับเฉพาะ A-Z ทำให้ชื่อภาษาไทย (มีสระ/วรรณยุกต์) และชื่อวิชาที่มีช่องว่างไม่ผ่าน
+// name รับตัวอักษรทุกภาษา เครื่องหมายกำกับ ช่องว่าง และเครื่องหมายวรรคตอนที่พบในชื่อ แต่ไม่รับตัวเลข
+func init() {
+	govalidator.TagMap["name"] = govalidator.Validator(IsName)
+}
+
+func IsName(s string) bool {
+	if strings.TrimSpace(s) == "" {
+		return false
+	}
+	for _, r := range s {
+		switch {
+		case unicode.IsLetter(r), unicode.IsMark(r), r == ' ':
+		case strings.ContainsRune(".,'-&()/", r):
+		default:
+			return false
+		}
+	}
+	return true
+}
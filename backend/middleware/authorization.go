@@ -23,7 +23,7 @@ func Authorizes() gin.HandlerFunc {
 		clientToken := c.Request.Header.Get("Authorization")
 		if clientToken == "" {
 
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "No Authorization header provided"})
+			abortUnauthorized(c, "ไม่พบ Authorization header")
 			return
 
 		}
@@ -35,7 +35,7 @@ func Authorizes() gin.HandlerFunc {
 
 		} else {
 
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Incorrect Format of Authorization Token"})
+			abortUnauthorized(c, "รูปแบบ Authorization token ไม่ถูกต้อง")
 			return
 
 		}
@@ -50,10 +50,16 @@ func Authorizes() gin.HandlerFunc {
 
 		if err != nil {
 
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			abortUnauthorized(c, "token ไม่ถูกต้องหรือหมดอายุ")
 			return
 
 		}
 		c.Next()
 	}
 }
+
+// abortUnauthorized ตอบ 401 ในรูปแบบข้อผิดพลาดกลางเดียวกับ controllers
+func abortUnauthorized(c *gin.Context, message string) {
+	e := services.APIError{Status: http.StatusUnauthorized, Message: message}
+	c.AbortWithStatusJSON(e.Status, e.Body(services.PreferredLanguage(c.GetHeader("Accept-Language"))))
+}
@@ -0,0 +1,224 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/asaskevich/govalidator"
+)
+
+const (
+	LangTH = "th"
+	LangEN = "en"
+)
+
+// รหัสข้อผิดพลาดที่หน้าเว็บใช้ตัดสินใจได้โดยไม่ต้องอ่านข้อความ
+const (
+	CodeBadRequest   = "BAD_REQUEST"
+	CodeValidation   = "VALIDATION_FAILED"
+	CodeUnauthorized = "UNAUTHORIZED"
+	CodeForbidden    = "FORBIDDEN"
+	CodeNotFound     = "NOT_FOUND"
+	CodeConflict     = "CONFLICT"
+	CodeInternal     = "INTERNAL_ERROR"
+)
+
+var codeMessages = map[string][2]string{
+	CodeBadRequest:   {"ข้อมูลไม่ถูกต้อง", "Invalid request."},
+	CodeValidation:   {"ข้อมูลไม่ผ่านการตรวจสอบ", "Validation failed."},
+	CodeUnauthorized: {"กรุณาเข้าสู่ระบบ", "Authentication required."},
+	CodeForbidden:    {"ไม่มีสิทธิ์เข้าถึง", "Permission denied."},
+	CodeNotFound:     {"ไม่พบข้อมูล", "Resource not found."},
+	CodeConflict:     {"ข้อมูลขัดแย้งกับข้อมูลที่มีอยู่", "The request conflicts with existing data."},
+	CodeInternal:     {"เกิดข้อผิดพลาดภายในระบบ", "Internal server error."},
+}
+
+// FieldError ข้อผิดพลาดของฟิลด์เดียว (Rule คือชื่อ validator เช่น required, email)
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// APIError รูปแบบข้อผิดพลาดกลางของทุก endpoint
+// Message เป็นข้อความเฉพาะของ handler (ภาษาไทย) ว่างได้ถ้าใช้ข้อความมาตรฐานของ Code
+type APIError struct {
+	Status  int
+	Code    string
+	Message string
+	Fields  []FieldError
+}
+
+// CodeForStatus รหัสข้อผิดพลาดมาตรฐานของ HTTP status
+func CodeForStatus(status int) string {
+	switch status {
+	case http.StatusUnauthorized:
+		return CodeUnauthorized
+	case http.StatusForbidden:
+		return CodeForbidden
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusConflict:
+		return CodeConflict
+	case http.StatusUnprocessableEntity:
+		return CodeValidation
+	}
+	if status >= http.StatusInternalServerError {
+		return CodeInternal
+	}
+	return CodeBadRequest
+}
+
+// PreferredLanguage เลือก th หรือ en จาก Accept-Language ตามค่า q (ไม่ระบุหรือไม่รู้จักใช้ภาษาไทย)
+func PreferredLanguage(acceptLanguage string) string {
+	lang, best := LangTH, -1.0
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, q := part, 1.0
+		if i := strings.Index(part, ";"); i >= 0 {
+			tag = part[:i]
+			if v := strings.TrimSpace(part[i+1:]); strings.HasPrefix(v, "q=") {
+				f, err := strconv.ParseFloat(v[2:], 64)
+				if err != nil {
+					continue
+				}
+				q = f
+			}
+		}
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if i := strings.Index(tag, "-"); i >= 0 {
+			tag = tag[:i]
+		}
+		if (tag == LangTH || tag == LangEN) && q > best {
+			lang, best = tag, q
+		}
+	}
+	return lang
+}
+
+func hasThai(s string) bool {
+	for _, r := range s {
+		if unicode.Is(unicode.Thai, r) {
+			return true
+		}
+	}
+	return false
+}
+
+// LocalizedMessage ข้อความตามภาษา ใช้ข้อความของ handler เมื่อเป็นภาษาเดียวกับที่ขอ ไม่เช่นนั้นใช้ข้อความมาตรฐานของรหัส
+func LocalizedMessage(lang, code, message string) string {
+	if message != "" && hasThai(message) == (lang == LangTH) {
+		return message
+	}
+	msgs, ok := codeMessages[code]
+	if !ok {
+		msgs = codeMessages[CodeBadRequest]
+	}
+	if lang == LangEN {
+		return msgs[1]
+	}
+	return msgs[0]
+}
+
+// Body เนื้อหา JSON ของข้อผิดพลาด "error" เป็นข้อความตามภาษา (คงชื่อเดิมไว้ให้หน้าเว็บเดิมอ่านได้)
+func (e APIError) Body(lang string) map[string]interface{} {
+	code := e.Code
+	if code == "" {
+		code = CodeForStatus(e.Status)
+	}
+	fields := make([]FieldError, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		f.Message = fieldMessage(lang, f)
+		fields = append(fields, f)
+	}
+	return map[string]interface{}{
+		"code":   code,
+		"error":  LocalizedMessage(lang, code, e.Message),
+		"fields": fields,
+	}
+}
+
+// fieldMessage ข้อความของแท็ก valid เป็นภาษาอังกฤษอยู่แล้ว ภาษาไทยสร้างจากชนิดของ validator
+func fieldMessage(lang string, f FieldError) string {
+	if lang == LangEN && f.Message != "" {
+		return f.Message
+	}
+	switch {
+	case lang == LangEN:
+		return fmt.Sprintf("%s is invalid.", f.Field)
+	case f.Rule == "required":
+		return fmt.Sprintf("กรุณาระบุ %s", f.Field)
+	default:
+		return fmt.Sprintf("%s ไม่ถูกต้อง", f.Field)
+	}
+}
+
+// ValidationErrors แปลงผลของ govalidator.ValidateStruct เป็นรายการฟิลด์
+// ข้อผิดพลาดของ struct ที่ซ้อนอยู่ (ความสัมพันธ์ที่โหลดมาด้วย) ไม่นับ เพราะแต่ละ entity ตรวจของตัวเองตอนบันทึก
+func ValidationErrors(err error) []FieldError {
+	out := []FieldError{}
+	var walk func(error)
+	walk = func(err error) {
+		switch e := err.(type) {
+		case govalidator.Errors:
+			for _, inner := range e {
+				walk(inner)
+			}
+		case govalidator.Error:
+			if len(e.Path) > 0 {
+				return
+			}
+			out = append(out, FieldError{Field: e.Name, Rule: e.Validator, Message: e.Error()})
+		case nil:
+		default:
+			out = append(out, FieldError{Message: e.Error()})
+		}
+	}
+	walk(err)
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Field < out[j].Field })
+	return out
+}
+
+// ValidateEntity ตรวจแท็ก valid ของ entity คืนรายการฟิลด์ที่ไม่ผ่าน (ว่าง = ผ่าน)
+func ValidateEntity(v interface{}) []FieldError {
+	if _, err := govalidator.ValidateStruct(v); err != nil {
+		return ValidationErrors(err)
+	}
+	return nil
+}
+
+// bindFieldError ข้อผิดพลาดรายฟิลด์ของแท็ก binding ที่ gin ตรวจตอน ShouldBindJSON
+type bindFieldError interface {
+	Field() string
+	Tag() string
+}
+
+// BindErrors แปลงข้อผิดพลาดของ ShouldBindJSON เป็นรายการฟิลด์ คืนรายการว่างถ้าบอกฟิลด์ไม่ได้ (เช่น JSON ผิดรูปแบบ)
+// ไม่ใช้ข้อความดิบของ decoder/validator เพราะมีชื่อชนิดและโครงสร้างภายในของ Go
+func BindErrors(err error) []FieldError {
+	out := []FieldError{}
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		field := typeErr.Field
+		if field == "" {
+			field = typeErr.Value
+		}
+		return append(out, FieldError{Field: field, Rule: "type"})
+	}
+	rv := reflect.ValueOf(err)
+	if rv.Kind() != reflect.Slice {
+		return out
+	}
+	for i := 0; i < rv.Len(); i++ {
+		if fe, ok := rv.Index(i).Interface().(bindFieldError); ok {
+			out = append(out, FieldError{Field: fe.Field(), Rule: fe.Tag()})
+		}
+	}
+	return out
+}
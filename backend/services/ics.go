@@ -0,0 +1,31 @@
+package services
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// icsLineOctets ความยาวสูงสุดของหนึ่งบรรทัดใน iCalendar (ไม่รวม CRLF) ตาม RFC 5545 3.1
+const icsLineOctets = 75
+
+// FoldICSLine พับบรรทัดที่ยาวเกิน 75 ไบต์เป็นหลายบรรทัด (CRLF ตามด้วยช่องว่าง) โดยไม่ตัดกลางตัวอักษร UTF-8
+// บรรทัดต่อมีช่องว่างนำหน้าซึ่งนับรวมใน 75 ไบต์ด้วย คืนค่าโดยไม่มี CRLF ท้ายบรรทัด
+func FoldICSLine(line string) string {
+	if len(line) <= icsLineOctets {
+		return line
+	}
+	var b strings.Builder
+	limit := icsLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		limit = icsLineOctets - 1
+	}
+	b.WriteString(line)
+	return b.String()
+}
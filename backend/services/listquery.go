@@ -0,0 +1,150 @@
+package services
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 200
+)
+
+// ชนิดค่าของตัวกรอง
+const (
+	FilterUint   = "uint"   // ตัวเลขจำนวนเต็มบวก เช่น major_id
+	FilterString = "string" // ข้อความตรงทั้งคำ เช่น major_name
+	FilterSearch = "search" // ค้นหาบางส่วน (ILIKE %ค่า%)
+)
+
+type (
+	// ListFilter ตัวกรองหนึ่งตัว Where เป็นเงื่อนไข SQL ที่ทุก ? ใช้ค่าเดียวกัน
+	ListFilter struct {
+		Kind  string
+		Where string
+	}
+
+	// ListSpec ฟิลด์ที่เรียงลำดับได้ (ชื่อใน query -> คอลัมน์ SQL) และตัวกรองที่รองรับของแต่ละ endpoint
+	ListSpec struct {
+		Sorts       map[string]string
+		Filters     map[string]ListFilter
+		DefaultSort string
+	}
+
+	SortField struct {
+		Column string
+		Desc   bool
+	}
+
+	// ListQuery พารามิเตอร์ ?page=&size=&sort=code,-credit&<ตัวกรอง>= ที่ตรวจแล้ว
+	ListQuery struct {
+		Paged   bool // ระบุ page มา (ตอบแบบ envelope)
+		Page    int
+		Size    int
+		Sort    []SortField
+		filters []appliedFilter
+	}
+
+	appliedFilter struct {
+		where string
+		value interface{}
+	}
+)
+
+// ParseListQuery อ่าน page/size/sort และตัวกรองตาม spec ค่าที่ไม่ถูกต้องคืน error (ข้อความภาษาไทย)
+func ParseListQuery(values url.Values, spec ListSpec) (ListQuery, error) {
+	q := ListQuery{Page: 1, Size: DefaultPageSize}
+
+	if v := values.Get("page"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return q, fmt.Errorf("page ต้องเป็นตัวเลขตั้งแต่ 1")
+		}
+		q.Paged, q.Page = true, n
+	}
+	if v := values.Get("size"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > MaxPageSize {
+			return q, fmt.Errorf("size ต้องอยู่ระหว่าง 1 ถึง %d", MaxPageSize)
+		}
+		q.Size = n
+	}
+
+	sort := values.Get("sort")
+	if sort == "" {
+		sort = spec.DefaultSort
+	}
+	for _, key := range strings.Split(sort, ",") {
+		key = strings.TrimSpace(key)
+		if key == "" {
+			continue
+		}
+		desc := strings.HasPrefix(key, "-")
+		column, ok := spec.Sorts[strings.TrimPrefix(key, "-")]
+		if !ok {
+			return q, fmt.Errorf("เรียงลำดับด้วย %s ไม่ได้", strings.TrimPrefix(key, "-"))
+		}
+		q.Sort = append(q.Sort, SortField{Column: column, Desc: desc})
+	}
+
+	for name, f := range spec.Filters {
+		raw := strings.TrimSpace(values.Get(name))
+		if raw == "" {
+			continue
+		}
+		var value interface{}
+		switch f.Kind {
+		case FilterUint:
+			n, err := strconv.ParseUint(raw, 10, 64)
+			if err != nil || n == 0 {
+				return q, fmt.Errorf("%s ต้องเป็นตัวเลข", name)
+			}
+			value = uint(n)
+		case FilterSearch:
+			value = "%" + EscapeLike(NormalizeSearchQuery(raw)) + "%"
+		default:
+			value = raw
+		}
+		q.filters = append(q.filters, appliedFilter{where: f.Where, value: value})
+	}
+	return q, nil
+}
+
+// Offset ลำดับแถวแรกของหน้าปัจจุบัน (ใช้ทำเลขลำดับต่อเนื่องข้ามหน้า)
+func (q ListQuery) Offset() int {
+	if !q.Paged {
+		return 0
+	}
+	return (q.Page - 1) * q.Size
+}
+
+// Filter ใส่เงื่อนไขตัวกรองลงใน query (ใช้ทั้งตอนนับจำนวนและตอนดึงข้อมูล)
+func (q ListQuery) Filter(db *gorm.DB) *gorm.DB {
+	for _, f := range q.filters {
+		args := make([]interface{}, strings.Count(f.where, "?"))
+		for i := range args {
+			args[i] = f.value
+		}
+		db = db.Where(f.where, args...)
+	}
+	return db
+}
+
+// Paginate เรียงลำดับและตัดหน้า (ไม่ระบุ page จะคืนทุกแถวแบบเดิม)
+func (q ListQuery) Paginate(db *gorm.DB) *gorm.DB {
+	for _, s := range q.Sort {
+		if s.Desc {
+			db = db.Order(s.Column + " DESC")
+		} else {
+			db = db.Order(s.Column)
+		}
+	}
+	if q.Paged {
+		db = db.Offset(q.Offset()).Limit(q.Size)
+	}
+	return db
+}
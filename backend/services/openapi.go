@@ -0,0 +1,220 @@
+package services
+
+import (
+	"sort"
+	"strings"
+)
+
+const APIVersionPrefix = "/api/v1"
+
+// APIRoute เส้นทางหนึ่งของ API เวอร์ชัน 1 ใช้ทั้งลงทะเบียน handler และสร้างเอกสาร OpenAPI
+type APIRoute struct {
+	Method      string
+	Path        string   // path แบบ gin ใต้ /api/v1 เช่น /courses/:id
+	Legacy      []string // เส้นทางเดิม (ก่อนมี /api/v1) ที่ยังชี้มาที่ handler เดียวกัน
+	Tag         string
+	Summary     string
+	OperationID string    // ชื่อ handler
+	Query       []string  // query parameter ที่ handler อ่าน
+	List        *ListSpec // endpoint รายการที่รองรับ page/size/sort และตัวกรอง
+	Body        bool      // รับ JSON body
+}
+
+// OpenAPIPath แปลง :id ของ gin เป็น {id}
+func OpenAPIPath(path string) string {
+	parts := strings.Split(path, "/")
+	for i, p := range parts {
+		if strings.HasPrefix(p, ":") || strings.HasPrefix(p, "*") {
+			parts[i] = "{" + p[1:] + "}"
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+// PathParams ชื่อ path parameter ตามลำดับใน path
+func PathParams(path string) []string {
+	out := []string{}
+	for _, p := range strings.Split(path, "/") {
+		if strings.HasPrefix(p, ":") || strings.HasPrefix(p, "*") {
+			out = append(out, p[1:])
+		}
+	}
+	return out
+}
+
+var integerParams = map[string]bool{
+	"year": true, "term": true, "limit": true, "capacity": true,
+	"min_capacity": true, "max_hours": true, "min_hours": true,
+	"page": true, "size": true,
+}
+
+func paramSchema(name string) map[string]interface{} {
+	if name == "cascade" {
+		return map[string]interface{}{"type": "boolean", "default": false}
+	}
+	if integerParams[name] || name == "id" || strings.HasSuffix(name, "_id") || strings.HasSuffix(name, "ID") {
+		return map[string]interface{}{"type": "integer", "minimum": 0}
+	}
+	return map[string]interface{}{"type": "string"}
+}
+
+func queryParams(rt APIRoute) []map[string]interface{} {
+	names := append([]string{}, rt.Query...)
+	schemas := map[string]map[string]interface{}{}
+	if rt.List != nil {
+		names = append(names, "page", "size", "sort")
+		filters := make([]string, 0, len(rt.List.Filters))
+		for name, f := range rt.List.Filters {
+			filters = append(filters, name)
+			if f.Kind == FilterUint {
+				schemas[name] = map[string]interface{}{"type": "integer", "minimum": 1}
+			} else {
+				schemas[name] = map[string]interface{}{"type": "string"}
+			}
+		}
+		sort.Strings(filters)
+		names = append(names, filters...)
+		schemas["page"] = map[string]interface{}{"type": "integer", "minimum": 1, "default": 1}
+		schemas["size"] = map[string]interface{}{"type": "integer", "minimum": 1, "maximum": MaxPageSize, "default": DefaultPageSize}
+		schemas["sort"] = map[string]interface{}{"type": "string", "example": rt.List.DefaultSort}
+	}
+
+	out := []map[string]interface{}{}
+	seen := map[string]bool{}
+	for _, name := range names {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		schema, ok := schemas[name]
+		if !ok {
+			schema = paramSchema(name)
+		}
+		out = append(out, map[string]interface{}{"name": name, "in": "query", "schema": schema})
+	}
+	return out
+}
+
+func errorResponse(description string) map[string]interface{} {
+	return map[string]interface{}{
+		"description": description,
+		"content": map[string]interface{}{
+			"application/json": map[string]interface{}{"schema": map[string]interface{}{"$ref": "#/components/schemas/Error"}},
+		},
+	}
+}
+
+// OpenAPIDocument เอกสาร OpenAPI 3 ของเส้นทางทั้งหมด (สร้างจากตารางเส้นทางเดียวกับที่ลงทะเบียน handler)
+func OpenAPIDocument(routes []APIRoute) map[string]interface{} {
+	paths := map[string]map[string]interface{}{}
+	tagSet := map[string]bool{}
+	for _, rt := range routes {
+		params := []interface{}{map[string]interface{}{"$ref": "#/components/parameters/AcceptLanguage"}}
+		for _, name := range PathParams(rt.Path) {
+			params = append(params, map[string]interface{}{"name": name, "in": "path", "required": true, "schema": paramSchema(name)})
+		}
+		for _, p := range queryParams(rt) {
+			params = append(params, p)
+		}
+
+		op := map[string]interface{}{
+			"tags":        []string{rt.Tag},
+			"summary":     rt.Summary,
+			"operationId": rt.OperationID,
+			"parameters":  params,
+			"responses": map[string]interface{}{
+				"2XX": map[string]interface{}{"description": "สำเร็จ"},
+				"4XX": errorResponse("ข้อมูลไม่ถูกต้อง ไม่พบ หรือขัดแย้งกับข้อมูลที่มีอยู่"),
+				"5XX": errorResponse("ข้อผิดพลาดภายในระบบ"),
+			},
+		}
+		if rt.Body {
+			op["requestBody"] = map[string]interface{}{
+				"required": true,
+				"content": map[string]interface{}{
+					"application/json": map[string]interface{}{"schema": map[string]interface{}{"type": "object"}},
+				},
+			}
+		}
+		if len(rt.Legacy) > 0 {
+			op["x-legacy-paths"] = rt.Legacy
+		}
+
+		path := OpenAPIPath(rt.Path)
+		if paths[path] == nil {
+			paths[path] = map[string]interface{}{}
+		}
+		paths[path][strings.ToLower(rt.Method)] = op
+		tagSet[rt.Tag] = true
+	}
+
+	tags := []map[string]interface{}{}
+	names := make([]string, 0, len(tagSet))
+	for name := range tagSet {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		tags = append(tags, map[string]interface{}{"name": name})
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":       "CPE Teaching Schedule API",
+			"version":     "1.0.0",
+			"description": "เส้นทางเดิม (x-legacy-paths) ยังใช้งานได้และชี้มาที่ handler เดียวกัน",
+		},
+		"servers": []map[string]interface{}{{"url": APIVersionPrefix}},
+		"tags":    tags,
+		"paths":   paths,
+		"components": map[string]interface{}{
+			"parameters": map[string]interface{}{
+				"AcceptLanguage": map[string]interface{}{
+					"name":        "Accept-Language",
+					"in":          "header",
+					"description": "ภาษาของข้อความผิดพลาด (th หรือ en)",
+					"schema":      map[string]interface{}{"type": "string", "default": LangTH},
+				},
+			},
+			"schemas": map[string]interface{}{
+				"Error": map[string]interface{}{
+					"type":     "object",
+					"required": []string{"code", "error", "fields"},
+					"properties": map[string]interface{}{
+						"code": map[string]interface{}{
+							"type": "string",
+							"enum": []string{CodeBadRequest, CodeValidation, CodeUnauthorized, CodeForbidden, CodeNotFound, CodeConflict, CodeInternal},
+						},
+						"error":   map[string]interface{}{"type": "string"},
+						"details": map[string]interface{}{"type": "string"},
+						"dependents": map[string]interface{}{
+							"type":        "array",
+							"description": "ข้อมูลที่อ้างถึงอยู่ เมื่อการลบถูกปฏิเสธ (ส่ง cascade=true เพื่อลบไปด้วย)",
+							"items": map[string]interface{}{
+								"type": "object",
+								"properties": map[string]interface{}{
+									"kind":  map[string]interface{}{"type": "string"},
+									"label": map[string]interface{}{"type": "string"},
+									"count": map[string]interface{}{"type": "integer"},
+									"ids":   map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "integer"}},
+								},
+							},
+						},
+						"fields": map[string]interface{}{
+							"type": "array",
+							"items": map[string]interface{}{
+								"type": "object",
+								"properties": map[string]interface{}{
+									"field":   map[string]interface{}{"type": "string"},
+									"rule":    map[string]interface{}{"type": "string"},
+									"message": map[string]interface{}{"type": "string"},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
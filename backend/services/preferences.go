@@ -0,0 +1,226 @@
+package services
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/Nichakorn25/CPE-Teaching-Schedule/entity"
+)
+
+// PreferenceSatisfaction ผลการจัดตารางเทียบกับความต้องการของผู้สอนหนึ่งคน
+type PreferenceSatisfaction struct {
+	UserID           uint
+	Fullname         string
+	TeachingMinutes  int
+	PreferredMinutes int // นาทีที่ตรงกับช่วงที่อยากสอน
+	AvoidedMinutes   int // นาทีที่ตกอยู่ในช่วงที่ขอเลี่ยง
+	TeachingDays     int
+	MaxTeachingDays  uint
+	MaxDailyHours    float64
+	MaxHoursPerDay   uint
+	LimitsMet        bool
+	Score            float64 // 0-100
+}
+
+// TeacherPrefs ความต้องการแบบยืดหยุ่นของผู้สอนทุกคน ใช้เป็น soft constraint ในตัวจัดตาราง
+type TeacherPrefs struct {
+	prefs  map[uint][]entity.ConditionPreference
+	limits map[uint]entity.ConditionLimit
+}
+
+// Teaches ผู้สอน uid สอนคาบ s หรือไม่
+type Teaches func(uid uint, s entity.Schedule) bool
+
+func NewTeacherPrefs(prefs []entity.ConditionPreference, limits []entity.ConditionLimit) *TeacherPrefs {
+	p := &TeacherPrefs{
+		prefs:  make(map[uint][]entity.ConditionPreference),
+		limits: make(map[uint]entity.ConditionLimit),
+	}
+	for _, pr := range prefs {
+		p.prefs[pr.UserID] = append(p.prefs[pr.UserID], pr)
+	}
+	for _, l := range limits {
+		p.limits[l.UserID] = l
+	}
+	return p
+}
+
+// ClockMinutes คืนเวลาเป็นนาทีนับจากเที่ยงคืน (ตามเวลาไทย) โดยไม่สนใจวันที่
+// เพราะตารางแต่ละแหล่งบันทึกวันที่ต่างกัน (2006-01-02, 2000-01-01 หรือวันที่สร้าง)
+func ClockMinutes(t time.Time) int {
+	t = t.In(time.FixedZone("Asia/Bangkok", 7*60*60))
+	return t.Hour()*60 + t.Minute()
+}
+
+// OverlapMinutes จำนวนนาทีที่ช่วง [aStart, aEnd) กับ [bStart, bEnd) ทับกัน
+func OverlapMinutes(aStart, aEnd, bStart, bEnd int) int {
+	start, end := aStart, aEnd
+	if bStart > start {
+		start = bStart
+	}
+	if bEnd < end {
+		end = bEnd
+	}
+	if end <= start {
+		return 0
+	}
+	return end - start
+}
+
+// Score คะแนนความพอใจของผู้สอนทั้งหมดต่อช่วงเวลานี้ (ถ่วงน้ำหนักตามนาทีที่ทับ)
+func (p *TeacherPrefs) Score(teachers []uint, day string, start, end int) float64 {
+	total := 0.0
+	for _, uid := range teachers {
+		for _, pr := range p.prefs[uid] {
+			if pr.DayOfWeek != "" && pr.DayOfWeek != day {
+				continue
+			}
+			ov := OverlapMinutes(start, end, ClockMinutes(pr.StartTime), ClockMinutes(pr.EndTime))
+			total += float64(pr.Weight) * float64(ov) / 60
+		}
+	}
+	return total
+}
+
+// teacherDayMinutes นาทีสอนรายวันของผู้สอนจากตารางที่มีอยู่
+func teacherDayMinutes(uid uint, schedules []entity.Schedule, teaches Teaches) map[string]int {
+	days := make(map[string]int)
+	for _, s := range schedules {
+		if teaches(uid, s) {
+			days[s.DayOfWeek] += ClockMinutes(s.EndTime) - ClockMinutes(s.StartTime)
+		}
+	}
+	return days
+}
+
+// WithinLimits การเพิ่มคาบยาว minutes นาทีในวันนี้ยังไม่เกินจำนวนวันสอน/ชั่วโมงต่อวันที่ผู้สอนขอไว้
+func (p *TeacherPrefs) WithinLimits(teachers []uint, day string, minutes int, schedules []entity.Schedule, teaches Teaches) bool {
+	for _, uid := range teachers {
+		limit, ok := p.limits[uid]
+		if !ok || (limit.MaxTeachingDays == 0 && limit.MaxHoursPerDay == 0) {
+			continue
+		}
+		days := teacherDayMinutes(uid, schedules, teaches)
+		if limit.MaxTeachingDays > 0 {
+			if _, teaching := days[day]; !teaching && len(days) >= int(limit.MaxTeachingDays) {
+				return false
+			}
+		}
+		if limit.MaxHoursPerDay > 0 && days[day]+minutes > int(limit.MaxHoursPerDay)*60 {
+			return false
+		}
+	}
+	return true
+}
+
+// RankStarts เรียงเวลาเริ่มตามคะแนนความพอใจ (มาก -> น้อย) เวลาที่คะแนนเท่ากันคงลำดับสุ่มเดิม
+func (p *TeacherPrefs) RankStarts(starts []int, teachers []uint, day string, length int) {
+	sort.SliceStable(starts, func(i, j int) bool {
+		return p.Score(teachers, day, starts[i], starts[i]+length) > p.Score(teachers, day, starts[j], starts[j]+length)
+	})
+}
+
+// SatisfactionReport สรุปว่าตารางตรงกับความต้องการของผู้สอนแต่ละคนแค่ไหน (เฉพาะผู้ที่ตั้งค่าไว้)
+// เรียงจากคะแนนน้อยไปมาก ไม่เติม Fullname
+func (p *TeacherPrefs) SatisfactionReport(schedules []entity.Schedule, teaches Teaches) []PreferenceSatisfaction {
+	userIDs := map[uint]bool{}
+	for uid := range p.prefs {
+		userIDs[uid] = true
+	}
+	for uid := range p.limits {
+		userIDs[uid] = true
+	}
+
+	report := []PreferenceSatisfaction{}
+	for uid := range userIDs {
+		r := PreferenceSatisfaction{UserID: uid, LimitsMet: true}
+		hasPositive, hasNegative := false, false
+		for _, pr := range p.prefs[uid] {
+			hasPositive = hasPositive || pr.Weight > 0
+			hasNegative = hasNegative || pr.Weight < 0
+		}
+
+		days := make(map[string]int)
+		for _, s := range schedules {
+			if !teaches(uid, s) {
+				continue
+			}
+			start, end := ClockMinutes(s.StartTime), ClockMinutes(s.EndTime)
+			r.TeachingMinutes += end - start
+			days[s.DayOfWeek] += end - start
+
+			preferred, avoided := 0, 0
+			for _, pr := range p.prefs[uid] {
+				if pr.DayOfWeek != "" && pr.DayOfWeek != s.DayOfWeek {
+					continue
+				}
+				ov := OverlapMinutes(start, end, ClockMinutes(pr.StartTime), ClockMinutes(pr.EndTime))
+				if pr.Weight > 0 && ov > preferred {
+					preferred = ov
+				}
+				if pr.Weight < 0 && ov > avoided {
+					avoided = ov
+				}
+			}
+			r.PreferredMinutes += preferred
+			r.AvoidedMinutes += avoided
+		}
+
+		r.TeachingDays = len(days)
+		for _, m := range days {
+			if h := float64(m) / 60; h > r.MaxDailyHours {
+				r.MaxDailyHours = h
+			}
+		}
+
+		parts := []float64{}
+		if limit, ok := p.limits[uid]; ok {
+			r.MaxTeachingDays = limit.MaxTeachingDays
+			r.MaxHoursPerDay = limit.MaxHoursPerDay
+			if limit.MaxTeachingDays > 0 {
+				met := r.TeachingDays <= int(limit.MaxTeachingDays)
+				r.LimitsMet = r.LimitsMet && met
+				parts = append(parts, boolScore(met))
+			}
+			if limit.MaxHoursPerDay > 0 {
+				met := r.MaxDailyHours <= float64(limit.MaxHoursPerDay)
+				r.LimitsMet = r.LimitsMet && met
+				parts = append(parts, boolScore(met))
+			}
+		}
+		if r.TeachingMinutes > 0 {
+			if hasPositive {
+				parts = append(parts, float64(r.PreferredMinutes)/float64(r.TeachingMinutes))
+			}
+			if hasNegative {
+				parts = append(parts, 1-float64(r.AvoidedMinutes)/float64(r.TeachingMinutes))
+			}
+		}
+
+		r.Score = 100
+		if len(parts) > 0 {
+			sum := 0.0
+			for _, v := range parts {
+				sum += v
+			}
+			r.Score = math.Round(sum/float64(len(parts))*1000) / 10
+		}
+		report = append(report, r)
+	}
+
+	sort.Slice(report, func(i, j int) bool {
+		if report[i].Score != report[j].Score {
+			return report[i].Score < report[j].Score
+		}
+		return report[i].UserID < report[j].UserID
+	})
+	return report
+}
+
+func boolScore(ok bool) float64 {
+	if ok {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,37 @@
+package services
+
+import (
+	"strings"
+	"unicode"
+)
+
+const MaxSearchLength = 100
+
+// NormalizeSearchQuery ตัดอักขระความกว้างศูนย์ (พบบ่อยในข้อความภาษาไทยที่คัดลอกมา) และยุบช่องว่างซ้ำ
+func NormalizeSearchQuery(q string) string {
+	q = strings.Map(func(r rune) rune {
+		switch r {
+		case '\u200b', '\u200c', '\u200d', '\ufeff':
+			return -1
+		}
+		if unicode.IsSpace(r) {
+			return ' '
+		}
+		return r
+	}, q)
+	q = strings.Join(strings.Fields(q), " ")
+	if r := []rune(q); len(r) > MaxSearchLength {
+		q = strings.TrimSpace(string(r[:MaxSearchLength]))
+	}
+	return q
+}
+
+// CompactCode รหัสวิชาแบบไม่มีช่องว่าง ให้ "ENG231001" ค้นเจอ "ENG23 1001"
+func CompactCode(q string) string {
+	return strings.ReplaceAll(q, " ", "")
+}
+
+// EscapeLike ป้องกัน % และ _ ในคำค้นไม่ให้เป็น wildcard
+func EscapeLike(q string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(q)
+}
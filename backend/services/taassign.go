@@ -0,0 +1,63 @@
+package services
+
+import "sort"
+
+// TAPick ผู้ช่วยสอนหนึ่งคนที่ถูกจัดให้กลุ่มเรียนหนึ่งกลุ่ม
+type TAPick struct {
+	TeachingAssistantID uint
+	SectionID           uint
+}
+
+// AssignTAs กระจายผู้ช่วยสอนแบบ greedy: กลุ่มที่มีตัวเลือกน้อยที่สุดได้เลือกก่อน
+// และเลือกผู้ช่วยสอนที่ภาระ (load) น้อยที่สุด need คือจำนวนผู้ช่วยสอนที่ยังขาดของแต่ละกลุ่ม
+// assign ถูกเรียกทุกครั้งที่จัดได้ เพื่อให้ eligible และ load เห็นภาระล่าสุด
+// กลุ่มที่ไม่มีผู้ช่วยสอนที่เหมาะสมจะถูกข้ามไปและคืนใน unassigned โดยไม่หยุดการจัดกลุ่มอื่น
+func AssignTAs(need map[uint]int, pool []uint, eligible func(taID, sectionID uint) bool, load func(taID uint) int, assign func(taID, sectionID uint)) (picks []TAPick, unassigned []uint) {
+	remaining := make(map[uint]int, len(need))
+	for sid, n := range need {
+		if n > 0 {
+			remaining[sid] = n
+		}
+	}
+
+	for len(remaining) > 0 {
+		var pick uint
+		pickCount := -1
+		for sid := range remaining {
+			count := 0
+			for _, tid := range pool {
+				if eligible(tid, sid) {
+					count++
+				}
+			}
+			if pickCount == -1 || count < pickCount || (count == pickCount && sid < pick) {
+				pick, pickCount = sid, count
+			}
+		}
+		if pickCount == 0 {
+			delete(remaining, pick)
+			unassigned = append(unassigned, pick)
+			continue
+		}
+
+		var best uint
+		bestLoad := -1
+		for _, tid := range pool {
+			if !eligible(tid, pick) {
+				continue
+			}
+			if l := load(tid); bestLoad == -1 || l < bestLoad {
+				best, bestLoad = tid, l
+			}
+		}
+
+		assign(best, pick)
+		picks = append(picks, TAPick{TeachingAssistantID: best, SectionID: pick})
+		if remaining[pick]--; remaining[pick] == 0 {
+			delete(remaining, pick)
+		}
+	}
+
+	sort.Slice(unassigned, func(i, j int) bool { return unassigned[i] < unassigned[j] })
+	return picks, unassigned
+}
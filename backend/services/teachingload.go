@@ -0,0 +1,45 @@
+package services
+
+import "github.com/Nichakorn25/CPE-Teaching-Schedule/entity"
+
+// InstructorShare ชั่วโมงต่อสัปดาห์ที่ผู้สอนหนึ่งคนรับผิดชอบในกลุ่มเรียน
+type InstructorShare struct {
+	UserID  uint
+	Lecture float64
+	Lab     float64
+}
+
+// SplitSectionHours แบ่งชั่วโมงบรรยาย/ปฏิบัติของกลุ่มเรียนให้ผู้สอน (ลำดับเดียวกับ sis)
+// ผู้สอนที่กำหนด LectureHours/LabHours ไว้ได้ตามนั้นแต่ไม่เกินชั่วโมงที่ยังเหลือของกลุ่ม (ไล่ตามลำดับ)
+// ชั่วโมงที่เหลือแบ่งเท่ากันให้ผู้สอนที่ไม่ได้กำหนด ผลรวมจึงไม่เกิน lecture/lab ที่ส่งมา
+func SplitSectionHours(sis []entity.SectionInstructor, lecture, lab float64) []InstructorShare {
+	shares := make([]InstructorShare, len(sis))
+	for i, si := range sis {
+		shares[i].UserID = si.UserID
+	}
+	split(sis, lecture, func(si entity.SectionInstructor) *uint { return si.LectureHours }, func(i int, h float64) { shares[i].Lecture = h })
+	split(sis, lab, func(si entity.SectionInstructor) *uint { return si.LabHours }, func(i int, h float64) { shares[i].Lab = h })
+	return shares
+}
+
+func split(sis []entity.SectionInstructor, total float64, explicit func(entity.SectionInstructor) *uint, set func(i int, hours float64)) {
+	rest, open := total, 0
+	for i, si := range sis {
+		h := explicit(si)
+		if h == nil {
+			open++
+			continue
+		}
+		share := min(float64(*h), rest)
+		set(i, share)
+		rest -= share
+	}
+	if open == 0 {
+		return
+	}
+	for i, si := range sis {
+		if explicit(si) == nil {
+			set(i, rest/float64(open))
+		}
+	}
+}
@@ -112,7 +112,7 @@ func TestAllCoursesValidation(t *testing.T) {
 		g.Expect(err.Error()).To(ContainSubstring("ThaiName is required."))
 	})
 
-	t.Run("Ismain is required (must be true)", func(t *testing.T) {
+	t.Run("Non-main course is valid", func(t *testing.T) {
 		g := NewWithT(t)
 
 		c := entity.AllCourses{
@@ -138,9 +138,8 @@ func TestAllCoursesValidation(t *testing.T) {
 		}
 
 		ok, err := govalidator.ValidateStruct(c)
-		g.Expect(ok).NotTo(BeTrue())
-		g.Expect(err).NotTo(BeNil())
-		g.Expect(err.Error()).To(ContainSubstring("ismain is required."))
+		g.Expect(ok).To(BeTrue())
+		g.Expect(err).To(BeNil())
 	})
 
 	t.Run("All fields are valid", func(t *testing.T) {
@@ -174,3 +173,7 @@ func TestAllCoursesValidation(t *testing.T) {
 		g.Expect(ok).To(BeTrue())
 	})
 }
+
+func TestCourseCodeIndexSkipsDeletedRows(t *testing.T) {
+	expectLiveUniqueIndex(NewWithT(t), &entity.AllCourses{}, "idx_course_code_curriculum", 2)
+}
@@ -0,0 +1,108 @@
+package unit
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/Nichakorn25/CPE-Teaching-Schedule/entity"
+	"github.com/Nichakorn25/CPE-Teaching-Schedule/services"
+	"github.com/gin-gonic/gin/binding"
+	. "github.com/onsi/gomega"
+)
+
+func TestPreferredLanguage(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	g.Expect(services.PreferredLanguage("")).To(Equal(services.LangTH))
+	g.Expect(services.PreferredLanguage("en-US,en;q=0.9")).To(Equal(services.LangEN))
+	g.Expect(services.PreferredLanguage("en;q=0.5, th-TH;q=0.8")).To(Equal(services.LangTH))
+	g.Expect(services.PreferredLanguage("fr-FR,de;q=0.9")).To(Equal(services.LangTH))
+}
+
+func TestAPIErrorBody(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	e := services.APIError{Status: http.StatusNotFound, Message: "ไม่พบกลุ่มเรียน"}
+
+	t.Run("Thai keeps the handler message", func(t *testing.T) {
+		body := e.Body(services.LangTH)
+		g.Expect(body["code"]).To(Equal(services.CodeNotFound))
+		g.Expect(body["error"]).To(Equal("ไม่พบกลุ่มเรียน"))
+		g.Expect(body["fields"]).To(BeEmpty())
+	})
+
+	t.Run("English falls back to the code message", func(t *testing.T) {
+		g.Expect(e.Body(services.LangEN)["error"]).To(Equal("Resource not found."))
+	})
+
+	t.Run("Empty message uses the status message", func(t *testing.T) {
+		body := services.APIError{Status: http.StatusInternalServerError}.Body(services.LangTH)
+		g.Expect(body["code"]).To(Equal(services.CodeInternal))
+		g.Expect(body["error"]).To(Equal("เกิดข้อผิดพลาดภายในระบบ"))
+	})
+}
+
+func TestValidateEntity(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	t.Run("Reports top-level fields only", func(t *testing.T) {
+		// Credit ว่าง (ความสัมพันธ์ที่ไม่ได้โหลด) ต้องไม่ทำให้รายวิชาไม่ผ่าน
+		fields := services.ValidateEntity(entity.AllCourses{Code: "ENG23 1001", ThaiName: "การเขียนโปรแกรม"})
+		g.Expect(fields).To(HaveLen(1))
+		g.Expect(fields[0].Field).To(Equal("EnglishName"))
+		g.Expect(fields[0].Rule).To(Equal("required"))
+	})
+
+	t.Run("Field messages follow the language", func(t *testing.T) {
+		fields := services.ValidateEntity(entity.Room{Name: "B1111", Building: "B", RoomType: "hall", Capacity: 40})
+		g.Expect(fields).To(HaveLen(1))
+
+		en := services.APIError{Status: http.StatusBadRequest, Code: services.CodeValidation, Fields: fields}.Body(services.LangEN)
+		g.Expect(en["fields"]).To(ConsistOf(services.FieldError{Field: "RoomType", Rule: "in", Message: "RoomType is invalid."}))
+
+		th := services.APIError{Status: http.StatusBadRequest, Code: services.CodeValidation, Fields: fields}.Body(services.LangTH)
+		g.Expect(th["fields"]).To(ConsistOf(services.FieldError{Field: "RoomType", Rule: "in", Message: "RoomType ไม่ถูกต้อง"}))
+	})
+
+	t.Run("Valid entity", func(t *testing.T) {
+		g.Expect(services.ValidateEntity(entity.Room{Name: "B1111", Building: "B", RoomType: "lab", Capacity: 40})).To(BeEmpty())
+	})
+}
+
+func TestBindErrors(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	type input struct {
+		Name  string `json:"name" binding:"required"`
+		Count int    `json:"count"`
+	}
+
+	t.Run("Missing required field", func(t *testing.T) {
+		var v input
+		err := binding.JSON.BindBody([]byte(`{"count": 1}`), &v)
+		g.Expect(services.BindErrors(err)).To(ConsistOf(services.FieldError{Field: "Name", Rule: "required"}))
+	})
+
+	t.Run("Wrong JSON type", func(t *testing.T) {
+		var v input
+		err := binding.JSON.BindBody([]byte(`{"name": "a", "count": "two"}`), &v)
+		g.Expect(services.BindErrors(err)).To(ConsistOf(services.FieldError{Field: "count", Rule: "type"}))
+	})
+
+	t.Run("Malformed JSON has no fields", func(t *testing.T) {
+		var v input
+		err := binding.JSON.BindBody([]byte(`{"name":`), &v)
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(services.BindErrors(err)).To(BeEmpty())
+	})
+}
+
+func TestIsName(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	g.Expect(entity.IsName("นันทวุฒิ")).To(BeTrue())
+	g.Expect(entity.IsName("Object-Oriented Technology")).To(BeTrue())
+	g.Expect(entity.IsName("Man, Society and Environment")).To(BeTrue())
+	g.Expect(entity.IsName("Software123")).To(BeFalse())
+	g.Expect(entity.IsName("  ")).To(BeFalse())
+}
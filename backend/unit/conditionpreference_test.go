@@ -0,0 +1,200 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Nichakorn25/CPE-Teaching-Schedule/entity"
+	"github.com/Nichakorn25/CPE-Teaching-Schedule/services"
+	"github.com/asaskevich/govalidator"
+	. "github.com/onsi/gomega"
+)
+
+func TestConditionPreferenceValidation(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	validStart := time.Date(2000, 1, 1, 8, 0, 0, 0, time.UTC)
+	validEnd := time.Date(2000, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("Valid preference for every day", func(t *testing.T) {
+		p := entity.ConditionPreference{
+			StartTime: validStart,
+			EndTime:   validEnd,
+			Weight:    3,
+			UserID:    1,
+		}
+		ok, err := govalidator.ValidateStruct(p)
+		g.Expect(ok).To(BeTrue())
+		g.Expect(err).To(BeNil())
+	})
+
+	t.Run("Valid avoidance", func(t *testing.T) {
+		p := entity.ConditionPreference{
+			DayOfWeek: "ศุกร์",
+			StartTime: validStart,
+			EndTime:   validEnd,
+			Weight:    -5,
+			UserID:    1,
+		}
+		ok, err := govalidator.ValidateStruct(p)
+		g.Expect(ok).To(BeTrue())
+		g.Expect(err).To(BeNil())
+	})
+
+	t.Run("Missing Weight", func(t *testing.T) {
+		p := entity.ConditionPreference{
+			StartTime: validStart,
+			EndTime:   validEnd,
+			UserID:    1,
+		}
+		ok, err := govalidator.ValidateStruct(p)
+		g.Expect(ok).To(BeFalse())
+		g.Expect(err.Error()).To(ContainSubstring("Weight is required."))
+	})
+
+	t.Run("Missing StartTime", func(t *testing.T) {
+		p := entity.ConditionPreference{
+			EndTime: validEnd,
+			Weight:  1,
+			UserID:  1,
+		}
+		ok, err := govalidator.ValidateStruct(p)
+		g.Expect(ok).To(BeFalse())
+		g.Expect(err.Error()).To(ContainSubstring("StartTime is required."))
+	})
+
+	t.Run("Limit requires UserID", func(t *testing.T) {
+		l := entity.ConditionLimit{MaxTeachingDays: 3, MaxHoursPerDay: 6}
+		ok, err := govalidator.ValidateStruct(l)
+		g.Expect(ok).To(BeFalse())
+		g.Expect(err.Error()).To(ContainSubstring("UserID is required."))
+	})
+
+	t.Run("Limit without caps is valid", func(t *testing.T) {
+		l := entity.ConditionLimit{UserID: 1}
+		ok, err := govalidator.ValidateStruct(l)
+		g.Expect(ok).To(BeTrue())
+		g.Expect(err).To(BeNil())
+	})
+}
+
+var bangkok = time.FixedZone("Asia/Bangkok", 7*60*60)
+
+// clock เวลา HH:MM ตามเวลาไทย (วันที่ไม่มีผล)
+func clock(h, m int) time.Time {
+	return time.Date(2000, 1, 1, h, m, 0, 0, bangkok)
+}
+
+// prefSession คาบของวิชาที่เปิดสอน oc กลุ่ม 1
+func prefSession(oc uint, day string, fromH, toH int) entity.Schedule {
+	return entity.Schedule{OfferedCoursesID: oc, SectionNumber: 1, DayOfWeek: day, StartTime: clock(fromH, 0), EndTime: clock(toH, 0)}
+}
+
+// teachesOffered ผู้สอน uid สอนวิชาที่เปิดสอน oc ตาม owner[oc]
+func teachesOffered(owner map[uint]uint) services.Teaches {
+	return func(uid uint, s entity.Schedule) bool { return owner[s.OfferedCoursesID] == uid }
+}
+
+func TestTeacherPrefsScore(t *testing.T) {
+	g := NewGomegaWithT(t)
+	prefs := services.NewTeacherPrefs([]entity.ConditionPreference{
+		{UserID: 1, StartTime: clock(8, 0), EndTime: clock(12, 0), Weight: 2},
+		{UserID: 1, DayOfWeek: "ศุกร์", StartTime: clock(13, 0), EndTime: clock(16, 0), Weight: -3},
+		{UserID: 2, StartTime: clock(9, 0), EndTime: clock(10, 0), Weight: 1},
+	}, nil)
+
+	t.Run("Weight is multiplied by overlapping hours", func(t *testing.T) {
+		g.Expect(prefs.Score([]uint{1}, "จันทร์", 10*60, 13*60)).To(Equal(4.0))
+	})
+
+	t.Run("Day-specific avoidance only applies on that day", func(t *testing.T) {
+		g.Expect(prefs.Score([]uint{1}, "ศุกร์", 13*60, 15*60)).To(Equal(-6.0))
+		g.Expect(prefs.Score([]uint{1}, "พุธ", 13*60, 15*60)).To(Equal(0.0))
+	})
+
+	t.Run("Scores of all teachers are added", func(t *testing.T) {
+		g.Expect(prefs.Score([]uint{1, 2}, "อังคาร", 9*60, 10*60)).To(Equal(3.0))
+	})
+}
+
+func TestTeacherPrefsWithinLimits(t *testing.T) {
+	g := NewGomegaWithT(t)
+	prefs := services.NewTeacherPrefs(nil, []entity.ConditionLimit{
+		{UserID: 1, MaxTeachingDays: 2, MaxHoursPerDay: 4},
+	})
+	teaches := teachesOffered(map[uint]uint{10: 1, 20: 2})
+	schedules := []entity.Schedule{
+		prefSession(10, "จันทร์", 8, 11),
+		prefSession(10, "อังคาร", 9, 10),
+		prefSession(20, "พุธ", 8, 12),
+	}
+
+	t.Run("Adding to an existing teaching day within the daily hours", func(t *testing.T) {
+		g.Expect(prefs.WithinLimits([]uint{1}, "จันทร์", 60, schedules, teaches)).To(BeTrue())
+	})
+
+	t.Run("Exceeding the daily hours", func(t *testing.T) {
+		g.Expect(prefs.WithinLimits([]uint{1}, "จันทร์", 120, schedules, teaches)).To(BeFalse())
+	})
+
+	t.Run("Opening a third teaching day", func(t *testing.T) {
+		g.Expect(prefs.WithinLimits([]uint{1}, "พุธ", 60, schedules, teaches)).To(BeFalse())
+	})
+
+	t.Run("Teachers without limits are not restricted", func(t *testing.T) {
+		g.Expect(prefs.WithinLimits([]uint{2}, "พุธ", 600, schedules, teaches)).To(BeTrue())
+	})
+}
+
+func TestTeacherPrefsRankStarts(t *testing.T) {
+	g := NewGomegaWithT(t)
+	prefs := services.NewTeacherPrefs([]entity.ConditionPreference{
+		{UserID: 1, StartTime: clock(9, 0), EndTime: clock(11, 0), Weight: 3},
+		{UserID: 1, StartTime: clock(13, 0), EndTime: clock(14, 0), Weight: -2},
+	}, nil)
+
+	// คะแนนเท่ากัน (8:00 กับ 15:00 ไม่ทับช่วงใด) คงลำดับเดิม
+	starts := []int{15 * 60, 13 * 60, 8 * 60, 9 * 60}
+	prefs.RankStarts(starts, []uint{1}, "จันทร์", 60)
+	g.Expect(starts).To(Equal([]int{9 * 60, 15 * 60, 8 * 60, 13 * 60}))
+}
+
+func TestTeacherPrefsSatisfactionReport(t *testing.T) {
+	g := NewGomegaWithT(t)
+	prefs := services.NewTeacherPrefs([]entity.ConditionPreference{
+		{UserID: 1, StartTime: clock(8, 0), EndTime: clock(12, 0), Weight: 2},
+		{UserID: 1, DayOfWeek: "ศุกร์", StartTime: clock(8, 0), EndTime: clock(17, 0), Weight: -1},
+	}, []entity.ConditionLimit{
+		{UserID: 1, MaxTeachingDays: 1},
+		{UserID: 2, MaxHoursPerDay: 3},
+	})
+	teaches := teachesOffered(map[uint]uint{10: 1, 20: 2})
+	schedules := []entity.Schedule{
+		prefSession(10, "จันทร์", 10, 14), // ตรงช่วงที่ต้องการ 2 ชั่วโมง
+		prefSession(10, "ศุกร์", 9, 11),   // ตรงช่วงที่ต้องการและช่วงที่เลี่ยง 2 ชั่วโมง
+		prefSession(20, "อังคาร", 8, 11),
+	}
+
+	report := prefs.SatisfactionReport(schedules, teaches)
+	g.Expect(report).To(HaveLen(2))
+
+	t.Run("Teacher who breaks a limit and teaches outside preferences", func(t *testing.T) {
+		r := report[0]
+		g.Expect(r.UserID).To(Equal(uint(1)))
+		g.Expect(r.TeachingMinutes).To(Equal(360))
+		g.Expect(r.PreferredMinutes).To(Equal(240))
+		g.Expect(r.AvoidedMinutes).To(Equal(120))
+		g.Expect(r.TeachingDays).To(Equal(2))
+		g.Expect(r.LimitsMet).To(BeFalse())
+		// (0 + 240/360 + (1 - 120/360)) / 3
+		g.Expect(r.Score).To(Equal(44.4))
+	})
+
+	t.Run("Teacher within limits scores full marks", func(t *testing.T) {
+		r := report[1]
+		g.Expect(r.UserID).To(Equal(uint(2)))
+		g.Expect(r.MaxDailyHours).To(Equal(3.0))
+		g.Expect(r.LimitsMet).To(BeTrue())
+		g.Expect(r.Score).To(Equal(100.0))
+	})
+}
@@ -0,0 +1,64 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/Nichakorn25/CPE-Teaching-Schedule/config"
+	"github.com/Nichakorn25/CPE-Teaching-Schedule/entity"
+	. "github.com/onsi/gomega"
+)
+
+func uintPtr(v uint) *uint { return &v }
+
+func TestCreditHourRules(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	course := entity.AllCourses{
+		TypeOfCoursesID: 2,
+		Credit:          entity.Credit{Unit: 3, Lecture: 2, Lab: 1, Self: 5},
+	}
+	course.ID = 10
+
+	t.Run("Default rule", func(t *testing.T) {
+		var rules *config.HoursRules
+		h := rules.For(course)
+		g.Expect(h.Lecture).To(Equal(2))
+		g.Expect(h.Lab).To(Equal(3))
+		g.Expect(h.MaxLectureBlock).To(Equal(3))
+		g.Expect(h.LabBlocks()).To(Equal([]int{3}))
+	})
+
+	t.Run("Course type rule", func(t *testing.T) {
+		rules := config.NewHoursRules([]entity.CreditHourRule{
+			{TypeOfCoursesID: uintPtr(2), LabPerCredit: uintPtr(2)},
+		})
+		h := rules.For(course)
+		g.Expect(h.Lecture).To(Equal(2))
+		g.Expect(h.Lab).To(Equal(2))
+	})
+
+	t.Run("Course rule overrides course type rule", func(t *testing.T) {
+		rules := config.NewHoursRules([]entity.CreditHourRule{
+			{TypeOfCoursesID: uintPtr(2), LabPerCredit: uintPtr(2), MaxLectureBlock: uintPtr(2)},
+			{AllCoursesID: uintPtr(10), LectureHours: uintPtr(4), MaxLabBlock: uintPtr(1)},
+		})
+		h := rules.For(course)
+		g.Expect(h.Lecture).To(Equal(4))
+		g.Expect(h.Lab).To(Equal(2))
+		g.Expect(h.MaxLectureBlock).To(Equal(2))
+		g.Expect(h.LabBlocks()).To(Equal([]int{1, 1}))
+	})
+
+	t.Run("Split lab into blocks", func(t *testing.T) {
+		h := config.CourseHours{Lab: 6, MaxLabBlock: 2}
+		g.Expect(h.LabBlocks()).To(Equal([]int{2, 2, 2}))
+
+		h = config.CourseHours{Lab: 5, MaxLabBlock: 2}
+		g.Expect(h.LabBlocks()).To(Equal([]int{2, 2, 1}))
+	})
+
+	t.Run("No lab", func(t *testing.T) {
+		h := config.CourseHours{Lecture: 3}
+		g.Expect(h.LabBlocks()).To(BeEmpty())
+	})
+}
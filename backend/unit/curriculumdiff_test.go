@@ -0,0 +1,91 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/Nichakorn25/CPE-Teaching-Schedule/config"
+	"github.com/Nichakorn25/CPE-Teaching-Schedule/entity"
+	"github.com/asaskevich/govalidator"
+	. "github.com/onsi/gomega"
+	"gorm.io/gorm"
+)
+
+func diffCourse(id uint, code, name string, unit uint, typeName string) entity.AllCourses {
+	return entity.AllCourses{
+		Model:         gorm.Model{ID: id},
+		Code:          code,
+		ThaiName:      name,
+		EnglishName:   name,
+		Credit:        entity.Credit{Unit: unit, Lecture: unit, Lab: 0, Self: unit * 2},
+		TypeOfCourses: entity.TypeOfCourses{TypeName: typeName},
+	}
+}
+
+func TestNormalizeCourseCode(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	g.Expect(config.NormalizeCourseCode("ENG23 1001")).To(Equal("ENG231001"))
+	g.Expect(config.NormalizeCourseCode("eng23-1001**")).To(Equal("ENG231001"))
+}
+
+func TestCompareCurricula(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	oldCourses := []entity.AllCourses{
+		diffCourse(1, "ENG23 1001", "Programming", 3, "หมวดวิชาเฉพาะ"),
+		diffCourse(2, "ENG23 2001", "Data Structures", 3, "หมวดวิชาเฉพาะ"),
+		diffCourse(3, "ENG23 3001", "Compiler", 4, "หมวดวิชาเฉพาะ"),
+		diffCourse(4, "ENG23 4001", "Legacy Systems", 3, "หมวดวิชาเลือก"),
+	}
+	newCourses := []entity.AllCourses{
+		diffCourse(11, "ENG231001", "Programming", 3, "หมวดวิชาเฉพาะ"),
+		diffCourse(12, "ENG23 2001", "Data Structures", 4, "หมวดวิชาเฉพาะ"),
+		diffCourse(13, "ENG23 3101", "Compiler", 4, "หมวดวิชาเฉพาะ"),
+		diffCourse(14, "ENG23 4501", "Machine Learning", 3, "หมวดวิชาเลือก"),
+	}
+
+	diff := config.CompareCurricula(oldCourses, newCourses, map[uint]uint{3: 13})
+
+	t.Run("Added and removed", func(t *testing.T) {
+		g.Expect(diff.Added).To(HaveLen(1))
+		g.Expect(diff.Added[0].ID).To(Equal(uint(14)))
+		g.Expect(diff.Removed).To(HaveLen(1))
+		g.Expect(diff.Removed[0].ID).To(Equal(uint(4)))
+	})
+
+	t.Run("Changed by code and by mapping", func(t *testing.T) {
+		g.Expect(diff.Changed).To(HaveLen(3))
+
+		byOld := map[uint]config.CourseChange{}
+		for _, ch := range diff.Changed {
+			byOld[ch.Old.ID] = ch
+		}
+		g.Expect(byOld[1].MatchedBy).To(Equal(config.MatchByCode))
+		g.Expect(byOld[1].Changes).To(ConsistOf(config.CourseFieldChange{Field: "Code", Old: "ENG23 1001", New: "ENG231001"}))
+
+		g.Expect(byOld[2].Changes).To(ConsistOf(config.CourseFieldChange{Field: "Credit", Old: "3 (3-0-6)", New: "4 (4-0-8)"}))
+
+		g.Expect(byOld[3].MatchedBy).To(Equal(config.MatchByMapping))
+		g.Expect(byOld[3].New.ID).To(Equal(uint(13)))
+	})
+
+	t.Run("Identical curricula", func(t *testing.T) {
+		same := config.CompareCurricula(oldCourses, oldCourses, nil)
+		g.Expect(same.Unchanged).To(HaveLen(4))
+		g.Expect(same.Changed).To(BeEmpty())
+		g.Expect(same.Added).To(BeEmpty())
+		g.Expect(same.Removed).To(BeEmpty())
+	})
+}
+
+func TestCourseMappingValidation(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	ok, err := govalidator.ValidateStruct(entity.CourseMapping{OldCourseID: 1, NewCourseID: 2})
+	g.Expect(ok).To(BeTrue())
+	g.Expect(err).To(BeNil())
+
+	ok, err = govalidator.ValidateStruct(entity.CourseMapping{OldCourseID: 1})
+	g.Expect(ok).To(BeFalse())
+	g.Expect(err.Error()).To(ContainSubstring("NewCourseID is required."))
+}
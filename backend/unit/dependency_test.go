@@ -0,0 +1,74 @@
+package unit
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/Nichakorn25/CPE-Teaching-Schedule/config"
+	. "github.com/onsi/gomega"
+	"gorm.io/gorm/schema"
+)
+
+func TestDependencyGraph(t *testing.T) {
+	g := NewGomegaWithT(t)
+	cache := &sync.Map{}
+
+	parse := func(kind string) *schema.Schema {
+		model := config.DependencyModel(kind)
+		g.Expect(model).NotTo(BeNil(), kind)
+		s, err := schema.Parse(model, cache, schema.NamingStrategy{})
+		g.Expect(err).To(BeNil())
+		return s
+	}
+
+	t.Run("Every kind has a soft-deletable model and a label", func(t *testing.T) {
+		for _, kind := range config.DependencyKinds() {
+			s := parse(kind)
+			g.Expect(s.LookUpField("deleted_at")).NotTo(BeNil(), kind)
+			g.Expect(config.DependencyLabel(kind)).NotTo(Equal(kind))
+		}
+	})
+
+	t.Run("Every dependency column exists on the child table", func(t *testing.T) {
+		for _, parent := range config.DependencyKinds() {
+			for _, dep := range config.Dependencies(parent) {
+				field := parse(dep.Kind).LookUpField(dep.Column)
+				g.Expect(field).NotTo(BeNil(), dep.Kind+"."+dep.Column)
+				if dep.Detach {
+					// ตั้งเป็น NULL ได้เฉพาะคอลัมน์ pointer
+					g.Expect(field.FieldType.Kind().String()).To(Equal("ptr"), dep.Kind+"."+dep.Column)
+					g.Expect(dep.Owned).To(BeFalse())
+				}
+			}
+		}
+	})
+
+	t.Run("Graph has no cycles", func(t *testing.T) {
+		state := map[string]int{}
+		var visit func(kind string) bool
+		visit = func(kind string) bool {
+			switch state[kind] {
+			case 1:
+				return false
+			case 2:
+				return true
+			}
+			state[kind] = 1
+			for _, dep := range config.Dependencies(kind) {
+				if !dep.Detach && !visit(dep.Kind) {
+					return false
+				}
+			}
+			state[kind] = 2
+			return true
+		}
+		for _, kind := range config.DependencyKinds() {
+			g.Expect(visit(kind)).To(BeTrue(), kind)
+		}
+	})
+
+	t.Run("Unknown kind has no model", func(t *testing.T) {
+		g.Expect(config.DependencyModel("nothing")).To(BeNil())
+		g.Expect(config.Dependencies("nothing")).To(BeEmpty())
+	})
+}
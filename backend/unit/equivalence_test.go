@@ -0,0 +1,52 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/Nichakorn25/CPE-Teaching-Schedule/config"
+	"github.com/Nichakorn25/CPE-Teaching-Schedule/entity"
+	"github.com/asaskevich/govalidator"
+	. "github.com/onsi/gomega"
+)
+
+func TestCourseEquivalenceValidation(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	t.Run("Valid equivalence", func(t *testing.T) {
+		ok, err := govalidator.ValidateStruct(entity.CourseEquivalence{AllCoursesID: 1, EquivalentID: 2})
+		g.Expect(ok).To(BeTrue())
+		g.Expect(err).To(BeNil())
+	})
+
+	t.Run("Missing EquivalentID", func(t *testing.T) {
+		ok, err := govalidator.ValidateStruct(entity.CourseEquivalence{AllCoursesID: 1})
+		g.Expect(ok).To(BeFalse())
+		g.Expect(err.Error()).To(ContainSubstring("EquivalentID is required."))
+	})
+
+	t.Run("Missing alias course", func(t *testing.T) {
+		ok, err := govalidator.ValidateStruct(entity.OfferedCourseAlias{OfferedCoursesID: 3})
+		g.Expect(ok).To(BeFalse())
+		g.Expect(err.Error()).To(ContainSubstring("AllCoursesID is required."))
+	})
+}
+
+func TestEquivalenceClasses(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	// 2560 -> 2565 -> 2570 และคู่แยก 7 = 8
+	eq := config.NewEquivalence([]entity.CourseEquivalence{
+		{AllCoursesID: 1, EquivalentID: 2},
+		{AllCoursesID: 3, EquivalentID: 2},
+		{AllCoursesID: 7, EquivalentID: 8},
+	})
+
+	g.Expect(eq.Equivalent(1, 3)).To(BeTrue())
+	g.Expect(eq.Equivalent(3, 1)).To(BeTrue())
+	g.Expect(eq.Equivalent(1, 7)).To(BeFalse())
+	g.Expect(eq.Equivalent(5, 5)).To(BeTrue())
+
+	g.Expect(eq.Class(2)).To(Equal([]uint{1, 3}))
+	g.Expect(eq.Class(8)).To(Equal([]uint{7}))
+	g.Expect(eq.Class(42)).To(BeEmpty())
+}
@@ -0,0 +1,43 @@
+package unit
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/Nichakorn25/CPE-Teaching-Schedule/services"
+	. "github.com/onsi/gomega"
+)
+
+func TestFoldICSLine(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	t.Run("Short line is unchanged", func(t *testing.T) {
+		g.Expect(services.FoldICSLine("SUMMARY:ENG23 1001")).To(Equal("SUMMARY:ENG23 1001"))
+	})
+
+	t.Run("ASCII line folds at 75 octets", func(t *testing.T) {
+		line := "DESCRIPTION:" + strings.Repeat("a", 100)
+		parts := strings.Split(services.FoldICSLine(line), "\r\n")
+		g.Expect(parts).To(HaveLen(2))
+		g.Expect(parts[0]).To(HaveLen(75))
+		g.Expect(parts[1]).To(HavePrefix(" "))
+		g.Expect(parts[0] + parts[1][1:]).To(Equal(line))
+	})
+
+	t.Run("Thai text is never split inside a character", func(t *testing.T) {
+		line := "SUMMARY:" + strings.Repeat("การเขียนโปรแกรม ", 10)
+		parts := strings.Split(services.FoldICSLine(line), "\r\n")
+		g.Expect(len(parts)).To(BeNumerically(">", 1))
+		joined := parts[0]
+		for i, p := range parts {
+			g.Expect(len(p)).To(BeNumerically("<=", 75))
+			g.Expect(utf8.ValidString(p)).To(BeTrue())
+			if i > 0 {
+				g.Expect(p).To(HavePrefix(" "))
+				joined += p[1:]
+			}
+		}
+		g.Expect(joined).To(Equal(line))
+	})
+}
@@ -0,0 +1,41 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/Nichakorn25/CPE-Teaching-Schedule/entity"
+	"github.com/asaskevich/govalidator"
+	. "github.com/onsi/gomega"
+)
+
+func TestLaboratoryValidation(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	t.Run("Missing Room", func(t *testing.T) {
+		lab := entity.Laboratory{Room: "", Building: "F11", Capacity: 40}
+		ok, err := govalidator.ValidateStruct(lab)
+		g.Expect(ok).To(BeFalse())
+		g.Expect(err.Error()).To(ContainSubstring("Room is required."))
+	})
+
+	t.Run("Missing Building", func(t *testing.T) {
+		lab := entity.Laboratory{Room: "F11419", Building: "", Capacity: 40}
+		ok, err := govalidator.ValidateStruct(lab)
+		g.Expect(ok).To(BeFalse())
+		g.Expect(err.Error()).To(ContainSubstring("Building is required."))
+	})
+
+	t.Run("Missing Capacity", func(t *testing.T) {
+		lab := entity.Laboratory{Room: "F11419", Building: "F11", Capacity: 0}
+		ok, err := govalidator.ValidateStruct(lab)
+		g.Expect(ok).To(BeFalse())
+		g.Expect(err.Error()).To(ContainSubstring("Capacity is required."))
+	})
+
+	t.Run("All fields valid", func(t *testing.T) {
+		lab := entity.Laboratory{Room: "F11419", Building: "F11", Capacity: 40}
+		ok, err := govalidator.ValidateStruct(lab)
+		g.Expect(ok).To(BeTrue())
+		g.Expect(err).To(BeNil())
+	})
+}
@@ -0,0 +1,63 @@
+package unit
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/Nichakorn25/CPE-Teaching-Schedule/services"
+	. "github.com/onsi/gomega"
+)
+
+var testListSpec = services.ListSpec{
+	Sorts: map[string]string{
+		"id":   "all_courses.id",
+		"code": "all_courses.code",
+	},
+	Filters: map[string]services.ListFilter{
+		"major_id": {Kind: services.FilterUint, Where: "curriculums.major_id = ?"},
+		"search":   {Kind: services.FilterSearch, Where: "all_courses.code ILIKE ?"},
+	},
+	DefaultSort: "id",
+}
+
+func TestParseListQuery(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	t.Run("Defaults keep legacy response", func(t *testing.T) {
+		q, err := services.ParseListQuery(url.Values{}, testListSpec)
+		g.Expect(err).To(BeNil())
+		g.Expect(q.Paged).To(BeFalse())
+		g.Expect(q.Offset()).To(Equal(0))
+		g.Expect(q.Sort).To(Equal([]services.SortField{{Column: "all_courses.id"}}))
+	})
+
+	t.Run("Page, size and multi-field sort", func(t *testing.T) {
+		q, err := services.ParseListQuery(url.Values{"page": {"3"}, "size": {"10"}, "sort": {"-code,id"}}, testListSpec)
+		g.Expect(err).To(BeNil())
+		g.Expect(q.Paged).To(BeTrue())
+		g.Expect(q.Offset()).To(Equal(20))
+		g.Expect(q.Sort).To(Equal([]services.SortField{
+			{Column: "all_courses.code", Desc: true},
+			{Column: "all_courses.id"},
+		}))
+	})
+
+	t.Run("Unknown sort field", func(t *testing.T) {
+		_, err := services.ParseListQuery(url.Values{"sort": {"password"}}, testListSpec)
+		g.Expect(err).NotTo(BeNil())
+		g.Expect(err.Error()).To(ContainSubstring("password"))
+	})
+
+	t.Run("Invalid page and size", func(t *testing.T) {
+		_, err := services.ParseListQuery(url.Values{"page": {"0"}}, testListSpec)
+		g.Expect(err).NotTo(BeNil())
+		_, err = services.ParseListQuery(url.Values{"page": {"1"}, "size": {"100000"}}, testListSpec)
+		g.Expect(err).NotTo(BeNil())
+	})
+
+	t.Run("Typed filter rejects non-numeric id", func(t *testing.T) {
+		_, err := services.ParseListQuery(url.Values{"major_id": {"abc"}}, testListSpec)
+		g.Expect(err).NotTo(BeNil())
+		g.Expect(err.Error()).To(ContainSubstring("major_id"))
+	})
+}
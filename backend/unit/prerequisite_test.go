@@ -0,0 +1,104 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/Nichakorn25/CPE-Teaching-Schedule/config"
+	"github.com/Nichakorn25/CPE-Teaching-Schedule/entity"
+	"github.com/asaskevich/govalidator"
+	. "github.com/onsi/gomega"
+)
+
+func TestCoursePrerequisiteValidation(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	t.Run("Valid prerequisite", func(t *testing.T) {
+		p := entity.CoursePrerequisite{Kind: entity.RequisitePre, AllCoursesID: 2, RequiresID: 1}
+		ok, err := govalidator.ValidateStruct(p)
+		g.Expect(ok).To(BeTrue())
+		g.Expect(err).To(BeNil())
+	})
+
+	t.Run("Invalid Kind", func(t *testing.T) {
+		p := entity.CoursePrerequisite{Kind: "recommended", AllCoursesID: 2, RequiresID: 1}
+		ok, err := govalidator.ValidateStruct(p)
+		g.Expect(ok).To(BeFalse())
+		g.Expect(err.Error()).To(ContainSubstring("Kind is invalid."))
+	})
+
+	t.Run("Missing RequiresID", func(t *testing.T) {
+		p := entity.CoursePrerequisite{Kind: entity.RequisiteCo, AllCoursesID: 2}
+		ok, err := govalidator.ValidateStruct(p)
+		g.Expect(ok).To(BeFalse())
+		g.Expect(err.Error()).To(ContainSubstring("RequiresID is required."))
+	})
+}
+
+func TestRequisiteGraph(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	// 3 ต้องผ่าน 2, 2 ต้องผ่าน 1, 4 เรียนร่วมกับ 3
+	graph := config.NewRequisiteGraph([]entity.CoursePrerequisite{
+		{Kind: entity.RequisitePre, AllCoursesID: 2, RequiresID: 1},
+		{Kind: entity.RequisitePre, AllCoursesID: 3, RequiresID: 2},
+		{Kind: entity.RequisiteCo, AllCoursesID: 4, RequiresID: 3},
+	})
+
+	t.Run("Self reference is a cycle", func(t *testing.T) {
+		g.Expect(graph.CyclePath(1, 1, entity.RequisitePre)).To(Equal([]uint{1, 1}))
+	})
+
+	t.Run("Indirect cycle", func(t *testing.T) {
+		g.Expect(graph.CyclePath(1, 3, entity.RequisitePre)).To(Equal([]uint{1, 3, 2, 1}))
+	})
+
+	t.Run("New branch is not a cycle", func(t *testing.T) {
+		g.Expect(graph.CyclePath(5, 3, entity.RequisitePre)).To(BeNil())
+	})
+
+	t.Run("Mutual corequisites are allowed", func(t *testing.T) {
+		g.Expect(graph.CyclePath(3, 4, entity.RequisiteCo)).To(BeNil())
+	})
+
+	t.Run("Corequisite against a prerequisite chain is a cycle", func(t *testing.T) {
+		g.Expect(graph.CyclePath(1, 4, entity.RequisiteCo)).To(Equal([]uint{1, 4, 3, 2, 1}))
+	})
+
+	t.Run("Transitive prerequisites", func(t *testing.T) {
+		g.Expect(graph.Prerequisites(3)).To(ConsistOf(uint(2), uint(1)))
+		g.Expect(graph.Prerequisites(4)).To(BeEmpty())
+	})
+}
+
+func TestRemapRequisites(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	// หลักสูตรต้นทาง: 10 <- 11 <- 12 (12 เรียนร่วมกับ 11) และ 11 ต้องผ่าน 99 (วิชาศึกษาทั่วไป)
+	rows := []entity.CoursePrerequisite{
+		{Kind: entity.RequisitePre, AllCoursesID: 11, RequiresID: 10},
+		{Kind: entity.RequisitePre, AllCoursesID: 11, RequiresID: 99},
+		{Kind: entity.RequisiteCo, AllCoursesID: 12, RequiresID: 11},
+	}
+	inSource := map[uint]bool{10: true, 11: true, 12: true}
+
+	t.Run("Maps copied courses and keeps external prerequisites", func(t *testing.T) {
+		idMap := map[uint]uint{10: 20, 11: 21, 12: 22}
+		mapped, skipped := config.RemapRequisites(rows, idMap, inSource)
+		g.Expect(skipped).To(BeEmpty())
+		g.Expect(mapped).To(ConsistOf(
+			entity.CoursePrerequisite{Kind: entity.RequisitePre, AllCoursesID: 21, RequiresID: 20},
+			entity.CoursePrerequisite{Kind: entity.RequisitePre, AllCoursesID: 21, RequiresID: 99},
+			entity.CoursePrerequisite{Kind: entity.RequisiteCo, AllCoursesID: 22, RequiresID: 21},
+		))
+	})
+
+	t.Run("Skips prerequisites missing from target", func(t *testing.T) {
+		idMap := map[uint]uint{11: 21}
+		mapped, skipped := config.RemapRequisites(rows, idMap, inSource)
+		g.Expect(mapped).To(ConsistOf(
+			entity.CoursePrerequisite{Kind: entity.RequisitePre, AllCoursesID: 21, RequiresID: 99},
+		))
+		g.Expect(skipped).To(HaveLen(1))
+		g.Expect(skipped[0].RequiresID).To(Equal(uint(10)))
+	})
+}
@@ -0,0 +1,67 @@
+package unit
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/Nichakorn25/CPE-Teaching-Schedule/entity"
+	"github.com/asaskevich/govalidator"
+	. "github.com/onsi/gomega"
+	"gorm.io/gorm/schema"
+)
+
+func TestRoomValidation(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	t.Run("Missing Name", func(t *testing.T) {
+		r := entity.Room{Name: "", Building: "B", RoomType: entity.RoomTypeLectureHall, Capacity: 40}
+		ok, err := govalidator.ValidateStruct(r)
+		g.Expect(ok).To(BeFalse())
+		g.Expect(err.Error()).To(ContainSubstring("Name is required."))
+	})
+
+	t.Run("Missing Capacity", func(t *testing.T) {
+		r := entity.Room{Name: "B5207", Building: "B", RoomType: entity.RoomTypeLectureHall, Capacity: 0}
+		ok, err := govalidator.ValidateStruct(r)
+		g.Expect(ok).To(BeFalse())
+		g.Expect(err.Error()).To(ContainSubstring("Capacity is required."))
+	})
+
+	t.Run("Invalid RoomType", func(t *testing.T) {
+		r := entity.Room{Name: "B5207", Building: "B", RoomType: "office", Capacity: 40}
+		ok, err := govalidator.ValidateStruct(r)
+		g.Expect(ok).To(BeFalse())
+		g.Expect(err.Error()).To(ContainSubstring("RoomType is invalid."))
+	})
+
+	t.Run("All fields valid", func(t *testing.T) {
+		for _, roomType := range []string{entity.RoomTypeLectureHall, entity.RoomTypeLab, entity.RoomTypeComputerRoom} {
+			r := entity.Room{Name: "B5207", Building: "B", RoomType: roomType, Capacity: 40, Equipment: "projector"}
+			ok, err := govalidator.ValidateStruct(r)
+			g.Expect(ok).To(BeTrue())
+			g.Expect(err).To(BeNil())
+		}
+	})
+}
+
+// expectLiveUniqueIndex ดัชนี unique ชื่อ name ต้องยกเว้นแถวที่ถูก soft delete
+func expectLiveUniqueIndex(g *WithT, model interface{}, name string, fields int) {
+	s, err := schema.Parse(model, &sync.Map{}, schema.NamingStrategy{})
+	g.Expect(err).To(BeNil())
+
+	var found bool
+	for _, idx := range s.ParseIndexes() {
+		if idx.Name != name {
+			continue
+		}
+		found = true
+		g.Expect(idx.Class).To(Equal("UNIQUE"))
+		g.Expect(idx.Where).To(Equal("deleted_at IS NULL"))
+		g.Expect(idx.Fields).To(HaveLen(fields))
+	}
+	g.Expect(found).To(BeTrue(), name)
+}
+
+func TestRoomNameIndexSkipsDeletedRows(t *testing.T) {
+	expectLiveUniqueIndex(NewWithT(t), &entity.Room{}, "idx_room_name_building", 2)
+}
@@ -0,0 +1,65 @@
+package unit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Nichakorn25/CPE-Teaching-Schedule/controllers"
+	"github.com/Nichakorn25/CPE-Teaching-Schedule/services"
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/gomega"
+)
+
+func TestRegisterRoutes(t *testing.T) {
+	g := NewGomegaWithT(t)
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	g.Expect(func() { controllers.RegisterRoutes(r) }).NotTo(Panic())
+
+	registered := map[string]bool{}
+	for _, info := range r.Routes() {
+		registered[info.Method+" "+info.Path] = true
+	}
+
+	t.Run("Every v1 route and legacy alias is registered", func(t *testing.T) {
+		for _, rt := range controllers.APIRoutes() {
+			g.Expect(registered).To(HaveKey(rt.Method + " " + services.APIVersionPrefix + rt.Path))
+			for _, legacy := range rt.Legacy {
+				g.Expect(registered).To(HaveKey(rt.Method + " " + legacy))
+			}
+		}
+	})
+
+	t.Run("OpenAPI document covers every v1 route", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/openapi.json", nil))
+		g.Expect(w.Code).To(Equal(http.StatusOK))
+
+		var doc struct {
+			OpenAPI string                                       `json:"openapi"`
+			Paths   map[string]map[string]map[string]interface{} `json:"paths"`
+		}
+		g.Expect(json.Unmarshal(w.Body.Bytes(), &doc)).To(Succeed())
+		g.Expect(doc.OpenAPI).To(HavePrefix("3."))
+
+		ops := map[string]bool{}
+		for _, rt := range controllers.APIRoutes() {
+			op := doc.Paths[services.OpenAPIPath(rt.Path)]
+			g.Expect(op).To(HaveKey(strings.ToLower(rt.Method)), rt.Method+" "+rt.Path)
+			g.Expect(ops).NotTo(HaveKey(rt.OperationID))
+			ops[rt.OperationID] = true
+		}
+	})
+}
+
+func TestOpenAPIPath(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	g.Expect(services.OpenAPIPath("/courses/:id/prerequisites/:requiresID")).To(Equal("/courses/{id}/prerequisites/{requiresID}"))
+	g.Expect(services.PathParams("/term-calendars/:year/:term")).To(Equal([]string{"year", "term"}))
+	g.Expect(services.PathParams("/courses")).To(BeEmpty())
+}
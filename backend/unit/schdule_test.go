@@ -4,6 +4,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/Nichakorn25/CPE-Teaching-Schedule/config"
 	"github.com/Nichakorn25/CPE-Teaching-Schedule/entity"
 	"github.com/asaskevich/govalidator"
 	. "github.com/onsi/gomega"
@@ -97,6 +98,38 @@ func TestScheduleValidation(t *testing.T) {
 		g.Expect(err.Error()).To(ContainSubstring("OfferedCoursesID is required."))
 	})
 
+	t.Run("Invalid Kind", func(t *testing.T) {
+		s := entity.Schedule{
+			NameTable:        "T01",
+			SectionNumber:    1,
+			DayOfWeek:        "Tuesday",
+			StartTime:        validStart,
+			EndTime:          validEnd,
+			Kind:             "seminar",
+			OfferedCoursesID: 1,
+		}
+		ok, err := govalidator.ValidateStruct(s)
+		g.Expect(ok).To(BeFalse())
+		g.Expect(err.Error()).To(ContainSubstring("Kind is invalid."))
+	})
+
+	t.Run("Session block with kind", func(t *testing.T) {
+		for _, kind := range []string{entity.SessionKindLecture, entity.SessionKindLab} {
+			s := entity.Schedule{
+				NameTable:        "T01",
+				SectionNumber:    1,
+				DayOfWeek:        "Tuesday",
+				StartTime:        validStart,
+				EndTime:          validEnd,
+				Kind:             kind,
+				OfferedCoursesID: 1,
+			}
+			ok, err := govalidator.ValidateStruct(s)
+			g.Expect(ok).To(BeTrue())
+			g.Expect(err).To(BeNil())
+		}
+	})
+
 	t.Run("All fields valid", func(t *testing.T) {
 		s := entity.Schedule{
 			NameTable:        "T01",
@@ -111,3 +144,107 @@ func TestScheduleValidation(t *testing.T) {
 		g.Expect(err).To(BeNil())
 	})
 }
+
+func sessionRow(id uint, day string, from, to int, kind string) entity.Schedule {
+	loc := time.FixedZone("Asia/Bangkok", 7*60*60)
+	s := entity.Schedule{
+		NameTable:        "ปีการศึกษา 2568 เทอม 1",
+		SectionNumber:    1,
+		DayOfWeek:        day,
+		StartTime:        time.Date(2006, 1, 2, from, 0, 0, 0, loc),
+		EndTime:          time.Date(2006, 1, 2, to, 0, 0, 0, loc),
+		Kind:             kind,
+		OfferedCoursesID: 1,
+	}
+	s.ID = id
+	return s
+}
+
+func TestInferSessionKinds(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	labID := uint(1)
+	labCourse := entity.OfferedCourses{LaboratoryID: &labID}
+	threeHourLab := func(entity.OfferedCourses) float64 { return 3 }
+	withCourse := func(rows []entity.Schedule, oc entity.OfferedCourses) []entity.Schedule {
+		for i := range rows {
+			rows[i].OfferedCourses = oc
+		}
+		return rows
+	}
+
+	t.Run("Lab-length block is lab and the adjacent hour stays lecture", func(t *testing.T) {
+		rows := withCourse([]entity.Schedule{
+			sessionRow(1, "พุธ", 8, 9, ""),
+			sessionRow(2, "พุธ", 9, 12, ""),
+		}, labCourse)
+		g.Expect(config.InferSessionKinds(rows, threeHourLab)).To(Equal([]string{entity.SessionKindLecture, entity.SessionKindLab}))
+	})
+
+	t.Run("Hourly run of lab length on the last day is lab", func(t *testing.T) {
+		rows := withCourse([]entity.Schedule{
+			sessionRow(1, "พุธ", 13, 14, ""),
+			sessionRow(2, "พุธ", 14, 15, ""),
+			sessionRow(3, "พุธ", 15, 16, ""),
+			sessionRow(4, "อังคาร", 9, 10, ""),
+			sessionRow(5, "อังคาร", 10, 11, ""),
+			sessionRow(6, "อังคาร", 11, 12, ""),
+		}, labCourse)
+		g.Expect(config.InferSessionKinds(rows, threeHourLab)).To(Equal([]string{
+			entity.SessionKindLab, entity.SessionKindLab, entity.SessionKindLab,
+			entity.SessionKindLecture, entity.SessionKindLecture, entity.SessionKindLecture,
+		}))
+	})
+
+	t.Run("Course without laboratory is all lecture", func(t *testing.T) {
+		rows := withCourse([]entity.Schedule{sessionRow(1, "พุธ", 9, 12, "")}, entity.OfferedCourses{})
+		g.Expect(config.InferSessionKinds(rows, threeHourLab)).To(Equal([]string{entity.SessionKindLecture}))
+	})
+
+	t.Run("Existing kind is kept", func(t *testing.T) {
+		rows := withCourse([]entity.Schedule{sessionRow(1, "พุธ", 9, 12, entity.SessionKindLecture)}, labCourse)
+		g.Expect(config.InferSessionKinds(rows, threeHourLab)).To(Equal([]string{entity.SessionKindLecture}))
+	})
+}
+
+func TestPlanSessionMerges(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	t.Run("Adjacent rows of the same kind merge into one block", func(t *testing.T) {
+		rows := []entity.Schedule{
+			sessionRow(1, "พุธ", 13, 14, entity.SessionKindLab),
+			sessionRow(2, "พุธ", 14, 15, entity.SessionKindLab),
+			sessionRow(3, "พุธ", 15, 16, entity.SessionKindLab),
+		}
+		merges := config.PlanSessionMerges(rows)
+		g.Expect(merges).To(HaveLen(2))
+		g.Expect(merges[1].Into).To(Equal(uint(1)))
+		g.Expect(merges[1].From).To(Equal(uint(3)))
+		g.Expect(merges[1].EndTime).To(Equal(rows[2].EndTime))
+	})
+
+	t.Run("Lecture and lab are never merged", func(t *testing.T) {
+		rows := []entity.Schedule{
+			sessionRow(1, "พุธ", 8, 9, entity.SessionKindLecture),
+			sessionRow(2, "พุธ", 9, 12, entity.SessionKindLab),
+		}
+		g.Expect(config.PlanSessionMerges(rows)).To(BeEmpty())
+	})
+
+	t.Run("Rows without kind are never merged", func(t *testing.T) {
+		rows := []entity.Schedule{
+			sessionRow(1, "พุธ", 8, 9, ""),
+			sessionRow(2, "พุธ", 9, 10, ""),
+		}
+		g.Expect(config.PlanSessionMerges(rows)).To(BeEmpty())
+	})
+
+	t.Run("Fixed course rows are never merged", func(t *testing.T) {
+		rows := []entity.Schedule{
+			sessionRow(1, "พุธ", 8, 9, entity.SessionKindLecture),
+			sessionRow(2, "พุธ", 9, 10, entity.SessionKindLecture),
+		}
+		rows[1].TimeFixedCourses = []entity.TimeFixedCourses{{}}
+		g.Expect(config.PlanSessionMerges(rows)).To(BeEmpty())
+	})
+}
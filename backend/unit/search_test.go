@@ -0,0 +1,38 @@
+package unit
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Nichakorn25/CPE-Teaching-Schedule/services"
+	. "github.com/onsi/gomega"
+)
+
+func TestNormalizeSearchQuery(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	t.Run("Strips zero-width characters and collapses spaces", func(t *testing.T) {
+		g.Expect(services.NormalizeSearchQuery("  คอม\u200bพิวเตอร์ \t\n ENG23\ufeff ")).To(Equal("คอมพิวเตอร์ ENG23"))
+	})
+
+	t.Run("Blank query", func(t *testing.T) {
+		g.Expect(services.NormalizeSearchQuery(" \u200b ")).To(BeEmpty())
+	})
+
+	t.Run("Truncates by rune", func(t *testing.T) {
+		q := services.NormalizeSearchQuery(strings.Repeat("ก", services.MaxSearchLength+20))
+		g.Expect([]rune(q)).To(HaveLen(services.MaxSearchLength))
+	})
+}
+
+func TestSearchHelpers(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	t.Run("Compact course code", func(t *testing.T) {
+		g.Expect(services.CompactCode("ENG23 1001")).To(Equal("ENG231001"))
+	})
+
+	t.Run("Escape LIKE wildcards", func(t *testing.T) {
+		g.Expect(services.EscapeLike(`50%_a\b`)).To(Equal(`50\%\_a\\b`))
+	})
+}
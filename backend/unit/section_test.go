@@ -0,0 +1,41 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/Nichakorn25/CPE-Teaching-Schedule/entity"
+	"github.com/asaskevich/govalidator"
+	. "github.com/onsi/gomega"
+)
+
+func TestSectionValidation(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	t.Run("Missing SectionNumber", func(t *testing.T) {
+		s := entity.Section{SectionNumber: 0, Capacity: 40, OfferedCoursesID: 1}
+		ok, err := govalidator.ValidateStruct(s)
+		g.Expect(ok).To(BeFalse())
+		g.Expect(err.Error()).To(ContainSubstring("SectionNumber is required."))
+	})
+
+	t.Run("Missing Capacity", func(t *testing.T) {
+		s := entity.Section{SectionNumber: 1, Capacity: 0, OfferedCoursesID: 1}
+		ok, err := govalidator.ValidateStruct(s)
+		g.Expect(ok).To(BeFalse())
+		g.Expect(err.Error()).To(ContainSubstring("Capacity is required."))
+	})
+
+	t.Run("Missing OfferedCoursesID", func(t *testing.T) {
+		s := entity.Section{SectionNumber: 1, Capacity: 40, OfferedCoursesID: 0}
+		ok, err := govalidator.ValidateStruct(s)
+		g.Expect(ok).To(BeFalse())
+		g.Expect(err.Error()).To(ContainSubstring("OfferedCoursesID is required."))
+	})
+
+	t.Run("All fields valid", func(t *testing.T) {
+		s := entity.Section{SectionNumber: 1, Capacity: 40, Enrolled: 35, OfferedCoursesID: 1}
+		ok, err := govalidator.ValidateStruct(s)
+		g.Expect(ok).To(BeTrue())
+		g.Expect(err).To(BeNil())
+	})
+}
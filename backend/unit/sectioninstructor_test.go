@@ -0,0 +1,42 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/Nichakorn25/CPE-Teaching-Schedule/entity"
+	"github.com/asaskevich/govalidator"
+	. "github.com/onsi/gomega"
+)
+
+func TestSectionInstructorValidation(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	t.Run("Missing SectionNumber", func(t *testing.T) {
+		si := entity.SectionInstructor{SectionNumber: 0, OfferedCoursesID: 1, UserID: 2}
+		ok, err := govalidator.ValidateStruct(si)
+		g.Expect(ok).To(BeFalse())
+		g.Expect(err.Error()).To(ContainSubstring("SectionNumber is required."))
+	})
+
+	t.Run("Missing OfferedCoursesID", func(t *testing.T) {
+		si := entity.SectionInstructor{SectionNumber: 1, OfferedCoursesID: 0, UserID: 2}
+		ok, err := govalidator.ValidateStruct(si)
+		g.Expect(ok).To(BeFalse())
+		g.Expect(err.Error()).To(ContainSubstring("OfferedCoursesID is required."))
+	})
+
+	t.Run("Missing UserID", func(t *testing.T) {
+		si := entity.SectionInstructor{SectionNumber: 1, OfferedCoursesID: 1, UserID: 0}
+		ok, err := govalidator.ValidateStruct(si)
+		g.Expect(ok).To(BeFalse())
+		g.Expect(err.Error()).To(ContainSubstring("UserID is required."))
+	})
+
+	t.Run("All fields valid", func(t *testing.T) {
+		hours := uint(2)
+		si := entity.SectionInstructor{SectionNumber: 1, OfferedCoursesID: 1, UserID: 2, LectureHours: &hours}
+		ok, err := govalidator.ValidateStruct(si)
+		g.Expect(ok).To(BeTrue())
+		g.Expect(err).To(BeNil())
+	})
+}
@@ -0,0 +1,59 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/Nichakorn25/CPE-Teaching-Schedule/entity"
+	"github.com/asaskevich/govalidator"
+	. "github.com/onsi/gomega"
+)
+
+func TestStudentGroupValidation(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	t.Run("Valid student group", func(t *testing.T) {
+		sg := entity.StudentGroup{Name: "CPE67-1", EntryYear: 2567, Size: 45, MajorID: 1, CurriculumID: uintPtr(2)}
+		ok, err := govalidator.ValidateStruct(sg)
+		g.Expect(ok).To(BeTrue())
+		g.Expect(err).To(BeNil())
+	})
+
+	t.Run("Missing Name", func(t *testing.T) {
+		sg := entity.StudentGroup{EntryYear: 2567, Size: 45, MajorID: 1}
+		ok, err := govalidator.ValidateStruct(sg)
+		g.Expect(ok).To(BeFalse())
+		g.Expect(err.Error()).To(ContainSubstring("Name is required."))
+	})
+
+	t.Run("Missing Size", func(t *testing.T) {
+		sg := entity.StudentGroup{Name: "CPE67-1", EntryYear: 2567, MajorID: 1}
+		ok, err := govalidator.ValidateStruct(sg)
+		g.Expect(ok).To(BeFalse())
+		g.Expect(err.Error()).To(ContainSubstring("Size is required."))
+	})
+
+	t.Run("Missing MajorID", func(t *testing.T) {
+		sg := entity.StudentGroup{Name: "CPE67-1", EntryYear: 2567, Size: 45}
+		ok, err := govalidator.ValidateStruct(sg)
+		g.Expect(ok).To(BeFalse())
+		g.Expect(err.Error()).To(ContainSubstring("MajorID is required."))
+	})
+}
+
+func TestSectionStudentGroupValidation(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	t.Run("Valid link", func(t *testing.T) {
+		link := entity.SectionStudentGroup{SectionID: 3, StudentGroupID: 1}
+		ok, err := govalidator.ValidateStruct(link)
+		g.Expect(ok).To(BeTrue())
+		g.Expect(err).To(BeNil())
+	})
+
+	t.Run("Missing StudentGroupID", func(t *testing.T) {
+		link := entity.SectionStudentGroup{SectionID: 3}
+		ok, err := govalidator.ValidateStruct(link)
+		g.Expect(ok).To(BeFalse())
+		g.Expect(err.Error()).To(ContainSubstring("StudentGroupID is required."))
+	})
+}
@@ -0,0 +1,41 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/Nichakorn25/CPE-Teaching-Schedule/entity"
+	"github.com/asaskevich/govalidator"
+	. "github.com/onsi/gomega"
+)
+
+func TestStudyPlanEntryValidation(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	t.Run("Valid required course", func(t *testing.T) {
+		e := entity.StudyPlanEntry{Term: 1, CurriculumID: 1, AcademicYearID: 2, AllCoursesID: uintPtr(5)}
+		ok, err := govalidator.ValidateStruct(e)
+		g.Expect(ok).To(BeTrue())
+		g.Expect(err).To(BeNil())
+	})
+
+	t.Run("Valid elective slot", func(t *testing.T) {
+		e := entity.StudyPlanEntry{Term: 2, CurriculumID: 1, AcademicYearID: 3, ElectiveSlot: "วิชาเลือกเฉพาะสาขา 1", TypeOfCoursesID: uintPtr(4), Credits: 4}
+		ok, err := govalidator.ValidateStruct(e)
+		g.Expect(ok).To(BeTrue())
+		g.Expect(err).To(BeNil())
+	})
+
+	t.Run("Term out of range", func(t *testing.T) {
+		e := entity.StudyPlanEntry{Term: 4, CurriculumID: 1, AcademicYearID: 2, AllCoursesID: uintPtr(5)}
+		ok, err := govalidator.ValidateStruct(e)
+		g.Expect(ok).To(BeFalse())
+		g.Expect(err.Error()).To(ContainSubstring("Term must be between 1 and 3."))
+	})
+
+	t.Run("Missing AcademicYearID", func(t *testing.T) {
+		e := entity.StudyPlanEntry{Term: 1, CurriculumID: 1, AllCoursesID: uintPtr(5)}
+		ok, err := govalidator.ValidateStruct(e)
+		g.Expect(ok).To(BeFalse())
+		g.Expect(err.Error()).To(ContainSubstring("AcademicYearID is required."))
+	})
+}
@@ -89,3 +89,33 @@ func TestTeachingAssistantValidation(t *testing.T) {
 		g.Expect(err).To(BeNil())
 	})
 }
+
+func TestScheduleTeachingAssistantValidation(t *testing.T) {
+	g := NewGomegaWithT(t)
+	sectionID := uint(1)
+
+	t.Run("TeachingAssistantID is required", func(t *testing.T) {
+		sta := entity.ScheduleTeachingAssistant{SectionID: &sectionID}
+		ok, err := govalidator.ValidateStruct(sta)
+		g.Expect(ok).To(BeFalse())
+		g.Expect(err.Error()).To(ContainSubstring("TeachingAssistantID is required."))
+	})
+
+	t.Run("SectionID is required", func(t *testing.T) {
+		sta := entity.ScheduleTeachingAssistant{TeachingAssistantID: 1}
+		ok, err := govalidator.ValidateStruct(sta)
+		g.Expect(ok).To(BeFalse())
+		g.Expect(err.Error()).To(ContainSubstring("SectionID is required."))
+	})
+
+	t.Run("All fields are valid", func(t *testing.T) {
+		sta := entity.ScheduleTeachingAssistant{TeachingAssistantID: 1, SectionID: &sectionID}
+		ok, err := govalidator.ValidateStruct(sta)
+		g.Expect(ok).To(BeTrue())
+		g.Expect(err).To(BeNil())
+	})
+}
+
+func TestSectionTAIndexSkipsDeletedRows(t *testing.T) {
+	expectLiveUniqueIndex(NewWithT(t), &entity.ScheduleTeachingAssistant{}, "idx_section_ta", 2)
+}
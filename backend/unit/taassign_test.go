@@ -0,0 +1,85 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/Nichakorn25/CPE-Teaching-Schedule/services"
+	. "github.com/onsi/gomega"
+)
+
+// taFixture ภาระและสิทธิ์ของผู้ช่วยสอนแบบจำลอง: can[ta][section] = เหมาะสม, ผู้ช่วยสอนรับได้คนละไม่เกิน max กลุ่ม
+type taFixture struct {
+	can  map[uint]map[uint]bool
+	load map[uint]int
+	max  int
+	got  map[uint]map[uint]bool
+}
+
+func newTAFixture(max int, can map[uint][]uint) *taFixture {
+	f := &taFixture{can: map[uint]map[uint]bool{}, load: map[uint]int{}, max: max, got: map[uint]map[uint]bool{}}
+	for ta, sections := range can {
+		f.can[ta] = map[uint]bool{}
+		f.got[ta] = map[uint]bool{}
+		for _, s := range sections {
+			f.can[ta][s] = true
+		}
+	}
+	return f
+}
+
+func (f *taFixture) run(need map[uint]int, pool []uint) ([]services.TAPick, []uint) {
+	eligible := func(ta, sec uint) bool { return f.can[ta][sec] && !f.got[ta][sec] && f.load[ta] < f.max }
+	load := func(ta uint) int { return f.load[ta] }
+	assign := func(ta, sec uint) { f.got[ta][sec] = true; f.load[ta]++ }
+	return services.AssignTAs(need, pool, eligible, load, assign)
+}
+
+func TestAssignTAs(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	t.Run("Impossible section does not block the others", func(t *testing.T) {
+		// กลุ่ม 1 ไม่มีผู้ช่วยสอนที่เหมาะสม (ตัวเลือกน้อยที่สุดจึงถูกเลือกก่อน)
+		f := newTAFixture(5, map[uint][]uint{10: {2, 3}, 11: {3}})
+		picks, unassigned := f.run(map[uint]int{1: 1, 2: 1, 3: 1}, []uint{10, 11})
+		g.Expect(unassigned).To(Equal([]uint{1}))
+		g.Expect(picks).To(HaveLen(2))
+		sections := map[uint]bool{}
+		for _, p := range picks {
+			sections[p.SectionID] = true
+		}
+		g.Expect(sections).To(Equal(map[uint]bool{2: true, 3: true}))
+	})
+
+	t.Run("Most constrained section picks first", func(t *testing.T) {
+		// ถ้ากลุ่ม 2 เลือกก่อนอาจได้ TA 11 ซึ่งเป็นคนเดียวที่กลุ่ม 1 ใช้ได้
+		f := newTAFixture(1, map[uint][]uint{10: {2}, 11: {1, 2}})
+		picks, unassigned := f.run(map[uint]int{1: 1, 2: 1}, []uint{10, 11})
+		g.Expect(unassigned).To(BeEmpty())
+		g.Expect(picks).To(ConsistOf(
+			services.TAPick{TeachingAssistantID: 11, SectionID: 1},
+			services.TAPick{TeachingAssistantID: 10, SectionID: 2},
+		))
+	})
+
+	t.Run("Least loaded TA is chosen", func(t *testing.T) {
+		f := newTAFixture(5, map[uint][]uint{10: {1, 2}, 11: {1, 2}})
+		f.load[10] = 3
+		picks, _ := f.run(map[uint]int{1: 1}, []uint{10, 11})
+		g.Expect(picks).To(Equal([]services.TAPick{{TeachingAssistantID: 11, SectionID: 1}}))
+	})
+
+	t.Run("Section that runs out of TAs midway is reported", func(t *testing.T) {
+		// กลุ่ม 1 ต้องการ 2 คนแต่มีผู้ช่วยสอนที่เหมาะสมคนเดียว
+		f := newTAFixture(5, map[uint][]uint{10: {1, 2}, 11: {2}})
+		picks, unassigned := f.run(map[uint]int{1: 2, 2: 1}, []uint{10, 11})
+		g.Expect(unassigned).To(Equal([]uint{1}))
+		g.Expect(picks).To(HaveLen(2))
+	})
+
+	t.Run("Zero need is ignored", func(t *testing.T) {
+		f := newTAFixture(5, map[uint][]uint{10: {1}})
+		picks, unassigned := f.run(map[uint]int{1: 0}, []uint{10})
+		g.Expect(picks).To(BeEmpty())
+		g.Expect(unassigned).To(BeEmpty())
+	})
+}
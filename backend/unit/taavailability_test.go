@@ -0,0 +1,38 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Nichakorn25/CPE-Teaching-Schedule/entity"
+	"github.com/asaskevich/govalidator"
+	. "github.com/onsi/gomega"
+)
+
+func TestTAAvailabilityValidation(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	start := time.Date(2000, 1, 1, 13, 0, 0, 0, time.UTC)
+	end := time.Date(2000, 1, 1, 17, 0, 0, 0, time.UTC)
+
+	t.Run("Valid availability", func(t *testing.T) {
+		a := entity.TAAvailability{DayOfWeek: "พุธ", StartTime: start, EndTime: end, TeachingAssistantID: 1}
+		ok, err := govalidator.ValidateStruct(a)
+		g.Expect(ok).To(BeTrue())
+		g.Expect(err).To(BeNil())
+	})
+
+	t.Run("Missing DayOfWeek", func(t *testing.T) {
+		a := entity.TAAvailability{StartTime: start, EndTime: end, TeachingAssistantID: 1}
+		ok, err := govalidator.ValidateStruct(a)
+		g.Expect(ok).To(BeFalse())
+		g.Expect(err.Error()).To(ContainSubstring("DayOfWeek is required."))
+	})
+
+	t.Run("Missing TeachingAssistantID", func(t *testing.T) {
+		a := entity.TAAvailability{DayOfWeek: "พุธ", StartTime: start, EndTime: end}
+		ok, err := govalidator.ValidateStruct(a)
+		g.Expect(ok).To(BeFalse())
+		g.Expect(err.Error()).To(ContainSubstring("TeachingAssistantID is required."))
+	})
+}
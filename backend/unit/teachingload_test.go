@@ -0,0 +1,67 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/Nichakorn25/CPE-Teaching-Schedule/entity"
+	"github.com/Nichakorn25/CPE-Teaching-Schedule/services"
+	. "github.com/onsi/gomega"
+)
+
+func TestSplitSectionHours(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	t.Run("Hours are split equally when none are set", func(t *testing.T) {
+		shares := services.SplitSectionHours([]entity.SectionInstructor{{UserID: 1}, {UserID: 2}}, 3, 3)
+		g.Expect(shares).To(Equal([]services.InstructorShare{
+			{UserID: 1, Lecture: 1.5, Lab: 1.5},
+			{UserID: 2, Lecture: 1.5, Lab: 1.5},
+		}))
+	})
+
+	t.Run("Set hours are used and the rest goes to the others", func(t *testing.T) {
+		shares := services.SplitSectionHours([]entity.SectionInstructor{
+			{UserID: 1, LectureHours: uintPtr(2), LabHours: uintPtr(0)},
+			{UserID: 2},
+			{UserID: 3, LabHours: uintPtr(1)},
+		}, 3, 3)
+		g.Expect(shares).To(Equal([]services.InstructorShare{
+			{UserID: 1, Lecture: 2, Lab: 0},
+			{UserID: 2, Lecture: 0.5, Lab: 2},
+			{UserID: 3, Lecture: 0.5, Lab: 1},
+		}))
+	})
+
+	t.Run("Set hours are capped at the hours scheduled", func(t *testing.T) {
+		shares := services.SplitSectionHours([]entity.SectionInstructor{
+			{UserID: 1, LectureHours: uintPtr(4)},
+			{UserID: 2, LectureHours: uintPtr(2)},
+			{UserID: 3},
+		}, 3, 0)
+		g.Expect(shares[0].Lecture).To(Equal(3.0))
+		g.Expect(shares[1].Lecture).To(Equal(0.0))
+		g.Expect(shares[2].Lecture).To(Equal(0.0))
+	})
+
+	t.Run("Nothing is credited for a section without schedules", func(t *testing.T) {
+		shares := services.SplitSectionHours([]entity.SectionInstructor{
+			{UserID: 1, LectureHours: uintPtr(2), LabHours: uintPtr(3)},
+			{UserID: 2},
+		}, 0, 0)
+		g.Expect(shares).To(Equal([]services.InstructorShare{{UserID: 1}, {UserID: 2}}))
+	})
+
+	t.Run("Partly scheduled section fills set hours first", func(t *testing.T) {
+		shares := services.SplitSectionHours([]entity.SectionInstructor{
+			{UserID: 1, LectureHours: uintPtr(2)},
+			{UserID: 2},
+		}, 1, 0)
+		g.Expect(shares[0].Lecture).To(Equal(1.0))
+		g.Expect(shares[1].Lecture).To(Equal(0.0))
+	})
+
+	t.Run("Sole instructor takes the whole section", func(t *testing.T) {
+		shares := services.SplitSectionHours([]entity.SectionInstructor{{UserID: 7}}, 2, 3)
+		g.Expect(shares).To(Equal([]services.InstructorShare{{UserID: 7, Lecture: 2, Lab: 3}}))
+	})
+}
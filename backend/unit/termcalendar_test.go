@@ -0,0 +1,73 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Nichakorn25/CPE-Teaching-Schedule/entity"
+	"github.com/asaskevich/govalidator"
+	. "github.com/onsi/gomega"
+)
+
+func TestTermCalendarValidation(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	start := time.Date(2025, 6, 9, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2025, 10, 10, 0, 0, 0, 0, time.UTC)
+
+	t.Run("Valid term calendar", func(t *testing.T) {
+		cal := entity.TermCalendar{Year: 2568, Term: 1, StartDate: start, EndDate: end}
+		ok, err := govalidator.ValidateStruct(cal)
+		g.Expect(ok).To(BeTrue())
+		g.Expect(err).To(BeNil())
+	})
+
+	t.Run("Missing StartDate", func(t *testing.T) {
+		cal := entity.TermCalendar{Year: 2568, Term: 1, EndDate: end}
+		ok, err := govalidator.ValidateStruct(cal)
+		g.Expect(ok).To(BeFalse())
+		g.Expect(err.Error()).To(ContainSubstring("StartDate is required."))
+	})
+
+	t.Run("Missing Term", func(t *testing.T) {
+		cal := entity.TermCalendar{Year: 2568, StartDate: start, EndDate: end}
+		ok, err := govalidator.ValidateStruct(cal)
+		g.Expect(ok).To(BeFalse())
+		g.Expect(err.Error()).To(ContainSubstring("Term is required."))
+	})
+}
+
+func TestConditionDateRange(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	startTime := time.Date(2000, 1, 1, 9, 0, 0, 0, time.UTC)
+	endTime := time.Date(2000, 1, 1, 12, 0, 0, 0, time.UTC)
+	from := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 7, 14, 0, 0, 0, 0, time.UTC)
+
+	t.Run("Weekly condition", func(t *testing.T) {
+		c := entity.Condition{DayOfWeek: "จันทร์", StartTime: startTime, EndTime: endTime, UserID: 1}
+		g.Expect(c.IsWeekly()).To(BeTrue())
+	})
+
+	t.Run("Date-ranged condition is valid and not weekly", func(t *testing.T) {
+		c := entity.Condition{
+			DayOfWeek:     "อังคาร",
+			StartTime:     startTime,
+			EndTime:       endTime,
+			EffectiveFrom: &from,
+			EffectiveTo:   &to,
+			Note:          "ลาไปประชุมวิชาการ",
+			UserID:        1,
+		}
+		ok, err := govalidator.ValidateStruct(c)
+		g.Expect(ok).To(BeTrue())
+		g.Expect(err).To(BeNil())
+		g.Expect(c.IsWeekly()).To(BeFalse())
+	})
+
+	t.Run("One-off condition is not weekly", func(t *testing.T) {
+		c := entity.Condition{DayOfWeek: "อังคาร", StartTime: startTime, EndTime: endTime, Date: &from, UserID: 1}
+		g.Expect(c.IsWeekly()).To(BeFalse())
+	})
+}
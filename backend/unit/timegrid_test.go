@@ -0,0 +1,74 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/Nichakorn25/CPE-Teaching-Schedule/entity"
+	"github.com/asaskevich/govalidator"
+	. "github.com/onsi/gomega"
+)
+
+func validTimeGrid() entity.TimeGrid {
+	return entity.TimeGrid{
+		WorkingDays:    "จันทร์,อังคาร,พุธ,พฤหัสบดี,ศุกร์,เสาร์",
+		DayStart:       "08:00",
+		DayEnd:         "21:00",
+		SlotMinutes:    30,
+		PreferredStart: "08:00",
+		PreferredEnd:   "16:00",
+		FallbackStart:  "16:00",
+		FallbackEnd:    "21:00",
+		DepartmentID:   1,
+	}
+}
+
+func TestTimeGridValidation(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	t.Run("Missing WorkingDays", func(t *testing.T) {
+		tg := validTimeGrid()
+		tg.WorkingDays = ""
+		ok, err := govalidator.ValidateStruct(tg)
+		g.Expect(ok).To(BeFalse())
+		g.Expect(err.Error()).To(ContainSubstring("WorkingDays is required."))
+	})
+
+	t.Run("Invalid SlotMinutes", func(t *testing.T) {
+		tg := validTimeGrid()
+		tg.SlotMinutes = 45
+		ok, err := govalidator.ValidateStruct(tg)
+		g.Expect(ok).To(BeFalse())
+		g.Expect(err.Error()).To(ContainSubstring("SlotMinutes is invalid."))
+	})
+
+	t.Run("Invalid DayStart", func(t *testing.T) {
+		tg := validTimeGrid()
+		tg.DayStart = "8 โมง"
+		ok, err := govalidator.ValidateStruct(tg)
+		g.Expect(ok).To(BeFalse())
+		g.Expect(err.Error()).To(ContainSubstring("DayStart is invalid."))
+	})
+
+	t.Run("Missing DepartmentID", func(t *testing.T) {
+		tg := validTimeGrid()
+		tg.DepartmentID = 0
+		ok, err := govalidator.ValidateStruct(tg)
+		g.Expect(ok).To(BeFalse())
+		g.Expect(err.Error()).To(ContainSubstring("DepartmentID is required."))
+	})
+
+	t.Run("All fields valid", func(t *testing.T) {
+		tg := validTimeGrid()
+		tg.BlockedPeriods = []entity.TimeGridBlock{{StartTime: "12:00", EndTime: "13:00", Label: "พักกลางวัน"}}
+		ok, err := govalidator.ValidateStruct(tg)
+		g.Expect(ok).To(BeTrue())
+		g.Expect(err).To(BeNil())
+	})
+
+	t.Run("Invalid blocked period", func(t *testing.T) {
+		b := entity.TimeGridBlock{StartTime: "12:00", EndTime: "25:00"}
+		ok, err := govalidator.ValidateStruct(b)
+		g.Expect(ok).To(BeFalse())
+		g.Expect(err.Error()).To(ContainSubstring("EndTime is invalid."))
+	})
+}
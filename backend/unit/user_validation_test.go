@@ -112,6 +112,17 @@ func TestUserNameValidation(t *testing.T) {
 		g.Expect(err.Error()).To(ContainSubstring("Firstname must contain only letters."))
 	})
 
+	t.Run("Thai names are valid", func(t *testing.T) {
+		g := NewWithT(t)
+		user := validUser()
+		user.Firstname = "ศรัญญา"
+		user.Lastname = "กาญจนโชติ"
+
+		ok, err := govalidator.ValidateStruct(user)
+		g.Expect(ok).To(BeTrue())
+		g.Expect(err).To(BeNil())
+	})
+
 	t.Run("Lastname is required", func(t *testing.T) {
 		g := NewWithT(t)
 		user := validUser()
@@ -170,7 +181,7 @@ func TestUserContactValidation(t *testing.T) {
 		g.Expect(err.Error()).To(ContainSubstring("Phone number is required."))
 	})
 
-	t.Run("PhoneNumber must be 10 digits", func(t *testing.T) {
+	t.Run("PhoneNumber must be 9-10 digits", func(t *testing.T) {
 		g := NewWithT(t)
 		user := validUser()
 		user.PhoneNumber = "12345"
@@ -178,7 +189,17 @@ func TestUserContactValidation(t *testing.T) {
 		ok, err := govalidator.ValidateStruct(user)
 		g.Expect(ok).NotTo(BeTrue())
 		g.Expect(err).NotTo(BeNil())
-		g.Expect(err.Error()).To(ContainSubstring("Phone number must be 10 digits."))
+		g.Expect(err.Error()).To(ContainSubstring("Phone number must be 9-10 digits starting with 0."))
+	})
+
+	t.Run("Landline PhoneNumber is valid", func(t *testing.T) {
+		g := NewWithT(t)
+		user := validUser()
+		user.PhoneNumber = "044224422"
+
+		ok, err := govalidator.ValidateStruct(user)
+		g.Expect(ok).To(BeTrue())
+		g.Expect(err).To(BeNil())
 	})
 
 	t.Run("Address is required", func(t *testing.T) {
@@ -0,0 +1,45 @@
+package unit
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/Nichakorn25/CPE-Teaching-Schedule/services"
+	. "github.com/onsi/gomega"
+)
+
+func TestWriteXLSX(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	var buf bytes.Buffer
+	err := services.WriteXLSX(&buf, "TA Timesheet", [][]interface{}{
+		{"ผู้ช่วยสอน", "ชั่วโมง/สัปดาห์"},
+		{"นาย A & B", 4.5},
+		{"รวม", 30},
+	})
+	g.Expect(err).To(BeNil())
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	g.Expect(err).To(BeNil())
+
+	files := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		g.Expect(err).To(BeNil())
+		body, _ := io.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = string(body)
+	}
+
+	g.Expect(files).To(HaveKey("[Content_Types].xml"))
+	g.Expect(files).To(HaveKey("xl/workbook.xml"))
+	g.Expect(files["xl/workbook.xml"]).To(ContainSubstring(`name="TA Timesheet"`))
+
+	sheet := files["xl/worksheets/sheet1.xml"]
+	g.Expect(sheet).To(ContainSubstring(`<c r="A1" t="inlineStr"><is><t xml:space="preserve">ผู้ช่วยสอน</t></is></c>`))
+	g.Expect(sheet).To(ContainSubstring("นาย A &amp; B"))
+	g.Expect(sheet).To(ContainSubstring(`<c r="B2"><v>4.5</v></c>`))
+	g.Expect(sheet).To(ContainSubstring(`<c r="B3"><v>30</v></c>`))
+}
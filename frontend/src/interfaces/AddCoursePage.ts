@@ -20,6 +20,7 @@ export interface CourseInterface {
   Credit: string;
   TypeOfCourse: string;
   TotalSections: number;
+  Codes?: string[]; // รหัสวิชาหลักและวิชาเทียบเท่าที่เรียนรวม
   Sections: SectionInterface[];
   IsFixCourses?: boolean;
 }
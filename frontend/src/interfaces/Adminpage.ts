@@ -46,6 +46,7 @@ export interface AllCourseinOpenCourseInterface {
 
 export interface SectionDetailInterface {
   ID: number;
+  SectionID: number;
   SectionNumber: number;
   Room: string;
   DayOfWeek: string;
@@ -65,6 +66,7 @@ export interface OfferedCourseDetailInterface {
   Credit: string;   // API ส่งเป็น string
   TypeOfCourse: string;
   TotalSections: number;
+  Codes?: string[]; // รหัสวิชาหลักและวิชาเทียบเท่าที่เรียนรวม
   Laboratory: string;   // เช่น "ไม่มีการสอนแลป"
   Sections: SectionDetailInterface[];
 }
@@ -153,6 +155,7 @@ export interface OpenCourseInterface {
     Time: string;
   }[];
   GroupTotal: number;
+  Codes?: string[];
   CapacityPer: number;
   Remark: string;
   IsFixCourses: boolean;
@@ -187,7 +190,7 @@ export interface CurriculumInterface {
 export interface CreateLaboratoryInterface {
   room: string;
   building: string;
-  capacity: string;
+  capacity: number;
 }
 
 export interface CreateCurriculumInterface {
@@ -228,7 +231,7 @@ export interface LaboratoryInterface {
   ID:number;
   Room: string;
   Building: string;
-  Capacity: string;
+  Capacity: number;
 }
 
 export interface CreateScheduleInterface {
@@ -306,6 +309,7 @@ export interface OpenCourseForAddTA {
   Credit: string;
   TypeOfCourse: string;
   TotalSections: number;
+  Codes?: string[]; // รหัสวิชาหลักและวิชาเทียบเท่าที่เรียนรวม
   Laboratory: string;
   IsFixCourses: boolean;
   GroupInfos?: {
@@ -65,7 +65,7 @@ export interface LaboratoryInterface {
   ID?: number;
   Room: string;
   Building: string;
-  Capacity: string;
+  Capacity: number;
 
   OfferedCourses?: OfferedCoursesInterface[];
 }
@@ -2,5 +2,5 @@ export interface LaboratoryData {
   id?: number;
   room?: string;
   building?: string;
-  capacity?: string;
+  capacity?: number | string;
 }
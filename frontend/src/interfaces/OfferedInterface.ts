@@ -49,7 +49,7 @@ export interface Schedule {
       ID: number;
       Room?: string;
       Building?: string;
-      Capacity?: string;
+      Capacity?: number;
     } | null;
     AllCoursesID: number;
     AllCourses: {
@@ -79,6 +79,7 @@ export interface CourseTableData {
   TypeOfCourse: string;
   Sections: any[];
   TotalSections: number;
+  Codes?: string[]; // รหัสวิชาหลักและวิชาเทียบเท่าที่เรียนรวม
   IsFixCourses: boolean;
   isChild?: boolean;
   isLastChild?: boolean;
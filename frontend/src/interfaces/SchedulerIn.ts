@@ -4,6 +4,10 @@ export interface ConditionInterface {
   DayOfWeek: string;
   Start: string;
   End: string;
+  EffectiveFrom?: string;
+  EffectiveTo?: string;
+  Date?: string;
+  Note?: string;
 }
 
 export interface UserConInterface {
@@ -23,6 +27,10 @@ export interface ConditionInputInterface {
   DayOfWeek: string;   
   StartTime: string;   
   EndTime: string;     
+  EffectiveFrom?: string; // YYYY-MM-DD
+  EffectiveTo?: string;
+  Date?: string;          // วันที่ไม่ว่างครั้งเดียว
+  Note?: string;
 }
 
 export interface ConditionsRequestInterface {
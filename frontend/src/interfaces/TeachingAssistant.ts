@@ -4,6 +4,7 @@ export interface TeachingAssistantInterface {
   Lastname: string;
   Email: string;
   PhoneNumber: string;
+  MaxHoursPerWeek?: number;
 
   TitleID: number;
   Title: TitleInterface;
@@ -20,8 +21,10 @@ export interface ScheduleTeachingAssistantIn {
   TeachingAssistantID: number;
   TeachingAssistant: TeachingAssistantInterface;
 
-  ScheduleID: number;
-  Schedule: ScheduleInterfae;
+  ScheduleID?: number | null;
+  Schedule?: ScheduleInterfae;
+
+  SectionID?: number | null;
 }
 
 export interface TitleInterface {
@@ -19,7 +19,7 @@ interface Laboratory {
   ID: number;
   Room: string;
   Building: string;
-  Capacity: string;
+  Capacity: number;
   Code?: string;
   Name?: string;
   Category?: string;
@@ -143,10 +143,10 @@ const LaboratoryList: React.FC = () => {
             sortedData.sort((a, b) => b.Room.localeCompare(a.Room));
             break;
           case "capacity-asc":
-            sortedData.sort((a, b) => parseInt(a.Capacity) - parseInt(b.Capacity));
+            sortedData.sort((a, b) => a.Capacity - b.Capacity);
             break;
           case "capacity-desc":
-            sortedData.sort((a, b) => parseInt(b.Capacity) - parseInt(a.Capacity));
+            sortedData.sort((a, b) => b.Capacity - a.Capacity);
             break;
           default:
             break;
@@ -86,7 +86,7 @@ const ManageLab: React.FC = () => {
       const payload = {
         room: formData.room || "",
         building: formData.building || "",
-        capacity: String(formData.capacity || ""),
+        capacity: Number(formData.capacity || 0),
       };
 
       let res;
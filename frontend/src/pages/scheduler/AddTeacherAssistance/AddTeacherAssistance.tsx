@@ -330,7 +330,7 @@ const AddTeachingAssistant: React.FC = () => {
       return;
     }
 
-    const sectionID = section.ID;
+    const sectionID = section.SectionID;
 
     const confirm = await Swal.fire({
       title: "ยืนยันการลบผู้ช่วยสอน?",
@@ -425,7 +425,7 @@ const AddTeachingAssistant: React.FC = () => {
       const promises = sections.map((sec: any) => {
         const existingTA = sec.TeachingAssistants || [];
         if (existingTA.length > 0) {
-          return upUpdateTeachingAssistants(sec.ID, teachingAssistantIDs);
+          return upUpdateTeachingAssistants(sec.SectionID, teachingAssistantIDs);
         } else {
           const payload = {
             offered_courses_id: Number(selectedCourse.ID),
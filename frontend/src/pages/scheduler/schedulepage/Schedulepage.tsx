@@ -1460,8 +1460,8 @@ const getTeachingAssistantsForTooltip = (subCell: SubCell): string => {
       (schedule: any) => schedule.ID === subCell.scheduleId
     );
     
-    if (originalSchedule?.ScheduleTeachingAssistant && originalSchedule.ScheduleTeachingAssistant.length > 0) {
-      const assistants = originalSchedule.ScheduleTeachingAssistant
+    if (originalSchedule?.Section?.TeachingAssistants && originalSchedule.Section.TeachingAssistants.length > 0) {
+      const assistants = originalSchedule.Section.TeachingAssistants
         .map((sta: any) => {
           if (sta.TeachingAssistant) {
             const title = sta.TeachingAssistant.Title?.Title || '';
@@ -4745,8 +4745,8 @@ const exportScheduleToXLSX = async () => {
             (schedule: any) => schedule.ID === scheduleId
           );
           
-          if (originalSchedule?.ScheduleTeachingAssistant && originalSchedule.ScheduleTeachingAssistant.length > 0) {
-            originalSchedule.ScheduleTeachingAssistant.forEach((sta: any) => {
+          if (originalSchedule?.Section?.TeachingAssistants && originalSchedule.Section.TeachingAssistants.length > 0) {
+            originalSchedule.Section.TeachingAssistants.forEach((sta: any) => {
               if (sta.TeachingAssistant) {
                 const title = sta.TeachingAssistant.Title?.Title || '';
                 const firstname = sta.TeachingAssistant.Firstname || '';
@@ -23,6 +23,7 @@ const requestOptions = {
   headers: {
     "Content-Type": "application/json",
     Authorization: `${Bearer} ${Authorization}`,
+    "Accept-Language": "th",
   },
 };
 
@@ -268,9 +269,16 @@ async function updateCurriculum(id: string | number, input: UpdateCurriculumInpu
   }
 }
 
-async function duplicateAllCourses(curriculumId: string | number) {
+async function duplicateAllCourses(
+  curriculumId: string | number,
+  sourceCurriculumId: number,
+  courseIds: number[] = []
+) {
   try {
-    const res = await axios.post(`${apiUrl}/curriculum-into-allcourse/${curriculumId}`);
+    const res = await axios.post(`${apiUrl}/curriculum-into-allcourse/${curriculumId}`, {
+      sourceCurriculumId,
+      courseIds,
+    });
     return res.data.data;
   } catch (error: any) {
     console.error("duplicateAllCourses error:", error.response || error.message);
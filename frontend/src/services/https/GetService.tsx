@@ -9,6 +9,7 @@ const requestOptions = {
   headers: {
     "Content-Type": "aaplication/json",
     Authorization: `${Bearer} ${Authorization}`,
+    "Accept-Language": "th",
   },
 };
 
@@ -11,6 +11,7 @@ const requestOptions = {
   headers: {
     "Content-Type": "application/json",
     Authorization: `${Bearer} ${Authorization}`,
+    "Accept-Language": "th",
   },
 
 };
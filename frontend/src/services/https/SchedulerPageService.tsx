@@ -16,6 +16,7 @@ const requestOptions = {
   headers: {
     "Content-Type": "application/json",
     Authorization: `${Bearer} ${Authorization}`,
+    "Accept-Language": "th",
   },
 };
 